@@ -0,0 +1,213 @@
+// Package accounts keeps the user accounts of the server with their hashed passwords.
+package accounts
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPassword is the least number of characters of a password.
+const MinPassword = 8
+
+// The errors returned by the store.
+var (
+	ErrExists        = errors.New("that username is taken")
+	ErrNotFound      = errors.New("no account with that name")
+	ErrWrongPassword = errors.New("wrong username or password")
+	ErrDisabled      = errors.New("this account is disabled")
+	ErrWeakPassword  = fmt.Errorf("a password needs at least %d characters", MinPassword)
+)
+
+// User is the account of a user. Every change of an account is appended to the log as a
+// whole record, the last one of a name wins when the log is loaded.
+type User struct {
+	Name     string `json:"name"`
+	Hash     []byte `json:"hash"` // bcrypt hash of the password
+	Disabled bool   `json:"disabled"`
+	Created  int64  `json:"created"`
+}
+
+// Store holds the accounts, kept in a log file if it was opened with a path.
+type Store struct {
+	lock  sync.RWMutex
+	users map[string]*User
+	f     *os.File
+	dummy []byte // hash checked for unknown names so they take as long as known ones
+}
+
+// Open loads the accounts from the log at path, creating it if needed. An empty path keeps
+// the accounts in memory only.
+// It returns the store and an error.
+func Open(path string) (*Store, error) {
+
+	dummy, err := bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{users: make(map[string]*User), dummy: dummy}
+	if path == "" {
+		return s, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var u User
+		if err := json.Unmarshal(sc.Bytes(), &u); err != nil {
+			// a torn last line from a crash is skipped
+			continue
+		}
+		s.users[u.Name] = &u
+	}
+	if err := sc.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	s.f = f
+	return s, nil
+}
+
+// save writes the record of u to the log. The store lock must be held.
+func (s *Store) save(u *User) error {
+
+	if s.f == nil {
+		return nil
+	}
+	b, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	_, err = s.f.Write(append(b, '\n'))
+	return err
+}
+
+// SignUp creates the account name with password.
+// It returns an error.
+func (s *Store) SignUp(name string, password string) error {
+
+	if len(password) < MinPassword {
+		return ErrWeakPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.users[name]; ok {
+		return ErrExists
+	}
+	u := &User{Name: name, Hash: hash, Created: time.Now().UnixNano()}
+	if err := s.save(u); err != nil {
+		return err
+	}
+	s.users[name] = u
+	return nil
+}
+
+// Check checks the password of the account name, unknown names and wrong passwords give
+// the same error.
+// It returns an error.
+func (s *Store) Check(name string, password string) error {
+
+	s.lock.RLock()
+	u, ok := s.users[name]
+	s.lock.RUnlock()
+
+	if !ok {
+		bcrypt.CompareHashAndPassword(s.dummy, []byte(password))
+		return ErrWrongPassword
+	}
+	if bcrypt.CompareHashAndPassword(u.Hash, []byte(password)) != nil {
+		return ErrWrongPassword
+	}
+	if u.Disabled {
+		return ErrDisabled
+	}
+	return nil
+}
+
+// ChangePassword replaces the password of the account name if old is the current one.
+// It returns an error.
+func (s *Store) ChangePassword(name string, old string, password string) error {
+
+	if err := s.Check(name, old); err != nil {
+		return err
+	}
+	if len(password) < MinPassword {
+		return ErrWeakPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	u := *s.users[name]
+	u.Hash = hash
+	if err := s.save(&u); err != nil {
+		return err
+	}
+	s.users[name] = &u
+	return nil
+}
+
+// SetDisabled disables or enables the account name.
+// It returns an error.
+func (s *Store) SetDisabled(name string, disabled bool) error {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	cur, ok := s.users[name]
+	if !ok {
+		return ErrNotFound
+	}
+	u := *cur
+	u.Disabled = disabled
+	if err := s.save(&u); err != nil {
+		return err
+	}
+	s.users[name] = &u
+	return nil
+}
+
+// Disabled checks if the account name exists and is disabled.
+func (s *Store) Disabled(name string) bool {
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	u, ok := s.users[name]
+	return ok && u.Disabled
+}
+
+// Close closes the log file.
+func (s *Store) Close() error {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
@@ -0,0 +1,101 @@
+package accounts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+
+	s, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SignUp("alice", "secret-alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name string
+		do   func() error
+		want error
+	}{
+		{"short password", func() error { return s.SignUp("bob", "short") }, ErrWeakPassword},
+		{"taken name", func() error { return s.SignUp("alice", "another-password") }, ErrExists},
+		{"right password", func() error { return s.Check("alice", "secret-alice") }, nil},
+		{"wrong password", func() error { return s.Check("alice", "secret-bob") }, ErrWrongPassword},
+		{"unknown name", func() error { return s.Check("nobody", "secret-alice") }, ErrWrongPassword},
+		{"change with wrong password", func() error { return s.ChangePassword("alice", "wrong-password", "new-password") }, ErrWrongPassword},
+		{"change to short password", func() error { return s.ChangePassword("alice", "secret-alice", "short") }, ErrWeakPassword},
+		{"change password", func() error { return s.ChangePassword("alice", "secret-alice", "new-password") }, nil},
+		{"old password", func() error { return s.Check("alice", "secret-alice") }, ErrWrongPassword},
+		{"new password", func() error { return s.Check("alice", "new-password") }, nil},
+		{"disable", func() error { return s.SetDisabled("alice", true) }, nil},
+		{"disabled login", func() error { return s.Check("alice", "new-password") }, ErrDisabled},
+		{"disabled wrong password", func() error { return s.Check("alice", "secret-alice") }, ErrWrongPassword},
+		{"enable", func() error { return s.SetDisabled("alice", false) }, nil},
+		{"enabled login", func() error { return s.Check("alice", "new-password") }, nil},
+		{"disable unknown", func() error { return s.SetDisabled("nobody", true) }, ErrNotFound},
+	}
+	for _, st := range steps {
+		if err := st.do(); err != st.want {
+			t.Errorf("%s: got %v, want %v", st.name, err, st.want)
+		}
+	}
+}
+
+func TestLog(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "accounts.log")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"alice", "bob", "carol"} {
+		if err := s.SignUp(name, "secret-"+name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.ChangePassword("bob", "secret-bob", "new-password"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetDisabled("carol", true); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	// a crash in the middle of a write leaves a torn last line
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"name":"dave","ha`)
+	f.Close()
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	tests := []struct {
+		name     string
+		password string
+		want     error
+	}{
+		{"alice", "secret-alice", nil},
+		{"bob", "secret-bob", ErrWrongPassword},
+		{"bob", "new-password", nil},
+		{"carol", "secret-carol", ErrDisabled},
+		{"dave", "secret-dave", ErrWrongPassword},
+	}
+	for _, tt := range tests {
+		if err := s.Check(tt.name, tt.password); err != tt.want {
+			t.Errorf("Check(%s, %s) = %v, want %v", tt.name, tt.password, err, tt.want)
+		}
+	}
+	if !s.Disabled("carol") || s.Disabled("alice") || s.Disabled("dave") {
+		t.Error("the disabled accounts were not kept")
+	}
+}
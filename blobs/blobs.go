@@ -0,0 +1,137 @@
+// Package blobs keeps the files attached to chat messages in a directory, each one named after
+// the sha256 of its content so a file shared twice is stored once.
+package blobs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// The errors of the store.
+var (
+	ErrNotFound = errors.New("attachment not found")
+	ErrChecksum = errors.New("the checksum doesn't match the data")
+	ErrTooLarge = errors.New("attachment too large")
+)
+
+// Store is a directory of files named after their sha256.
+type Store struct {
+	dir string
+	max int64 // most bytes of a file
+}
+
+// Open opens the store in dir, creating the directory if needed. Files are at most max bytes.
+// It returns the store and an error.
+func Open(dir string, max int64) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir, max: max}, nil
+}
+
+// Max returns the most bytes of a file.
+func (s *Store) Max() int64 {
+	return s.max
+}
+
+// path returns the path of the file with id, which must be a sha256 in hex.
+// It returns the path and ErrNotFound if id can't be one.
+func (s *Store) path(id string) (string, error) {
+	if b, err := hex.DecodeString(id); err != nil || len(b) != sha256.Size {
+		return "", ErrNotFound
+	}
+	return filepath.Join(s.dir, id), nil
+}
+
+// Writer writes a new file of the store, it is only added once committed.
+type Writer struct {
+	store *Store
+	f     *os.File
+	hash  hash.Hash
+	size  int64
+}
+
+// Create starts a new file.
+// It returns the writer of the file and an error.
+func (s *Store) Create() (*Writer, error) {
+	f, err := ioutil.TempFile(s.dir, "upload-")
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{store: s, f: f, hash: sha256.New()}, nil
+}
+
+// Write adds p to the file.
+// It returns the number of bytes written and an error, ErrTooLarge past the limit of the store.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.size+int64(len(p)) > w.store.max {
+		return 0, ErrTooLarge
+	}
+	n, err := w.f.Write(p)
+	w.hash.Write(p[:n])
+	w.size += int64(n)
+	return n, err
+}
+
+// Commit adds the file to the store if its content has the sha256 sum, in hex.
+// It returns the id of the file, its size and an error.
+func (w *Writer) Commit(sum string) (string, int64, error) {
+
+	defer w.Abort()
+
+	id := hex.EncodeToString(w.hash.Sum(nil))
+	if sum != id {
+		return "", 0, ErrChecksum
+	}
+	if err := w.f.Sync(); err != nil {
+		return "", 0, err
+	}
+	if err := w.f.Close(); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(w.f.Name(), filepath.Join(w.store.dir, id)); err != nil {
+		return "", 0, err
+	}
+	return id, w.size, nil
+}
+
+// Abort drops the file if it was not committed.
+func (w *Writer) Abort() {
+	w.f.Close()
+	os.Remove(w.f.Name())
+}
+
+// Stat returns the size of the file with id.
+// It returns the size and an error, ErrNotFound if there is no such file.
+func (s *Store) Stat(id string) (int64, error) {
+	p, err := s.path(id)
+	if err != nil {
+		return 0, err
+	}
+	fi, err := os.Stat(p)
+	if os.IsNotExist(err) {
+		return 0, ErrNotFound
+	} else if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// Open opens the file with id for reading.
+// It returns the file and an error, ErrNotFound if there is no such file.
+func (s *Store) Open(id string) (*os.File, error) {
+	p, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
@@ -0,0 +1,127 @@
+package blobs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// sum returns the sha256 of data in hex.
+func sum(data string) string {
+	h := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(h[:])
+}
+
+func TestUpload(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		chunks []string
+		sum    string
+		err    error
+	}{
+		{"one chunk", []string{"hello"}, sum("hello"), nil},
+		{"chunks", []string{"hel", "lo ", "world"}, sum("hello world"), nil},
+		{"empty", nil, sum(""), nil},
+		{"wrong sum", []string{"hello"}, sum("hallo"), ErrChecksum},
+		{"no sum", []string{"hello"}, "", ErrChecksum},
+		{"at the limit", []string{strings.Repeat("x", 8), strings.Repeat("y", 8)}, sum(strings.Repeat("x", 8) + strings.Repeat("y", 8)), nil},
+		{"past the limit", []string{strings.Repeat("x", 8), strings.Repeat("y", 9)}, "", ErrTooLarge},
+	}
+	for _, tt := range tests {
+		s, err := Open(t.TempDir(), 16)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := s.Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data string
+		for _, c := range tt.chunks {
+			if _, err = w.Write([]byte(c)); err != nil {
+				break
+			}
+			data += c
+		}
+		var id string
+		var size int64
+		if err == nil {
+			id, size, err = w.Commit(tt.sum)
+		} else {
+			w.Abort()
+		}
+		if err != tt.err {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+		}
+
+		files, _ := ioutil.ReadDir(s.dir)
+		if err != nil {
+			if len(files) != 0 {
+				t.Errorf("%s: the failed upload left %d files", tt.name, len(files))
+			}
+			continue
+		}
+		if id != tt.sum || size != int64(len(data)) || len(files) != 1 {
+			t.Errorf("%s: got %s of %d bytes in %d files", tt.name, id, size, len(files))
+		}
+		f, err := s.Open(id)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		b, _ := ioutil.ReadAll(f)
+		f.Close()
+		if string(b) != data {
+			t.Errorf("%s: read back %q, want %q", tt.name, b, data)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+
+	s, err := Open(t.TempDir(), 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, _ := s.Create()
+	w.Write([]byte("hello"))
+	id, _, err := w.Commit(sum("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the same content is stored once
+	w, _ = s.Create()
+	w.Write([]byte("hello"))
+	if again, _, err := w.Commit(sum("hello")); again != id || err != nil {
+		t.Errorf("second upload: %s %v", again, err)
+	}
+
+	tests := []struct {
+		name string
+		id   string
+		size int64
+		err  error
+	}{
+		{"stored", id, 5, nil},
+		{"missing", sum("other"), 0, ErrNotFound},
+		{"not hex", "../../etc/passwd", 0, ErrNotFound},
+		{"short", id[:10], 0, ErrNotFound},
+		{"empty", "", 0, ErrNotFound},
+	}
+	for _, tt := range tests {
+		size, err := s.Stat(tt.id)
+		if size != tt.size || err != tt.err {
+			t.Errorf("Stat %s: got %d %v, want %d %v", tt.name, size, err, tt.size, tt.err)
+		}
+		f, err := s.Open(tt.id)
+		if err != tt.err {
+			t.Errorf("Open %s: got %v, want %v", tt.name, err, tt.err)
+		}
+		if f != nil {
+			f.Close()
+		}
+	}
+}
@@ -1,35 +1,16 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // source: grpchat.proto
 
-/*
-Package chat is a generated protocol buffer package.
-
-It is generated from these files:
-	grpchat.proto
-
-It has these top-level messages:
-	Message
-	ClientLoginRequest
-	ClientLoginResponse
-	ClientLogoutRequest
-	ClientLogoutResponse
-	Login
-	Logout
-	ChatClient
-	ChatGroup
-	ChatGroupList
-	ChatClientList
-	Empty
-*/
 package chat
 
-import proto "github.com/golang/protobuf/proto"
-import fmt "fmt"
-import math "math"
-
 import (
-	context "golang.org/x/net/context"
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
 	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
 )
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -41,18 +22,190 @@ var _ = math.Inf
 // is compatible with the proto package it is being compiled against.
 // A compilation error at this line likely means your copy of the
 // proto package needs to be updated.
-const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type Visibility int32
+
+const (
+	Visibility_PUBLIC      Visibility = 0
+	Visibility_UNLISTED    Visibility = 1
+	Visibility_INVITE_ONLY Visibility = 2
+	Visibility_PASSWORD    Visibility = 3
+)
+
+var Visibility_name = map[int32]string{
+	0: "PUBLIC",
+	1: "UNLISTED",
+	2: "INVITE_ONLY",
+	3: "PASSWORD",
+}
+
+var Visibility_value = map[string]int32{
+	"PUBLIC":      0,
+	"UNLISTED":    1,
+	"INVITE_ONLY": 2,
+	"PASSWORD":    3,
+}
+
+func (x Visibility) String() string {
+	return proto.EnumName(Visibility_name, int32(x))
+}
+
+func (Visibility) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{0}
+}
+
+type InvitationState int32
+
+const (
+	InvitationState_PENDING  InvitationState = 0
+	InvitationState_ACCEPTED InvitationState = 1
+	InvitationState_DECLINED InvitationState = 2
+	InvitationState_EXPIRED  InvitationState = 3
+)
+
+var InvitationState_name = map[int32]string{
+	0: "PENDING",
+	1: "ACCEPTED",
+	2: "DECLINED",
+	3: "EXPIRED",
+}
+
+var InvitationState_value = map[string]int32{
+	"PENDING":  0,
+	"ACCEPTED": 1,
+	"DECLINED": 2,
+	"EXPIRED":  3,
+}
+
+func (x InvitationState) String() string {
+	return proto.EnumName(InvitationState_name, int32(x))
+}
+
+func (InvitationState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{1}
+}
+
+type Role int32
+
+const (
+	Role_MEMBER Role = 0
+	Role_ADMIN  Role = 1
+	Role_OWNER  Role = 2
+)
+
+var Role_name = map[int32]string{
+	0: "MEMBER",
+	1: "ADMIN",
+	2: "OWNER",
+}
+
+var Role_value = map[string]int32{
+	"MEMBER": 0,
+	"ADMIN":  1,
+	"OWNER":  2,
+}
+
+func (x Role) String() string {
+	return proto.EnumName(Role_name, int32(x))
+}
+
+func (Role) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{2}
+}
+
+type PresenceState int32
+
+const (
+	PresenceState_OFFLINE PresenceState = 0
+	PresenceState_ONLINE  PresenceState = 1
+	PresenceState_AWAY    PresenceState = 2
+	PresenceState_BUSY    PresenceState = 3
+)
+
+var PresenceState_name = map[int32]string{
+	0: "OFFLINE",
+	1: "ONLINE",
+	2: "AWAY",
+	3: "BUSY",
+}
+
+var PresenceState_value = map[string]int32{
+	"OFFLINE": 0,
+	"ONLINE":  1,
+	"AWAY":    2,
+	"BUSY":    3,
+}
+
+func (x PresenceState) String() string {
+	return proto.EnumName(PresenceState_name, int32(x))
+}
+
+func (PresenceState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{3}
+}
 
 type Message struct {
-	Body     string `protobuf:"bytes,1,opt,name=body" json:"body,omitempty"`
-	Sender   string `protobuf:"bytes,2,opt,name=sender" json:"sender,omitempty"`
-	Receiver string `protobuf:"bytes,3,opt,name=receiver" json:"receiver,omitempty"`
+	Body         string      `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+	Sender       string      `protobuf:"bytes,2,opt,name=sender,proto3" json:"sender,omitempty"`
+	Receiver     string      `protobuf:"bytes,3,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	Id           string      `protobuf:"bytes,4,opt,name=id,proto3" json:"id,omitempty"`
+	Timestamp    int64       `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Seq          int64       `protobuf:"varint,6,opt,name=seq,proto3" json:"seq,omitempty"`
+	Conversation string      `protobuf:"bytes,8,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	Edited       int64       `protobuf:"varint,18,opt,name=edited,proto3" json:"edited,omitempty"`
+	Deleted      bool        `protobuf:"varint,19,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	ReplyTo      string      `protobuf:"bytes,22,opt,name=reply_to,json=replyTo,proto3" json:"reply_to,omitempty"`
+	Thread       string      `protobuf:"bytes,23,opt,name=thread,proto3" json:"thread,omitempty"`
+	Quote        *Quote      `protobuf:"bytes,24,opt,name=quote,proto3" json:"quote,omitempty"`
+	Reactions    []*Reaction `protobuf:"bytes,25,rep,name=reactions,proto3" json:"reactions,omitempty"`
+	Mentions     []string    `protobuf:"bytes,27,rep,name=mentions,proto3" json:"mentions,omitempty"`
+	Attachment   *Attachment `protobuf:"bytes,29,opt,name=attachment,proto3" json:"attachment,omitempty"`
+	Sealed       *Sealed     `protobuf:"bytes,30,opt,name=sealed,proto3" json:"sealed,omitempty"`
+	// Types that are valid to be assigned to Event:
+	//	*Message_Typing
+	//	*Message_Read
+	//	*Message_Join
+	//	*Message_Leave
+	//	*Message_Notice
+	//	*Message_Error
+	//	*Message_Ack
+	//	*Message_Subscribe
+	//	*Message_Unsubscribe
+	//	*Message_Edit
+	//	*Message_Delete
+	//	*Message_Reaction
+	//	*Message_Mention
+	Event                isMessage_Event `protobuf_oneof:"event"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *Message) Reset()         { *m = Message{} }
+func (m *Message) String() string { return proto.CompactTextString(m) }
+func (*Message) ProtoMessage()    {}
+func (*Message) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{0}
+}
+
+func (m *Message) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Message.Unmarshal(m, b)
+}
+func (m *Message) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Message.Marshal(b, m, deterministic)
+}
+func (m *Message) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Message.Merge(m, src)
+}
+func (m *Message) XXX_Size() int {
+	return xxx_messageInfo_Message.Size(m)
+}
+func (m *Message) XXX_DiscardUnknown() {
+	xxx_messageInfo_Message.DiscardUnknown(m)
 }
 
-func (m *Message) Reset()                    { *m = Message{} }
-func (m *Message) String() string            { return proto.CompactTextString(m) }
-func (*Message) ProtoMessage()               {}
-func (*Message) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+var xxx_messageInfo_Message proto.InternalMessageInfo
 
 func (m *Message) GetBody() string {
 	if m != nil {
@@ -75,572 +228,4596 @@ func (m *Message) GetReceiver() string {
 	return ""
 }
 
-type ClientLoginRequest struct {
-	Password string `protobuf:"bytes,1,opt,name=password" json:"password,omitempty"`
-	Name     string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+func (m *Message) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
 }
 
-func (m *ClientLoginRequest) Reset()                    { *m = ClientLoginRequest{} }
-func (m *ClientLoginRequest) String() string            { return proto.CompactTextString(m) }
-func (*ClientLoginRequest) ProtoMessage()               {}
-func (*ClientLoginRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (m *Message) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
 
-func (m *ClientLoginRequest) GetPassword() string {
+func (m *Message) GetSeq() int64 {
 	if m != nil {
-		return m.Password
+		return m.Seq
 	}
-	return ""
+	return 0
 }
 
-func (m *ClientLoginRequest) GetName() string {
+func (m *Message) GetConversation() string {
 	if m != nil {
-		return m.Name
+		return m.Conversation
 	}
 	return ""
 }
 
-type ClientLoginResponse struct {
-	Token string `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+func (m *Message) GetEdited() int64 {
+	if m != nil {
+		return m.Edited
+	}
+	return 0
 }
 
-func (m *ClientLoginResponse) Reset()                    { *m = ClientLoginResponse{} }
-func (m *ClientLoginResponse) String() string            { return proto.CompactTextString(m) }
-func (*ClientLoginResponse) ProtoMessage()               {}
-func (*ClientLoginResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
-
-func (m *ClientLoginResponse) GetToken() string {
+func (m *Message) GetDeleted() bool {
 	if m != nil {
-		return m.Token
+		return m.Deleted
 	}
-	return ""
+	return false
 }
 
-type ClientLogoutRequest struct {
-	Token string `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+func (m *Message) GetReplyTo() string {
+	if m != nil {
+		return m.ReplyTo
+	}
+	return ""
 }
 
-func (m *ClientLogoutRequest) Reset()                    { *m = ClientLogoutRequest{} }
-func (m *ClientLogoutRequest) String() string            { return proto.CompactTextString(m) }
-func (*ClientLogoutRequest) ProtoMessage()               {}
-func (*ClientLogoutRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
-
-func (m *ClientLogoutRequest) GetToken() string {
+func (m *Message) GetThread() string {
 	if m != nil {
-		return m.Token
+		return m.Thread
 	}
 	return ""
 }
 
-type ClientLogoutResponse struct {
+func (m *Message) GetQuote() *Quote {
+	if m != nil {
+		return m.Quote
+	}
+	return nil
 }
 
-func (m *ClientLogoutResponse) Reset()                    { *m = ClientLogoutResponse{} }
-func (m *ClientLogoutResponse) String() string            { return proto.CompactTextString(m) }
-func (*ClientLogoutResponse) ProtoMessage()               {}
-func (*ClientLogoutResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (m *Message) GetReactions() []*Reaction {
+	if m != nil {
+		return m.Reactions
+	}
+	return nil
+}
 
-type Login struct {
-	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+func (m *Message) GetMentions() []string {
+	if m != nil {
+		return m.Mentions
+	}
+	return nil
 }
 
-func (m *Login) Reset()                    { *m = Login{} }
-func (m *Login) String() string            { return proto.CompactTextString(m) }
-func (*Login) ProtoMessage()               {}
-func (*Login) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (m *Message) GetAttachment() *Attachment {
+	if m != nil {
+		return m.Attachment
+	}
+	return nil
+}
 
-func (m *Login) GetName() string {
+func (m *Message) GetSealed() *Sealed {
 	if m != nil {
-		return m.Name
+		return m.Sealed
 	}
-	return ""
+	return nil
 }
 
-type Logout struct {
-	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+type isMessage_Event interface {
+	isMessage_Event()
 }
 
-func (m *Logout) Reset()                    { *m = Logout{} }
-func (m *Logout) String() string            { return proto.CompactTextString(m) }
-func (*Logout) ProtoMessage()               {}
-func (*Logout) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+type Message_Typing struct {
+	Typing *Typing `protobuf:"bytes,9,opt,name=typing,proto3,oneof"`
+}
 
-func (m *Logout) GetName() string {
-	if m != nil {
-		return m.Name
-	}
-	return ""
+type Message_Read struct {
+	Read *ReadReceipt `protobuf:"bytes,10,opt,name=read,proto3,oneof"`
 }
 
-type ChatClient struct {
-	Sender string `protobuf:"bytes,1,opt,name=sender" json:"sender,omitempty"`
+type Message_Join struct {
+	Join *Join `protobuf:"bytes,11,opt,name=join,proto3,oneof"`
 }
 
-func (m *ChatClient) Reset()                    { *m = ChatClient{} }
-func (m *ChatClient) String() string            { return proto.CompactTextString(m) }
-func (*ChatClient) ProtoMessage()               {}
-func (*ChatClient) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+type Message_Leave struct {
+	Leave *Leave `protobuf:"bytes,12,opt,name=leave,proto3,oneof"`
+}
 
-func (m *ChatClient) GetSender() string {
-	if m != nil {
-		return m.Sender
-	}
-	return ""
+type Message_Notice struct {
+	Notice *Notice `protobuf:"bytes,13,opt,name=notice,proto3,oneof"`
 }
 
-type ChatGroup struct {
-	Client string `protobuf:"bytes,1,opt,name=client" json:"client,omitempty"`
-	Name   string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+type Message_Error struct {
+	Error *Error `protobuf:"bytes,14,opt,name=error,proto3,oneof"`
 }
 
-func (m *ChatGroup) Reset()                    { *m = ChatGroup{} }
-func (m *ChatGroup) String() string            { return proto.CompactTextString(m) }
-func (*ChatGroup) ProtoMessage()               {}
-func (*ChatGroup) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+type Message_Ack struct {
+	Ack *Ack `protobuf:"bytes,15,opt,name=ack,proto3,oneof"`
+}
 
-func (m *ChatGroup) GetClient() string {
-	if m != nil {
-		return m.Client
-	}
-	return ""
+type Message_Subscribe struct {
+	Subscribe *Subscribe `protobuf:"bytes,16,opt,name=subscribe,proto3,oneof"`
 }
 
-func (m *ChatGroup) GetName() string {
-	if m != nil {
-		return m.Name
-	}
-	return ""
+type Message_Unsubscribe struct {
+	Unsubscribe *Unsubscribe `protobuf:"bytes,17,opt,name=unsubscribe,proto3,oneof"`
 }
 
-type ChatGroupList struct {
-	Groups []string `protobuf:"bytes,1,rep,name=groups" json:"groups,omitempty"`
+type Message_Edit struct {
+	Edit *Edit `protobuf:"bytes,20,opt,name=edit,proto3,oneof"`
 }
 
-func (m *ChatGroupList) Reset()                    { *m = ChatGroupList{} }
-func (m *ChatGroupList) String() string            { return proto.CompactTextString(m) }
-func (*ChatGroupList) ProtoMessage()               {}
-func (*ChatGroupList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+type Message_Delete struct {
+	Delete *Delete `protobuf:"bytes,21,opt,name=delete,proto3,oneof"`
+}
 
-func (m *ChatGroupList) GetGroups() []string {
+type Message_Reaction struct {
+	Reaction *ReactionChange `protobuf:"bytes,26,opt,name=reaction,proto3,oneof"`
+}
+
+type Message_Mention struct {
+	Mention *Mention `protobuf:"bytes,28,opt,name=mention,proto3,oneof"`
+}
+
+func (*Message_Typing) isMessage_Event() {}
+
+func (*Message_Read) isMessage_Event() {}
+
+func (*Message_Join) isMessage_Event() {}
+
+func (*Message_Leave) isMessage_Event() {}
+
+func (*Message_Notice) isMessage_Event() {}
+
+func (*Message_Error) isMessage_Event() {}
+
+func (*Message_Ack) isMessage_Event() {}
+
+func (*Message_Subscribe) isMessage_Event() {}
+
+func (*Message_Unsubscribe) isMessage_Event() {}
+
+func (*Message_Edit) isMessage_Event() {}
+
+func (*Message_Delete) isMessage_Event() {}
+
+func (*Message_Reaction) isMessage_Event() {}
+
+func (*Message_Mention) isMessage_Event() {}
+
+func (m *Message) GetEvent() isMessage_Event {
 	if m != nil {
-		return m.Groups
+		return m.Event
 	}
 	return nil
 }
 
-type ChatClientList struct {
-	Clients []string `protobuf:"bytes,1,rep,name=clients" json:"clients,omitempty"`
+func (m *Message) GetTyping() *Typing {
+	if x, ok := m.GetEvent().(*Message_Typing); ok {
+		return x.Typing
+	}
+	return nil
 }
 
-func (m *ChatClientList) Reset()                    { *m = ChatClientList{} }
-func (m *ChatClientList) String() string            { return proto.CompactTextString(m) }
-func (*ChatClientList) ProtoMessage()               {}
-func (*ChatClientList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
-
-func (m *ChatClientList) GetClients() []string {
-	if m != nil {
-		return m.Clients
+func (m *Message) GetRead() *ReadReceipt {
+	if x, ok := m.GetEvent().(*Message_Read); ok {
+		return x.Read
 	}
 	return nil
 }
 
-type Empty struct {
+func (m *Message) GetJoin() *Join {
+	if x, ok := m.GetEvent().(*Message_Join); ok {
+		return x.Join
+	}
+	return nil
 }
 
-func (m *Empty) Reset()                    { *m = Empty{} }
-func (m *Empty) String() string            { return proto.CompactTextString(m) }
-func (*Empty) ProtoMessage()               {}
-func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+func (m *Message) GetLeave() *Leave {
+	if x, ok := m.GetEvent().(*Message_Leave); ok {
+		return x.Leave
+	}
+	return nil
+}
 
-func init() {
-	proto.RegisterType((*Message)(nil), "chat.Message")
-	proto.RegisterType((*ClientLoginRequest)(nil), "chat.ClientLoginRequest")
-	proto.RegisterType((*ClientLoginResponse)(nil), "chat.ClientLoginResponse")
-	proto.RegisterType((*ClientLogoutRequest)(nil), "chat.ClientLogoutRequest")
-	proto.RegisterType((*ClientLogoutResponse)(nil), "chat.ClientLogoutResponse")
-	proto.RegisterType((*Login)(nil), "chat.Login")
-	proto.RegisterType((*Logout)(nil), "chat.Logout")
-	proto.RegisterType((*ChatClient)(nil), "chat.ChatClient")
-	proto.RegisterType((*ChatGroup)(nil), "chat.ChatGroup")
-	proto.RegisterType((*ChatGroupList)(nil), "chat.ChatGroupList")
-	proto.RegisterType((*ChatClientList)(nil), "chat.ChatClientList")
-	proto.RegisterType((*Empty)(nil), "chat.Empty")
+func (m *Message) GetNotice() *Notice {
+	if x, ok := m.GetEvent().(*Message_Notice); ok {
+		return x.Notice
+	}
+	return nil
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *Message) GetError() *Error {
+	if x, ok := m.GetEvent().(*Message_Error); ok {
+		return x.Error
+	}
+	return nil
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *Message) GetAck() *Ack {
+	if x, ok := m.GetEvent().(*Message_Ack); ok {
+		return x.Ack
+	}
+	return nil
+}
 
-// Client API for ChatService service
+func (m *Message) GetSubscribe() *Subscribe {
+	if x, ok := m.GetEvent().(*Message_Subscribe); ok {
+		return x.Subscribe
+	}
+	return nil
+}
 
-type ChatServiceClient interface {
-	Login(ctx context.Context, in *ClientLoginRequest, opts ...grpc.CallOption) (*ClientLoginResponse, error)
-	Logout(ctx context.Context, in *ClientLogoutRequest, opts ...grpc.CallOption) (*ClientLogoutResponse, error)
-	RouteChat(ctx context.Context, opts ...grpc.CallOption) (ChatService_RouteChatClient, error)
-	UnRegister(ctx context.Context, in *ChatClient, opts ...grpc.CallOption) (*Empty, error)
-	Register(ctx context.Context, in *ChatClient, opts ...grpc.CallOption) (*Empty, error)
-	CreateChatGroup(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*Empty, error)
-	JoinChatGroup(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*Empty, error)
-	GetChatGroupList(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChatGroupList, error)
-	GetChatGroupClientList(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*ChatClientList, error)
-	GetChatClientList(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChatClientList, error)
-	LeaveChatRoom(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*Empty, error)
+func (m *Message) GetUnsubscribe() *Unsubscribe {
+	if x, ok := m.GetEvent().(*Message_Unsubscribe); ok {
+		return x.Unsubscribe
+	}
+	return nil
 }
 
-type chatServiceClient struct {
-	cc *grpc.ClientConn
+func (m *Message) GetEdit() *Edit {
+	if x, ok := m.GetEvent().(*Message_Edit); ok {
+		return x.Edit
+	}
+	return nil
 }
 
-func NewChatServiceClient(cc *grpc.ClientConn) ChatServiceClient {
-	return &chatServiceClient{cc}
+func (m *Message) GetDelete() *Delete {
+	if x, ok := m.GetEvent().(*Message_Delete); ok {
+		return x.Delete
+	}
+	return nil
 }
 
-func (c *chatServiceClient) Login(ctx context.Context, in *ClientLoginRequest, opts ...grpc.CallOption) (*ClientLoginResponse, error) {
-	out := new(ClientLoginResponse)
-	err := grpc.Invoke(ctx, "/chat.ChatService/Login", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *Message) GetReaction() *ReactionChange {
+	if x, ok := m.GetEvent().(*Message_Reaction); ok {
+		return x.Reaction
 	}
-	return out, nil
+	return nil
 }
 
-func (c *chatServiceClient) Logout(ctx context.Context, in *ClientLogoutRequest, opts ...grpc.CallOption) (*ClientLogoutResponse, error) {
-	out := new(ClientLogoutResponse)
-	err := grpc.Invoke(ctx, "/chat.ChatService/Logout", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *Message) GetMention() *Mention {
+	if x, ok := m.GetEvent().(*Message_Mention); ok {
+		return x.Mention
 	}
-	return out, nil
+	return nil
 }
 
-func (c *chatServiceClient) RouteChat(ctx context.Context, opts ...grpc.CallOption) (ChatService_RouteChatClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_ChatService_serviceDesc.Streams[0], c.cc, "/chat.ChatService/RouteChat", opts...)
-	if err != nil {
-		return nil, err
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Message) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Message_Typing)(nil),
+		(*Message_Read)(nil),
+		(*Message_Join)(nil),
+		(*Message_Leave)(nil),
+		(*Message_Notice)(nil),
+		(*Message_Error)(nil),
+		(*Message_Ack)(nil),
+		(*Message_Subscribe)(nil),
+		(*Message_Unsubscribe)(nil),
+		(*Message_Edit)(nil),
+		(*Message_Delete)(nil),
+		(*Message_Reaction)(nil),
+		(*Message_Mention)(nil),
 	}
-	x := &chatServiceRouteChatClient{stream}
-	return x, nil
 }
 
-type ChatService_RouteChatClient interface {
-	Send(*Message) error
-	Recv() (*Message, error)
-	grpc.ClientStream
+type Typing struct {
+	Started              bool     `protobuf:"varint,1,opt,name=started,proto3" json:"started,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-type chatServiceRouteChatClient struct {
-	grpc.ClientStream
+func (m *Typing) Reset()         { *m = Typing{} }
+func (m *Typing) String() string { return proto.CompactTextString(m) }
+func (*Typing) ProtoMessage()    {}
+func (*Typing) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{1}
 }
 
-func (x *chatServiceRouteChatClient) Send(m *Message) error {
-	return x.ClientStream.SendMsg(m)
+func (m *Typing) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Typing.Unmarshal(m, b)
+}
+func (m *Typing) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Typing.Marshal(b, m, deterministic)
+}
+func (m *Typing) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Typing.Merge(m, src)
+}
+func (m *Typing) XXX_Size() int {
+	return xxx_messageInfo_Typing.Size(m)
+}
+func (m *Typing) XXX_DiscardUnknown() {
+	xxx_messageInfo_Typing.DiscardUnknown(m)
 }
 
-func (x *chatServiceRouteChatClient) Recv() (*Message, error) {
-	m := new(Message)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+var xxx_messageInfo_Typing proto.InternalMessageInfo
+
+func (m *Typing) GetStarted() bool {
+	if m != nil {
+		return m.Started
 	}
-	return m, nil
+	return false
 }
 
-func (c *chatServiceClient) UnRegister(ctx context.Context, in *ChatClient, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
-	err := grpc.Invoke(ctx, "/chat.ChatService/UnRegister", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type Join struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (c *chatServiceClient) Register(ctx context.Context, in *ChatClient, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
-	err := grpc.Invoke(ctx, "/chat.ChatService/Register", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *Join) Reset()         { *m = Join{} }
+func (m *Join) String() string { return proto.CompactTextString(m) }
+func (*Join) ProtoMessage()    {}
+func (*Join) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{2}
+}
+
+func (m *Join) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Join.Unmarshal(m, b)
+}
+func (m *Join) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Join.Marshal(b, m, deterministic)
+}
+func (m *Join) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Join.Merge(m, src)
+}
+func (m *Join) XXX_Size() int {
+	return xxx_messageInfo_Join.Size(m)
+}
+func (m *Join) XXX_DiscardUnknown() {
+	xxx_messageInfo_Join.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Join proto.InternalMessageInfo
+
+type Leave struct {
+	Removed              bool     `protobuf:"varint,1,opt,name=removed,proto3" json:"removed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Leave) Reset()         { *m = Leave{} }
+func (m *Leave) String() string { return proto.CompactTextString(m) }
+func (*Leave) ProtoMessage()    {}
+func (*Leave) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{3}
+}
+
+func (m *Leave) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Leave.Unmarshal(m, b)
+}
+func (m *Leave) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Leave.Marshal(b, m, deterministic)
+}
+func (m *Leave) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Leave.Merge(m, src)
+}
+func (m *Leave) XXX_Size() int {
+	return xxx_messageInfo_Leave.Size(m)
+}
+func (m *Leave) XXX_DiscardUnknown() {
+	xxx_messageInfo_Leave.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Leave proto.InternalMessageInfo
+
+func (m *Leave) GetRemoved() bool {
+	if m != nil {
+		return m.Removed
+	}
+	return false
+}
+
+type Notice struct {
+	Text                 string   `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Notice) Reset()         { *m = Notice{} }
+func (m *Notice) String() string { return proto.CompactTextString(m) }
+func (*Notice) ProtoMessage()    {}
+func (*Notice) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{4}
+}
+
+func (m *Notice) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Notice.Unmarshal(m, b)
+}
+func (m *Notice) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Notice.Marshal(b, m, deterministic)
+}
+func (m *Notice) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Notice.Merge(m, src)
+}
+func (m *Notice) XXX_Size() int {
+	return xxx_messageInfo_Notice.Size(m)
+}
+func (m *Notice) XXX_DiscardUnknown() {
+	xxx_messageInfo_Notice.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Notice proto.InternalMessageInfo
+
+func (m *Notice) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+type Error struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Error) Reset()         { *m = Error{} }
+func (m *Error) String() string { return proto.CompactTextString(m) }
+func (*Error) ProtoMessage()    {}
+func (*Error) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{5}
+}
+
+func (m *Error) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Error.Unmarshal(m, b)
+}
+func (m *Error) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Error.Marshal(b, m, deterministic)
+}
+func (m *Error) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Error.Merge(m, src)
+}
+func (m *Error) XXX_Size() int {
+	return xxx_messageInfo_Error.Size(m)
+}
+func (m *Error) XXX_DiscardUnknown() {
+	xxx_messageInfo_Error.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Error proto.InternalMessageInfo
+
+func (m *Error) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *Error) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type Subscribe struct {
+	ResumeFrom           int64    `protobuf:"varint,1,opt,name=resume_from,json=resumeFrom,proto3" json:"resume_from,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Subscribe) Reset()         { *m = Subscribe{} }
+func (m *Subscribe) String() string { return proto.CompactTextString(m) }
+func (*Subscribe) ProtoMessage()    {}
+func (*Subscribe) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{6}
+}
+
+func (m *Subscribe) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Subscribe.Unmarshal(m, b)
+}
+func (m *Subscribe) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Subscribe.Marshal(b, m, deterministic)
+}
+func (m *Subscribe) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Subscribe.Merge(m, src)
+}
+func (m *Subscribe) XXX_Size() int {
+	return xxx_messageInfo_Subscribe.Size(m)
+}
+func (m *Subscribe) XXX_DiscardUnknown() {
+	xxx_messageInfo_Subscribe.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Subscribe proto.InternalMessageInfo
+
+func (m *Subscribe) GetResumeFrom() int64 {
+	if m != nil {
+		return m.ResumeFrom
+	}
+	return 0
+}
+
+type Unsubscribe struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Unsubscribe) Reset()         { *m = Unsubscribe{} }
+func (m *Unsubscribe) String() string { return proto.CompactTextString(m) }
+func (*Unsubscribe) ProtoMessage()    {}
+func (*Unsubscribe) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{7}
+}
+
+func (m *Unsubscribe) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Unsubscribe.Unmarshal(m, b)
+}
+func (m *Unsubscribe) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Unsubscribe.Marshal(b, m, deterministic)
+}
+func (m *Unsubscribe) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Unsubscribe.Merge(m, src)
+}
+func (m *Unsubscribe) XXX_Size() int {
+	return xxx_messageInfo_Unsubscribe.Size(m)
+}
+func (m *Unsubscribe) XXX_DiscardUnknown() {
+	xxx_messageInfo_Unsubscribe.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Unsubscribe proto.InternalMessageInfo
+
+type Quote struct {
+	Sender               string   `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Body                 string   `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Quote) Reset()         { *m = Quote{} }
+func (m *Quote) String() string { return proto.CompactTextString(m) }
+func (*Quote) ProtoMessage()    {}
+func (*Quote) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{8}
+}
+
+func (m *Quote) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Quote.Unmarshal(m, b)
+}
+func (m *Quote) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Quote.Marshal(b, m, deterministic)
+}
+func (m *Quote) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Quote.Merge(m, src)
+}
+func (m *Quote) XXX_Size() int {
+	return xxx_messageInfo_Quote.Size(m)
+}
+func (m *Quote) XXX_DiscardUnknown() {
+	xxx_messageInfo_Quote.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Quote proto.InternalMessageInfo
+
+func (m *Quote) GetSender() string {
+	if m != nil {
+		return m.Sender
+	}
+	return ""
+}
+
+func (m *Quote) GetBody() string {
+	if m != nil {
+		return m.Body
+	}
+	return ""
+}
+
+type Reaction struct {
+	Emoji                string   `protobuf:"bytes,1,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	Senders              []string `protobuf:"bytes,2,rep,name=senders,proto3" json:"senders,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Reaction) Reset()         { *m = Reaction{} }
+func (m *Reaction) String() string { return proto.CompactTextString(m) }
+func (*Reaction) ProtoMessage()    {}
+func (*Reaction) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{9}
+}
+
+func (m *Reaction) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Reaction.Unmarshal(m, b)
+}
+func (m *Reaction) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Reaction.Marshal(b, m, deterministic)
+}
+func (m *Reaction) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Reaction.Merge(m, src)
+}
+func (m *Reaction) XXX_Size() int {
+	return xxx_messageInfo_Reaction.Size(m)
+}
+func (m *Reaction) XXX_DiscardUnknown() {
+	xxx_messageInfo_Reaction.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Reaction proto.InternalMessageInfo
+
+func (m *Reaction) GetEmoji() string {
+	if m != nil {
+		return m.Emoji
+	}
+	return ""
+}
+
+func (m *Reaction) GetSenders() []string {
+	if m != nil {
+		return m.Senders
+	}
+	return nil
+}
+
+type ReactionRequest struct {
+	Sender               string   `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Group                string   `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	Conversation         string   `protobuf:"bytes,3,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	Id                   string   `protobuf:"bytes,4,opt,name=id,proto3" json:"id,omitempty"`
+	Emoji                string   `protobuf:"bytes,5,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReactionRequest) Reset()         { *m = ReactionRequest{} }
+func (m *ReactionRequest) String() string { return proto.CompactTextString(m) }
+func (*ReactionRequest) ProtoMessage()    {}
+func (*ReactionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{10}
+}
+
+func (m *ReactionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReactionRequest.Unmarshal(m, b)
+}
+func (m *ReactionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReactionRequest.Marshal(b, m, deterministic)
+}
+func (m *ReactionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReactionRequest.Merge(m, src)
+}
+func (m *ReactionRequest) XXX_Size() int {
+	return xxx_messageInfo_ReactionRequest.Size(m)
+}
+func (m *ReactionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReactionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReactionRequest proto.InternalMessageInfo
+
+func (m *ReactionRequest) GetSender() string {
+	if m != nil {
+		return m.Sender
+	}
+	return ""
+}
+
+func (m *ReactionRequest) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *ReactionRequest) GetConversation() string {
+	if m != nil {
+		return m.Conversation
+	}
+	return ""
+}
+
+func (m *ReactionRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ReactionRequest) GetEmoji() string {
+	if m != nil {
+		return m.Emoji
+	}
+	return ""
+}
+
+type ReactionChange struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Seq                  int64    `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Emoji                string   `protobuf:"bytes,3,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	Removed              bool     `protobuf:"varint,4,opt,name=removed,proto3" json:"removed,omitempty"`
+	Count                int32    `protobuf:"varint,5,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReactionChange) Reset()         { *m = ReactionChange{} }
+func (m *ReactionChange) String() string { return proto.CompactTextString(m) }
+func (*ReactionChange) ProtoMessage()    {}
+func (*ReactionChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{11}
+}
+
+func (m *ReactionChange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReactionChange.Unmarshal(m, b)
+}
+func (m *ReactionChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReactionChange.Marshal(b, m, deterministic)
+}
+func (m *ReactionChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReactionChange.Merge(m, src)
+}
+func (m *ReactionChange) XXX_Size() int {
+	return xxx_messageInfo_ReactionChange.Size(m)
+}
+func (m *ReactionChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReactionChange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReactionChange proto.InternalMessageInfo
+
+func (m *ReactionChange) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ReactionChange) GetSeq() int64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *ReactionChange) GetEmoji() string {
+	if m != nil {
+		return m.Emoji
+	}
+	return ""
+}
+
+func (m *ReactionChange) GetRemoved() bool {
+	if m != nil {
+		return m.Removed
+	}
+	return false
+}
+
+func (m *ReactionChange) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type Mention struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Seq                  int64    `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Body                 string   `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Mention) Reset()         { *m = Mention{} }
+func (m *Mention) String() string { return proto.CompactTextString(m) }
+func (*Mention) ProtoMessage()    {}
+func (*Mention) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{12}
+}
+
+func (m *Mention) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Mention.Unmarshal(m, b)
+}
+func (m *Mention) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Mention.Marshal(b, m, deterministic)
+}
+func (m *Mention) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Mention.Merge(m, src)
+}
+func (m *Mention) XXX_Size() int {
+	return xxx_messageInfo_Mention.Size(m)
+}
+func (m *Mention) XXX_DiscardUnknown() {
+	xxx_messageInfo_Mention.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Mention proto.InternalMessageInfo
+
+func (m *Mention) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Mention) GetSeq() int64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *Mention) GetBody() string {
+	if m != nil {
+		return m.Body
+	}
+	return ""
+}
+
+type Edit struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Seq                  int64    `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Body                 string   `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Edit) Reset()         { *m = Edit{} }
+func (m *Edit) String() string { return proto.CompactTextString(m) }
+func (*Edit) ProtoMessage()    {}
+func (*Edit) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{13}
+}
+
+func (m *Edit) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Edit.Unmarshal(m, b)
+}
+func (m *Edit) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Edit.Marshal(b, m, deterministic)
+}
+func (m *Edit) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Edit.Merge(m, src)
+}
+func (m *Edit) XXX_Size() int {
+	return xxx_messageInfo_Edit.Size(m)
+}
+func (m *Edit) XXX_DiscardUnknown() {
+	xxx_messageInfo_Edit.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Edit proto.InternalMessageInfo
+
+func (m *Edit) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Edit) GetSeq() int64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *Edit) GetBody() string {
+	if m != nil {
+		return m.Body
+	}
+	return ""
+}
+
+type Delete struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Seq                  int64    `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Delete) Reset()         { *m = Delete{} }
+func (m *Delete) String() string { return proto.CompactTextString(m) }
+func (*Delete) ProtoMessage()    {}
+func (*Delete) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{14}
+}
+
+func (m *Delete) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Delete.Unmarshal(m, b)
+}
+func (m *Delete) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Delete.Marshal(b, m, deterministic)
+}
+func (m *Delete) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Delete.Merge(m, src)
+}
+func (m *Delete) XXX_Size() int {
+	return xxx_messageInfo_Delete.Size(m)
+}
+func (m *Delete) XXX_DiscardUnknown() {
+	xxx_messageInfo_Delete.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Delete proto.InternalMessageInfo
+
+func (m *Delete) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Delete) GetSeq() int64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+type Ack struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Seq                  int64    `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Timestamp            int64    `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Ack) Reset()         { *m = Ack{} }
+func (m *Ack) String() string { return proto.CompactTextString(m) }
+func (*Ack) ProtoMessage()    {}
+func (*Ack) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{15}
+}
+
+func (m *Ack) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Ack.Unmarshal(m, b)
+}
+func (m *Ack) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Ack.Marshal(b, m, deterministic)
+}
+func (m *Ack) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Ack.Merge(m, src)
+}
+func (m *Ack) XXX_Size() int {
+	return xxx_messageInfo_Ack.Size(m)
+}
+func (m *Ack) XXX_DiscardUnknown() {
+	xxx_messageInfo_Ack.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Ack proto.InternalMessageInfo
+
+func (m *Ack) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Ack) GetSeq() int64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *Ack) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+type ReadReceipt struct {
+	Seq                  int64    `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReadReceipt) Reset()         { *m = ReadReceipt{} }
+func (m *ReadReceipt) String() string { return proto.CompactTextString(m) }
+func (*ReadReceipt) ProtoMessage()    {}
+func (*ReadReceipt) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{16}
+}
+
+func (m *ReadReceipt) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadReceipt.Unmarshal(m, b)
+}
+func (m *ReadReceipt) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadReceipt.Marshal(b, m, deterministic)
+}
+func (m *ReadReceipt) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadReceipt.Merge(m, src)
+}
+func (m *ReadReceipt) XXX_Size() int {
+	return xxx_messageInfo_ReadReceipt.Size(m)
+}
+func (m *ReadReceipt) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadReceipt.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadReceipt proto.InternalMessageInfo
+
+func (m *ReadReceipt) GetSeq() int64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+type ClientLoginRequest struct {
+	Password             string   `protobuf:"bytes,1,opt,name=password,proto3" json:"password,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Previous             string   `protobuf:"bytes,3,opt,name=previous,proto3" json:"previous,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClientLoginRequest) Reset()         { *m = ClientLoginRequest{} }
+func (m *ClientLoginRequest) String() string { return proto.CompactTextString(m) }
+func (*ClientLoginRequest) ProtoMessage()    {}
+func (*ClientLoginRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{17}
+}
+
+func (m *ClientLoginRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClientLoginRequest.Unmarshal(m, b)
+}
+func (m *ClientLoginRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClientLoginRequest.Marshal(b, m, deterministic)
+}
+func (m *ClientLoginRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClientLoginRequest.Merge(m, src)
+}
+func (m *ClientLoginRequest) XXX_Size() int {
+	return xxx_messageInfo_ClientLoginRequest.Size(m)
+}
+func (m *ClientLoginRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClientLoginRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClientLoginRequest proto.InternalMessageInfo
+
+func (m *ClientLoginRequest) GetPassword() string {
+	if m != nil {
+		return m.Password
+	}
+	return ""
+}
+
+func (m *ClientLoginRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ClientLoginRequest) GetPrevious() string {
+	if m != nil {
+		return m.Previous
+	}
+	return ""
+}
+
+type ClientLoginResponse struct {
+	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Expires              int64    `protobuf:"varint,3,opt,name=expires,proto3" json:"expires,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClientLoginResponse) Reset()         { *m = ClientLoginResponse{} }
+func (m *ClientLoginResponse) String() string { return proto.CompactTextString(m) }
+func (*ClientLoginResponse) ProtoMessage()    {}
+func (*ClientLoginResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{18}
+}
+
+func (m *ClientLoginResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClientLoginResponse.Unmarshal(m, b)
+}
+func (m *ClientLoginResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClientLoginResponse.Marshal(b, m, deterministic)
+}
+func (m *ClientLoginResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClientLoginResponse.Merge(m, src)
+}
+func (m *ClientLoginResponse) XXX_Size() int {
+	return xxx_messageInfo_ClientLoginResponse.Size(m)
+}
+func (m *ClientLoginResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClientLoginResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClientLoginResponse proto.InternalMessageInfo
+
+func (m *ClientLoginResponse) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *ClientLoginResponse) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ClientLoginResponse) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+type PasswordChange struct {
+	OldPassword          string   `protobuf:"bytes,1,opt,name=old_password,json=oldPassword,proto3" json:"old_password,omitempty"`
+	NewPassword          string   `protobuf:"bytes,2,opt,name=new_password,json=newPassword,proto3" json:"new_password,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PasswordChange) Reset()         { *m = PasswordChange{} }
+func (m *PasswordChange) String() string { return proto.CompactTextString(m) }
+func (*PasswordChange) ProtoMessage()    {}
+func (*PasswordChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{19}
+}
+
+func (m *PasswordChange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PasswordChange.Unmarshal(m, b)
+}
+func (m *PasswordChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PasswordChange.Marshal(b, m, deterministic)
+}
+func (m *PasswordChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PasswordChange.Merge(m, src)
+}
+func (m *PasswordChange) XXX_Size() int {
+	return xxx_messageInfo_PasswordChange.Size(m)
+}
+func (m *PasswordChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_PasswordChange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PasswordChange proto.InternalMessageInfo
+
+func (m *PasswordChange) GetOldPassword() string {
+	if m != nil {
+		return m.OldPassword
+	}
+	return ""
+}
+
+func (m *PasswordChange) GetNewPassword() string {
+	if m != nil {
+		return m.NewPassword
+	}
+	return ""
+}
+
+type AccountState struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Disabled             bool     `protobuf:"varint,2,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AccountState) Reset()         { *m = AccountState{} }
+func (m *AccountState) String() string { return proto.CompactTextString(m) }
+func (*AccountState) ProtoMessage()    {}
+func (*AccountState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{20}
+}
+
+func (m *AccountState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AccountState.Unmarshal(m, b)
+}
+func (m *AccountState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AccountState.Marshal(b, m, deterministic)
+}
+func (m *AccountState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AccountState.Merge(m, src)
+}
+func (m *AccountState) XXX_Size() int {
+	return xxx_messageInfo_AccountState.Size(m)
+}
+func (m *AccountState) XXX_DiscardUnknown() {
+	xxx_messageInfo_AccountState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AccountState proto.InternalMessageInfo
+
+func (m *AccountState) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *AccountState) GetDisabled() bool {
+	if m != nil {
+		return m.Disabled
+	}
+	return false
+}
+
+type ClientLogoutRequest struct {
+	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	All                  bool     `protobuf:"varint,2,opt,name=all,proto3" json:"all,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClientLogoutRequest) Reset()         { *m = ClientLogoutRequest{} }
+func (m *ClientLogoutRequest) String() string { return proto.CompactTextString(m) }
+func (*ClientLogoutRequest) ProtoMessage()    {}
+func (*ClientLogoutRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{21}
+}
+
+func (m *ClientLogoutRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClientLogoutRequest.Unmarshal(m, b)
+}
+func (m *ClientLogoutRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClientLogoutRequest.Marshal(b, m, deterministic)
+}
+func (m *ClientLogoutRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClientLogoutRequest.Merge(m, src)
+}
+func (m *ClientLogoutRequest) XXX_Size() int {
+	return xxx_messageInfo_ClientLogoutRequest.Size(m)
+}
+func (m *ClientLogoutRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClientLogoutRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClientLogoutRequest proto.InternalMessageInfo
+
+func (m *ClientLogoutRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *ClientLogoutRequest) GetAll() bool {
+	if m != nil {
+		return m.All
+	}
+	return false
+}
+
+type ClientLogoutResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClientLogoutResponse) Reset()         { *m = ClientLogoutResponse{} }
+func (m *ClientLogoutResponse) String() string { return proto.CompactTextString(m) }
+func (*ClientLogoutResponse) ProtoMessage()    {}
+func (*ClientLogoutResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{22}
+}
+
+func (m *ClientLogoutResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClientLogoutResponse.Unmarshal(m, b)
+}
+func (m *ClientLogoutResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClientLogoutResponse.Marshal(b, m, deterministic)
+}
+func (m *ClientLogoutResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClientLogoutResponse.Merge(m, src)
+}
+func (m *ClientLogoutResponse) XXX_Size() int {
+	return xxx_messageInfo_ClientLogoutResponse.Size(m)
+}
+func (m *ClientLogoutResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClientLogoutResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClientLogoutResponse proto.InternalMessageInfo
+
+type Login struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Login) Reset()         { *m = Login{} }
+func (m *Login) String() string { return proto.CompactTextString(m) }
+func (*Login) ProtoMessage()    {}
+func (*Login) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{23}
+}
+
+func (m *Login) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Login.Unmarshal(m, b)
+}
+func (m *Login) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Login.Marshal(b, m, deterministic)
+}
+func (m *Login) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Login.Merge(m, src)
+}
+func (m *Login) XXX_Size() int {
+	return xxx_messageInfo_Login.Size(m)
+}
+func (m *Login) XXX_DiscardUnknown() {
+	xxx_messageInfo_Login.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Login proto.InternalMessageInfo
+
+func (m *Login) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type Logout struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Logout) Reset()         { *m = Logout{} }
+func (m *Logout) String() string { return proto.CompactTextString(m) }
+func (*Logout) ProtoMessage()    {}
+func (*Logout) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{24}
+}
+
+func (m *Logout) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Logout.Unmarshal(m, b)
+}
+func (m *Logout) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Logout.Marshal(b, m, deterministic)
+}
+func (m *Logout) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Logout.Merge(m, src)
+}
+func (m *Logout) XXX_Size() int {
+	return xxx_messageInfo_Logout.Size(m)
+}
+func (m *Logout) XXX_DiscardUnknown() {
+	xxx_messageInfo_Logout.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Logout proto.InternalMessageInfo
+
+func (m *Logout) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type ChatClient struct {
+	Sender               string   `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ChatClient) Reset()         { *m = ChatClient{} }
+func (m *ChatClient) String() string { return proto.CompactTextString(m) }
+func (*ChatClient) ProtoMessage()    {}
+func (*ChatClient) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{25}
+}
+
+func (m *ChatClient) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChatClient.Unmarshal(m, b)
+}
+func (m *ChatClient) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChatClient.Marshal(b, m, deterministic)
+}
+func (m *ChatClient) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChatClient.Merge(m, src)
+}
+func (m *ChatClient) XXX_Size() int {
+	return xxx_messageInfo_ChatClient.Size(m)
+}
+func (m *ChatClient) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChatClient.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChatClient proto.InternalMessageInfo
+
+func (m *ChatClient) GetSender() string {
+	if m != nil {
+		return m.Sender
+	}
+	return ""
+}
+
+type ChatGroup struct {
+	Client               string     `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
+	Name                 string     `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	OverflowPolicy       string     `protobuf:"bytes,3,opt,name=overflow_policy,json=overflowPolicy,proto3" json:"overflow_policy,omitempty"`
+	Visibility           Visibility `protobuf:"varint,4,opt,name=visibility,proto3,enum=chat.Visibility" json:"visibility,omitempty"`
+	Password             string     `protobuf:"bytes,5,opt,name=password,proto3" json:"password,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ChatGroup) Reset()         { *m = ChatGroup{} }
+func (m *ChatGroup) String() string { return proto.CompactTextString(m) }
+func (*ChatGroup) ProtoMessage()    {}
+func (*ChatGroup) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{26}
+}
+
+func (m *ChatGroup) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChatGroup.Unmarshal(m, b)
+}
+func (m *ChatGroup) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChatGroup.Marshal(b, m, deterministic)
+}
+func (m *ChatGroup) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChatGroup.Merge(m, src)
+}
+func (m *ChatGroup) XXX_Size() int {
+	return xxx_messageInfo_ChatGroup.Size(m)
+}
+func (m *ChatGroup) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChatGroup.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChatGroup proto.InternalMessageInfo
+
+func (m *ChatGroup) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *ChatGroup) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ChatGroup) GetOverflowPolicy() string {
+	if m != nil {
+		return m.OverflowPolicy
+	}
+	return ""
+}
+
+func (m *ChatGroup) GetVisibility() Visibility {
+	if m != nil {
+		return m.Visibility
+	}
+	return Visibility_PUBLIC
+}
+
+func (m *ChatGroup) GetPassword() string {
+	if m != nil {
+		return m.Password
+	}
+	return ""
+}
+
+type ChatGroupList struct {
+	Groups               []string `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ChatGroupList) Reset()         { *m = ChatGroupList{} }
+func (m *ChatGroupList) String() string { return proto.CompactTextString(m) }
+func (*ChatGroupList) ProtoMessage()    {}
+func (*ChatGroupList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{27}
+}
+
+func (m *ChatGroupList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChatGroupList.Unmarshal(m, b)
+}
+func (m *ChatGroupList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChatGroupList.Marshal(b, m, deterministic)
+}
+func (m *ChatGroupList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChatGroupList.Merge(m, src)
+}
+func (m *ChatGroupList) XXX_Size() int {
+	return xxx_messageInfo_ChatGroupList.Size(m)
+}
+func (m *ChatGroupList) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChatGroupList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChatGroupList proto.InternalMessageInfo
+
+func (m *ChatGroupList) GetGroups() []string {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+type ClientInfo struct {
+	Name                 string    `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Devices              int32     `protobuf:"varint,2,opt,name=devices,proto3" json:"devices,omitempty"`
+	Online               int32     `protobuf:"varint,3,opt,name=online,proto3" json:"online,omitempty"`
+	Presence             *Presence `protobuf:"bytes,4,opt,name=presence,proto3" json:"presence,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ClientInfo) Reset()         { *m = ClientInfo{} }
+func (m *ClientInfo) String() string { return proto.CompactTextString(m) }
+func (*ClientInfo) ProtoMessage()    {}
+func (*ClientInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{28}
+}
+
+func (m *ClientInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClientInfo.Unmarshal(m, b)
+}
+func (m *ClientInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClientInfo.Marshal(b, m, deterministic)
+}
+func (m *ClientInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClientInfo.Merge(m, src)
+}
+func (m *ClientInfo) XXX_Size() int {
+	return xxx_messageInfo_ClientInfo.Size(m)
+}
+func (m *ClientInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClientInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClientInfo proto.InternalMessageInfo
+
+func (m *ClientInfo) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ClientInfo) GetDevices() int32 {
+	if m != nil {
+		return m.Devices
+	}
+	return 0
+}
+
+func (m *ClientInfo) GetOnline() int32 {
+	if m != nil {
+		return m.Online
+	}
+	return 0
+}
+
+func (m *ClientInfo) GetPresence() *Presence {
+	if m != nil {
+		return m.Presence
+	}
+	return nil
+}
+
+type ChatClientList struct {
+	Clients              []string      `protobuf:"bytes,1,rep,name=clients,proto3" json:"clients,omitempty"`
+	Infos                []*ClientInfo `protobuf:"bytes,2,rep,name=infos,proto3" json:"infos,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ChatClientList) Reset()         { *m = ChatClientList{} }
+func (m *ChatClientList) String() string { return proto.CompactTextString(m) }
+func (*ChatClientList) ProtoMessage()    {}
+func (*ChatClientList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{29}
+}
+
+func (m *ChatClientList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChatClientList.Unmarshal(m, b)
+}
+func (m *ChatClientList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChatClientList.Marshal(b, m, deterministic)
+}
+func (m *ChatClientList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChatClientList.Merge(m, src)
+}
+func (m *ChatClientList) XXX_Size() int {
+	return xxx_messageInfo_ChatClientList.Size(m)
+}
+func (m *ChatClientList) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChatClientList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChatClientList proto.InternalMessageInfo
+
+func (m *ChatClientList) GetClients() []string {
+	if m != nil {
+		return m.Clients
+	}
+	return nil
+}
+
+func (m *ChatClientList) GetInfos() []*ClientInfo {
+	if m != nil {
+		return m.Infos
+	}
+	return nil
+}
+
+type Empty struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Empty) Reset()         { *m = Empty{} }
+func (m *Empty) String() string { return proto.CompactTextString(m) }
+func (*Empty) ProtoMessage()    {}
+func (*Empty) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{30}
+}
+
+func (m *Empty) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Empty.Unmarshal(m, b)
+}
+func (m *Empty) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Empty.Marshal(b, m, deterministic)
+}
+func (m *Empty) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Empty.Merge(m, src)
+}
+func (m *Empty) XXX_Size() int {
+	return xxx_messageInfo_Empty.Size(m)
+}
+func (m *Empty) XXX_DiscardUnknown() {
+	xxx_messageInfo_Empty.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Empty proto.InternalMessageInfo
+
+type HistoryRequest struct {
+	Group                *ChatGroup `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Before               int64      `protobuf:"varint,2,opt,name=before,proto3" json:"before,omitempty"`
+	Limit                int32      `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Conversation         string     `protobuf:"bytes,4,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	Thread               string     `protobuf:"bytes,5,opt,name=thread,proto3" json:"thread,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *HistoryRequest) Reset()         { *m = HistoryRequest{} }
+func (m *HistoryRequest) String() string { return proto.CompactTextString(m) }
+func (*HistoryRequest) ProtoMessage()    {}
+func (*HistoryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{31}
+}
+
+func (m *HistoryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HistoryRequest.Unmarshal(m, b)
+}
+func (m *HistoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HistoryRequest.Marshal(b, m, deterministic)
+}
+func (m *HistoryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HistoryRequest.Merge(m, src)
+}
+func (m *HistoryRequest) XXX_Size() int {
+	return xxx_messageInfo_HistoryRequest.Size(m)
+}
+func (m *HistoryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_HistoryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HistoryRequest proto.InternalMessageInfo
+
+func (m *HistoryRequest) GetGroup() *ChatGroup {
+	if m != nil {
+		return m.Group
+	}
+	return nil
+}
+
+func (m *HistoryRequest) GetBefore() int64 {
+	if m != nil {
+		return m.Before
+	}
+	return 0
+}
+
+func (m *HistoryRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *HistoryRequest) GetConversation() string {
+	if m != nil {
+		return m.Conversation
+	}
+	return ""
+}
+
+func (m *HistoryRequest) GetThread() string {
+	if m != nil {
+		return m.Thread
+	}
+	return ""
+}
+
+type Attachment struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Size                 int64    `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Attachment) Reset()         { *m = Attachment{} }
+func (m *Attachment) String() string { return proto.CompactTextString(m) }
+func (*Attachment) ProtoMessage()    {}
+func (*Attachment) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{32}
+}
+
+func (m *Attachment) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Attachment.Unmarshal(m, b)
+}
+func (m *Attachment) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Attachment.Marshal(b, m, deterministic)
+}
+func (m *Attachment) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Attachment.Merge(m, src)
+}
+func (m *Attachment) XXX_Size() int {
+	return xxx_messageInfo_Attachment.Size(m)
+}
+func (m *Attachment) XXX_DiscardUnknown() {
+	xxx_messageInfo_Attachment.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Attachment proto.InternalMessageInfo
+
+func (m *Attachment) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Attachment) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Attachment) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+type AttachmentChunk struct {
+	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Sha256               string   `protobuf:"bytes,2,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Size                 int64    `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AttachmentChunk) Reset()         { *m = AttachmentChunk{} }
+func (m *AttachmentChunk) String() string { return proto.CompactTextString(m) }
+func (*AttachmentChunk) ProtoMessage()    {}
+func (*AttachmentChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{33}
+}
+
+func (m *AttachmentChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AttachmentChunk.Unmarshal(m, b)
+}
+func (m *AttachmentChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AttachmentChunk.Marshal(b, m, deterministic)
+}
+func (m *AttachmentChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AttachmentChunk.Merge(m, src)
+}
+func (m *AttachmentChunk) XXX_Size() int {
+	return xxx_messageInfo_AttachmentChunk.Size(m)
+}
+func (m *AttachmentChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_AttachmentChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AttachmentChunk proto.InternalMessageInfo
+
+func (m *AttachmentChunk) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *AttachmentChunk) GetSha256() string {
+	if m != nil {
+		return m.Sha256
+	}
+	return ""
+}
+
+func (m *AttachmentChunk) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+type AttachmentRequest struct {
+	Group                string   `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Conversation         string   `protobuf:"bytes,2,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	Message              string   `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AttachmentRequest) Reset()         { *m = AttachmentRequest{} }
+func (m *AttachmentRequest) String() string { return proto.CompactTextString(m) }
+func (*AttachmentRequest) ProtoMessage()    {}
+func (*AttachmentRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{34}
+}
+
+func (m *AttachmentRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AttachmentRequest.Unmarshal(m, b)
+}
+func (m *AttachmentRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AttachmentRequest.Marshal(b, m, deterministic)
+}
+func (m *AttachmentRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AttachmentRequest.Merge(m, src)
+}
+func (m *AttachmentRequest) XXX_Size() int {
+	return xxx_messageInfo_AttachmentRequest.Size(m)
+}
+func (m *AttachmentRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AttachmentRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AttachmentRequest proto.InternalMessageInfo
+
+func (m *AttachmentRequest) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *AttachmentRequest) GetConversation() string {
+	if m != nil {
+		return m.Conversation
+	}
+	return ""
+}
+
+func (m *AttachmentRequest) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type PublicKey struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Key                  []byte   `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PublicKey) Reset()         { *m = PublicKey{} }
+func (m *PublicKey) String() string { return proto.CompactTextString(m) }
+func (*PublicKey) ProtoMessage()    {}
+func (*PublicKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{35}
+}
+
+func (m *PublicKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PublicKey.Unmarshal(m, b)
+}
+func (m *PublicKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PublicKey.Marshal(b, m, deterministic)
+}
+func (m *PublicKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PublicKey.Merge(m, src)
+}
+func (m *PublicKey) XXX_Size() int {
+	return xxx_messageInfo_PublicKey.Size(m)
+}
+func (m *PublicKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_PublicKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PublicKey proto.InternalMessageInfo
+
+func (m *PublicKey) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *PublicKey) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+type KeyList struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Keys                 [][]byte `protobuf:"bytes,2,rep,name=keys,proto3" json:"keys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeyList) Reset()         { *m = KeyList{} }
+func (m *KeyList) String() string { return proto.CompactTextString(m) }
+func (*KeyList) ProtoMessage()    {}
+func (*KeyList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{36}
+}
+
+func (m *KeyList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeyList.Unmarshal(m, b)
+}
+func (m *KeyList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeyList.Marshal(b, m, deterministic)
+}
+func (m *KeyList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeyList.Merge(m, src)
+}
+func (m *KeyList) XXX_Size() int {
+	return xxx_messageInfo_KeyList.Size(m)
+}
+func (m *KeyList) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeyList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeyList proto.InternalMessageInfo
+
+func (m *KeyList) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *KeyList) GetKeys() [][]byte {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+type Sealed struct {
+	SenderKey            []byte        `protobuf:"bytes,1,opt,name=sender_key,json=senderKey,proto3" json:"sender_key,omitempty"`
+	Copies               []*SealedCopy `protobuf:"bytes,4,rep,name=copies,proto3" json:"copies,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *Sealed) Reset()         { *m = Sealed{} }
+func (m *Sealed) String() string { return proto.CompactTextString(m) }
+func (*Sealed) ProtoMessage()    {}
+func (*Sealed) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{37}
+}
+
+func (m *Sealed) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Sealed.Unmarshal(m, b)
+}
+func (m *Sealed) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Sealed.Marshal(b, m, deterministic)
+}
+func (m *Sealed) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Sealed.Merge(m, src)
+}
+func (m *Sealed) XXX_Size() int {
+	return xxx_messageInfo_Sealed.Size(m)
+}
+func (m *Sealed) XXX_DiscardUnknown() {
+	xxx_messageInfo_Sealed.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Sealed proto.InternalMessageInfo
+
+func (m *Sealed) GetSenderKey() []byte {
+	if m != nil {
+		return m.SenderKey
+	}
+	return nil
+}
+
+func (m *Sealed) GetCopies() []*SealedCopy {
+	if m != nil {
+		return m.Copies
+	}
+	return nil
+}
+
+type SealedCopy struct {
+	Key                  []byte   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Ciphertext           []byte   `protobuf:"bytes,2,opt,name=ciphertext,proto3" json:"ciphertext,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SealedCopy) Reset()         { *m = SealedCopy{} }
+func (m *SealedCopy) String() string { return proto.CompactTextString(m) }
+func (*SealedCopy) ProtoMessage()    {}
+func (*SealedCopy) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{38}
+}
+
+func (m *SealedCopy) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SealedCopy.Unmarshal(m, b)
+}
+func (m *SealedCopy) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SealedCopy.Marshal(b, m, deterministic)
+}
+func (m *SealedCopy) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SealedCopy.Merge(m, src)
+}
+func (m *SealedCopy) XXX_Size() int {
+	return xxx_messageInfo_SealedCopy.Size(m)
+}
+func (m *SealedCopy) XXX_DiscardUnknown() {
+	xxx_messageInfo_SealedCopy.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SealedCopy proto.InternalMessageInfo
+
+func (m *SealedCopy) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *SealedCopy) GetCiphertext() []byte {
+	if m != nil {
+		return m.Ciphertext
+	}
+	return nil
+}
+
+type SearchRequest struct {
+	Query                string   `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Group                string   `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	Sender               string   `protobuf:"bytes,3,opt,name=sender,proto3" json:"sender,omitempty"`
+	Since                int64    `protobuf:"varint,4,opt,name=since,proto3" json:"since,omitempty"`
+	Until                int64    `protobuf:"varint,5,opt,name=until,proto3" json:"until,omitempty"`
+	Limit                int32    `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	Before               int64    `protobuf:"varint,7,opt,name=before,proto3" json:"before,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SearchRequest) Reset()         { *m = SearchRequest{} }
+func (m *SearchRequest) String() string { return proto.CompactTextString(m) }
+func (*SearchRequest) ProtoMessage()    {}
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{39}
+}
+
+func (m *SearchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchRequest.Unmarshal(m, b)
+}
+func (m *SearchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchRequest.Marshal(b, m, deterministic)
+}
+func (m *SearchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchRequest.Merge(m, src)
+}
+func (m *SearchRequest) XXX_Size() int {
+	return xxx_messageInfo_SearchRequest.Size(m)
+}
+func (m *SearchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchRequest proto.InternalMessageInfo
+
+func (m *SearchRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *SearchRequest) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *SearchRequest) GetSender() string {
+	if m != nil {
+		return m.Sender
+	}
+	return ""
+}
+
+func (m *SearchRequest) GetSince() int64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+func (m *SearchRequest) GetUntil() int64 {
+	if m != nil {
+		return m.Until
+	}
+	return 0
+}
+
+func (m *SearchRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *SearchRequest) GetBefore() int64 {
+	if m != nil {
+		return m.Before
+	}
+	return 0
+}
+
+type SearchResults struct {
+	Messages             []*Message `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	Next                 int64      `protobuf:"varint,2,opt,name=next,proto3" json:"next,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *SearchResults) Reset()         { *m = SearchResults{} }
+func (m *SearchResults) String() string { return proto.CompactTextString(m) }
+func (*SearchResults) ProtoMessage()    {}
+func (*SearchResults) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{40}
+}
+
+func (m *SearchResults) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SearchResults.Unmarshal(m, b)
+}
+func (m *SearchResults) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SearchResults.Marshal(b, m, deterministic)
+}
+func (m *SearchResults) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchResults.Merge(m, src)
+}
+func (m *SearchResults) XXX_Size() int {
+	return xxx_messageInfo_SearchResults.Size(m)
+}
+func (m *SearchResults) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchResults.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchResults proto.InternalMessageInfo
+
+func (m *SearchResults) GetMessages() []*Message {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+func (m *SearchResults) GetNext() int64 {
+	if m != nil {
+		return m.Next
+	}
+	return 0
+}
+
+type MessageList struct {
+	Messages             []*Message       `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	Read                 map[string]int64 `protobuf:"bytes,2,rep,name=read,proto3" json:"read,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *MessageList) Reset()         { *m = MessageList{} }
+func (m *MessageList) String() string { return proto.CompactTextString(m) }
+func (*MessageList) ProtoMessage()    {}
+func (*MessageList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{41}
+}
+
+func (m *MessageList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MessageList.Unmarshal(m, b)
+}
+func (m *MessageList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MessageList.Marshal(b, m, deterministic)
+}
+func (m *MessageList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MessageList.Merge(m, src)
+}
+func (m *MessageList) XXX_Size() int {
+	return xxx_messageInfo_MessageList.Size(m)
+}
+func (m *MessageList) XXX_DiscardUnknown() {
+	xxx_messageInfo_MessageList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MessageList proto.InternalMessageInfo
+
+func (m *MessageList) GetMessages() []*Message {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+func (m *MessageList) GetRead() map[string]int64 {
+	if m != nil {
+		return m.Read
+	}
+	return nil
+}
+
+type UnreadCount struct {
+	Count                int32    `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UnreadCount) Reset()         { *m = UnreadCount{} }
+func (m *UnreadCount) String() string { return proto.CompactTextString(m) }
+func (*UnreadCount) ProtoMessage()    {}
+func (*UnreadCount) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{42}
+}
+
+func (m *UnreadCount) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnreadCount.Unmarshal(m, b)
+}
+func (m *UnreadCount) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnreadCount.Marshal(b, m, deterministic)
+}
+func (m *UnreadCount) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnreadCount.Merge(m, src)
+}
+func (m *UnreadCount) XXX_Size() int {
+	return xxx_messageInfo_UnreadCount.Size(m)
+}
+func (m *UnreadCount) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnreadCount.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnreadCount proto.InternalMessageInfo
+
+func (m *UnreadCount) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type Conversation struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Peer                 string   `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`
+	LastSeq              int64    `protobuf:"varint,3,opt,name=last_seq,json=lastSeq,proto3" json:"last_seq,omitempty"`
+	LastMessage          *Message `protobuf:"bytes,4,opt,name=last_message,json=lastMessage,proto3" json:"last_message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Conversation) Reset()         { *m = Conversation{} }
+func (m *Conversation) String() string { return proto.CompactTextString(m) }
+func (*Conversation) ProtoMessage()    {}
+func (*Conversation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{43}
+}
+
+func (m *Conversation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Conversation.Unmarshal(m, b)
+}
+func (m *Conversation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Conversation.Marshal(b, m, deterministic)
+}
+func (m *Conversation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Conversation.Merge(m, src)
+}
+func (m *Conversation) XXX_Size() int {
+	return xxx_messageInfo_Conversation.Size(m)
+}
+func (m *Conversation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Conversation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Conversation proto.InternalMessageInfo
+
+func (m *Conversation) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Conversation) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+func (m *Conversation) GetLastSeq() int64 {
+	if m != nil {
+		return m.LastSeq
+	}
+	return 0
+}
+
+func (m *Conversation) GetLastMessage() *Message {
+	if m != nil {
+		return m.LastMessage
+	}
+	return nil
+}
+
+type ConversationList struct {
+	Conversations        []*Conversation `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *ConversationList) Reset()         { *m = ConversationList{} }
+func (m *ConversationList) String() string { return proto.CompactTextString(m) }
+func (*ConversationList) ProtoMessage()    {}
+func (*ConversationList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{44}
+}
+
+func (m *ConversationList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConversationList.Unmarshal(m, b)
+}
+func (m *ConversationList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConversationList.Marshal(b, m, deterministic)
+}
+func (m *ConversationList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConversationList.Merge(m, src)
+}
+func (m *ConversationList) XXX_Size() int {
+	return xxx_messageInfo_ConversationList.Size(m)
+}
+func (m *ConversationList) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConversationList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConversationList proto.InternalMessageInfo
+
+func (m *ConversationList) GetConversations() []*Conversation {
+	if m != nil {
+		return m.Conversations
+	}
+	return nil
+}
+
+type Invitation struct {
+	Id                   string          `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Inviter              string          `protobuf:"bytes,2,opt,name=inviter,proto3" json:"inviter,omitempty"`
+	Invitee              string          `protobuf:"bytes,3,opt,name=invitee,proto3" json:"invitee,omitempty"`
+	Group                string          `protobuf:"bytes,4,opt,name=group,proto3" json:"group,omitempty"`
+	Created              int64           `protobuf:"varint,5,opt,name=created,proto3" json:"created,omitempty"`
+	Expires              int64           `protobuf:"varint,6,opt,name=expires,proto3" json:"expires,omitempty"`
+	State                InvitationState `protobuf:"varint,7,opt,name=state,proto3,enum=chat.InvitationState" json:"state,omitempty"`
+	Conversation         string          `protobuf:"bytes,8,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *Invitation) Reset()         { *m = Invitation{} }
+func (m *Invitation) String() string { return proto.CompactTextString(m) }
+func (*Invitation) ProtoMessage()    {}
+func (*Invitation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{45}
+}
+
+func (m *Invitation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Invitation.Unmarshal(m, b)
+}
+func (m *Invitation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Invitation.Marshal(b, m, deterministic)
+}
+func (m *Invitation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Invitation.Merge(m, src)
+}
+func (m *Invitation) XXX_Size() int {
+	return xxx_messageInfo_Invitation.Size(m)
+}
+func (m *Invitation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Invitation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Invitation proto.InternalMessageInfo
+
+func (m *Invitation) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Invitation) GetInviter() string {
+	if m != nil {
+		return m.Inviter
+	}
+	return ""
+}
+
+func (m *Invitation) GetInvitee() string {
+	if m != nil {
+		return m.Invitee
+	}
+	return ""
+}
+
+func (m *Invitation) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *Invitation) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *Invitation) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+func (m *Invitation) GetState() InvitationState {
+	if m != nil {
+		return m.State
+	}
+	return InvitationState_PENDING
+}
+
+func (m *Invitation) GetConversation() string {
+	if m != nil {
+		return m.Conversation
+	}
+	return ""
+}
+
+type InvitationList struct {
+	Invitations          []*Invitation `protobuf:"bytes,1,rep,name=invitations,proto3" json:"invitations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *InvitationList) Reset()         { *m = InvitationList{} }
+func (m *InvitationList) String() string { return proto.CompactTextString(m) }
+func (*InvitationList) ProtoMessage()    {}
+func (*InvitationList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{46}
+}
+
+func (m *InvitationList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InvitationList.Unmarshal(m, b)
+}
+func (m *InvitationList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InvitationList.Marshal(b, m, deterministic)
+}
+func (m *InvitationList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InvitationList.Merge(m, src)
+}
+func (m *InvitationList) XXX_Size() int {
+	return xxx_messageInfo_InvitationList.Size(m)
+}
+func (m *InvitationList) XXX_DiscardUnknown() {
+	xxx_messageInfo_InvitationList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InvitationList proto.InternalMessageInfo
+
+func (m *InvitationList) GetInvitations() []*Invitation {
+	if m != nil {
+		return m.Invitations
+	}
+	return nil
+}
+
+type InvitationResponse struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Accept               bool     `protobuf:"varint,2,opt,name=accept,proto3" json:"accept,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InvitationResponse) Reset()         { *m = InvitationResponse{} }
+func (m *InvitationResponse) String() string { return proto.CompactTextString(m) }
+func (*InvitationResponse) ProtoMessage()    {}
+func (*InvitationResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{47}
+}
+
+func (m *InvitationResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InvitationResponse.Unmarshal(m, b)
+}
+func (m *InvitationResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InvitationResponse.Marshal(b, m, deterministic)
+}
+func (m *InvitationResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InvitationResponse.Merge(m, src)
+}
+func (m *InvitationResponse) XXX_Size() int {
+	return xxx_messageInfo_InvitationResponse.Size(m)
+}
+func (m *InvitationResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_InvitationResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InvitationResponse proto.InternalMessageInfo
+
+func (m *InvitationResponse) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *InvitationResponse) GetAccept() bool {
+	if m != nil {
+		return m.Accept
+	}
+	return false
+}
+
+type OverflowStats struct {
+	Policy               string   `protobuf:"bytes,1,opt,name=policy,proto3" json:"policy,omitempty"`
+	Dropped              int64    `protobuf:"varint,2,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *OverflowStats) Reset()         { *m = OverflowStats{} }
+func (m *OverflowStats) String() string { return proto.CompactTextString(m) }
+func (*OverflowStats) ProtoMessage()    {}
+func (*OverflowStats) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{48}
+}
+
+func (m *OverflowStats) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OverflowStats.Unmarshal(m, b)
+}
+func (m *OverflowStats) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OverflowStats.Marshal(b, m, deterministic)
+}
+func (m *OverflowStats) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OverflowStats.Merge(m, src)
+}
+func (m *OverflowStats) XXX_Size() int {
+	return xxx_messageInfo_OverflowStats.Size(m)
+}
+func (m *OverflowStats) XXX_DiscardUnknown() {
+	xxx_messageInfo_OverflowStats.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OverflowStats proto.InternalMessageInfo
+
+func (m *OverflowStats) GetPolicy() string {
+	if m != nil {
+		return m.Policy
+	}
+	return ""
+}
+
+func (m *OverflowStats) GetDropped() int64 {
+	if m != nil {
+		return m.Dropped
+	}
+	return 0
+}
+
+type ModerationRequest struct {
+	Client               string   `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
+	Group                string   `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	Member               string   `protobuf:"bytes,3,opt,name=member,proto3" json:"member,omitempty"`
+	Duration             int64    `protobuf:"varint,4,opt,name=duration,proto3" json:"duration,omitempty"`
+	Lift                 bool     `protobuf:"varint,5,opt,name=lift,proto3" json:"lift,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ModerationRequest) Reset()         { *m = ModerationRequest{} }
+func (m *ModerationRequest) String() string { return proto.CompactTextString(m) }
+func (*ModerationRequest) ProtoMessage()    {}
+func (*ModerationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{49}
+}
+
+func (m *ModerationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ModerationRequest.Unmarshal(m, b)
+}
+func (m *ModerationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ModerationRequest.Marshal(b, m, deterministic)
+}
+func (m *ModerationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ModerationRequest.Merge(m, src)
+}
+func (m *ModerationRequest) XXX_Size() int {
+	return xxx_messageInfo_ModerationRequest.Size(m)
+}
+func (m *ModerationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ModerationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ModerationRequest proto.InternalMessageInfo
+
+func (m *ModerationRequest) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *ModerationRequest) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *ModerationRequest) GetMember() string {
+	if m != nil {
+		return m.Member
+	}
+	return ""
+}
+
+func (m *ModerationRequest) GetDuration() int64 {
+	if m != nil {
+		return m.Duration
+	}
+	return 0
+}
+
+func (m *ModerationRequest) GetLift() bool {
+	if m != nil {
+		return m.Lift
+	}
+	return false
+}
+
+type Presence struct {
+	Name                 string        `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	State                PresenceState `protobuf:"varint,2,opt,name=state,proto3,enum=chat.PresenceState" json:"state,omitempty"`
+	Status               string        `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Since                int64         `protobuf:"varint,4,opt,name=since,proto3" json:"since,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *Presence) Reset()         { *m = Presence{} }
+func (m *Presence) String() string { return proto.CompactTextString(m) }
+func (*Presence) ProtoMessage()    {}
+func (*Presence) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{50}
+}
+
+func (m *Presence) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Presence.Unmarshal(m, b)
+}
+func (m *Presence) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Presence.Marshal(b, m, deterministic)
+}
+func (m *Presence) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Presence.Merge(m, src)
+}
+func (m *Presence) XXX_Size() int {
+	return xxx_messageInfo_Presence.Size(m)
+}
+func (m *Presence) XXX_DiscardUnknown() {
+	xxx_messageInfo_Presence.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Presence proto.InternalMessageInfo
+
+func (m *Presence) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Presence) GetState() PresenceState {
+	if m != nil {
+		return m.State
+	}
+	return PresenceState_OFFLINE
+}
+
+func (m *Presence) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Presence) GetSince() int64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+type PresenceList struct {
+	Presences            []*Presence `protobuf:"bytes,1,rep,name=presences,proto3" json:"presences,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *PresenceList) Reset()         { *m = PresenceList{} }
+func (m *PresenceList) String() string { return proto.CompactTextString(m) }
+func (*PresenceList) ProtoMessage()    {}
+func (*PresenceList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15d776bce20e22fd, []int{51}
+}
+
+func (m *PresenceList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PresenceList.Unmarshal(m, b)
+}
+func (m *PresenceList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PresenceList.Marshal(b, m, deterministic)
+}
+func (m *PresenceList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PresenceList.Merge(m, src)
+}
+func (m *PresenceList) XXX_Size() int {
+	return xxx_messageInfo_PresenceList.Size(m)
+}
+func (m *PresenceList) XXX_DiscardUnknown() {
+	xxx_messageInfo_PresenceList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PresenceList proto.InternalMessageInfo
+
+func (m *PresenceList) GetPresences() []*Presence {
+	if m != nil {
+		return m.Presences
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("chat.Visibility", Visibility_name, Visibility_value)
+	proto.RegisterEnum("chat.InvitationState", InvitationState_name, InvitationState_value)
+	proto.RegisterEnum("chat.Role", Role_name, Role_value)
+	proto.RegisterEnum("chat.PresenceState", PresenceState_name, PresenceState_value)
+	proto.RegisterType((*Message)(nil), "chat.Message")
+	proto.RegisterType((*Typing)(nil), "chat.Typing")
+	proto.RegisterType((*Join)(nil), "chat.Join")
+	proto.RegisterType((*Leave)(nil), "chat.Leave")
+	proto.RegisterType((*Notice)(nil), "chat.Notice")
+	proto.RegisterType((*Error)(nil), "chat.Error")
+	proto.RegisterType((*Subscribe)(nil), "chat.Subscribe")
+	proto.RegisterType((*Unsubscribe)(nil), "chat.Unsubscribe")
+	proto.RegisterType((*Quote)(nil), "chat.Quote")
+	proto.RegisterType((*Reaction)(nil), "chat.Reaction")
+	proto.RegisterType((*ReactionRequest)(nil), "chat.ReactionRequest")
+	proto.RegisterType((*ReactionChange)(nil), "chat.ReactionChange")
+	proto.RegisterType((*Mention)(nil), "chat.Mention")
+	proto.RegisterType((*Edit)(nil), "chat.Edit")
+	proto.RegisterType((*Delete)(nil), "chat.Delete")
+	proto.RegisterType((*Ack)(nil), "chat.Ack")
+	proto.RegisterType((*ReadReceipt)(nil), "chat.ReadReceipt")
+	proto.RegisterType((*ClientLoginRequest)(nil), "chat.ClientLoginRequest")
+	proto.RegisterType((*ClientLoginResponse)(nil), "chat.ClientLoginResponse")
+	proto.RegisterType((*PasswordChange)(nil), "chat.PasswordChange")
+	proto.RegisterType((*AccountState)(nil), "chat.AccountState")
+	proto.RegisterType((*ClientLogoutRequest)(nil), "chat.ClientLogoutRequest")
+	proto.RegisterType((*ClientLogoutResponse)(nil), "chat.ClientLogoutResponse")
+	proto.RegisterType((*Login)(nil), "chat.Login")
+	proto.RegisterType((*Logout)(nil), "chat.Logout")
+	proto.RegisterType((*ChatClient)(nil), "chat.ChatClient")
+	proto.RegisterType((*ChatGroup)(nil), "chat.ChatGroup")
+	proto.RegisterType((*ChatGroupList)(nil), "chat.ChatGroupList")
+	proto.RegisterType((*ClientInfo)(nil), "chat.ClientInfo")
+	proto.RegisterType((*ChatClientList)(nil), "chat.ChatClientList")
+	proto.RegisterType((*Empty)(nil), "chat.Empty")
+	proto.RegisterType((*HistoryRequest)(nil), "chat.HistoryRequest")
+	proto.RegisterType((*Attachment)(nil), "chat.Attachment")
+	proto.RegisterType((*AttachmentChunk)(nil), "chat.AttachmentChunk")
+	proto.RegisterType((*AttachmentRequest)(nil), "chat.AttachmentRequest")
+	proto.RegisterType((*PublicKey)(nil), "chat.PublicKey")
+	proto.RegisterType((*KeyList)(nil), "chat.KeyList")
+	proto.RegisterType((*Sealed)(nil), "chat.Sealed")
+	proto.RegisterType((*SealedCopy)(nil), "chat.SealedCopy")
+	proto.RegisterType((*SearchRequest)(nil), "chat.SearchRequest")
+	proto.RegisterType((*SearchResults)(nil), "chat.SearchResults")
+	proto.RegisterType((*MessageList)(nil), "chat.MessageList")
+	proto.RegisterMapType((map[string]int64)(nil), "chat.MessageList.ReadEntry")
+	proto.RegisterType((*UnreadCount)(nil), "chat.UnreadCount")
+	proto.RegisterType((*Conversation)(nil), "chat.Conversation")
+	proto.RegisterType((*ConversationList)(nil), "chat.ConversationList")
+	proto.RegisterType((*Invitation)(nil), "chat.Invitation")
+	proto.RegisterType((*InvitationList)(nil), "chat.InvitationList")
+	proto.RegisterType((*InvitationResponse)(nil), "chat.InvitationResponse")
+	proto.RegisterType((*OverflowStats)(nil), "chat.OverflowStats")
+	proto.RegisterType((*ModerationRequest)(nil), "chat.ModerationRequest")
+	proto.RegisterType((*Presence)(nil), "chat.Presence")
+	proto.RegisterType((*PresenceList)(nil), "chat.PresenceList")
+}
+
+func init() { proto.RegisterFile("grpchat.proto", fileDescriptor_15d776bce20e22fd) }
+
+var fileDescriptor_15d776bce20e22fd = []byte{
+	// 2693 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x19, 0xdb, 0x76, 0xdb, 0xc6,
+	0x91, 0x20, 0x78, 0x1d, 0x5e, 0x04, 0xad, 0x65, 0x05, 0x61, 0x6e, 0xca, 0x26, 0x4d, 0x14, 0xc7,
+	0x89, 0x1d, 0x25, 0x4e, 0x7c, 0x5c, 0x37, 0x39, 0xb2, 0xc4, 0x48, 0xb2, 0x75, 0xcb, 0x4a, 0x8a,
+	0x9b, 0x87, 0x1e, 0x15, 0x02, 0x57, 0x22, 0x22, 0x12, 0xa0, 0x81, 0xa5, 0x14, 0xf6, 0xa1, 0x7d,
+	0xe8, 0x53, 0x3f, 0xa3, 0x7d, 0xec, 0x39, 0xed, 0x8f, 0xf5, 0x27, 0x7a, 0xf6, 0x86, 0x1b, 0xa1,
+	0xd8, 0xce, 0x1b, 0xe6, 0xba, 0xb3, 0x33, 0xb3, 0xb3, 0x33, 0x0b, 0xe8, 0x5c, 0x84, 0x13, 0x77,
+	0xe8, 0xb0, 0xcf, 0x27, 0x61, 0xc0, 0x02, 0x54, 0xe1, 0xdf, 0xf8, 0xef, 0x0d, 0xa8, 0xef, 0xd1,
+	0x28, 0x72, 0x2e, 0x28, 0x42, 0x50, 0x39, 0x0b, 0x06, 0x33, 0xdb, 0x58, 0x31, 0x56, 0x9b, 0x44,
+	0x7c, 0xa3, 0x65, 0xa8, 0x45, 0xd4, 0x1f, 0xd0, 0xd0, 0x2e, 0x0b, 0xac, 0x82, 0x50, 0x0f, 0x1a,
+	0x21, 0x75, 0xa9, 0x77, 0x45, 0x43, 0xdb, 0x14, 0x94, 0x18, 0x46, 0x5d, 0x28, 0x7b, 0x03, 0xbb,
+	0x22, 0xb0, 0x65, 0x6f, 0x80, 0xde, 0x86, 0x26, 0xf3, 0xc6, 0x34, 0x62, 0xce, 0x78, 0x62, 0x57,
+	0x57, 0x8c, 0x55, 0x93, 0x24, 0x08, 0x64, 0x81, 0x19, 0xd1, 0x17, 0x76, 0x4d, 0xe0, 0xf9, 0x27,
+	0xc2, 0xd0, 0x76, 0x03, 0xff, 0x8a, 0x86, 0x91, 0xc3, 0xbc, 0xc0, 0xb7, 0x1b, 0x42, 0x53, 0x06,
+	0xc7, 0xed, 0xa2, 0x03, 0x8f, 0xd1, 0x81, 0x8d, 0x84, 0xa0, 0x82, 0x90, 0x0d, 0xf5, 0x01, 0x1d,
+	0x51, 0x4e, 0xb8, 0xb5, 0x62, 0xac, 0x36, 0x88, 0x06, 0xd1, 0x9b, 0xdc, 0xe2, 0xc9, 0x68, 0x76,
+	0xca, 0x02, 0x7b, 0x59, 0x68, 0xac, 0x0b, 0xf8, 0x38, 0xe0, 0xca, 0xd8, 0x30, 0xa4, 0xce, 0xc0,
+	0x7e, 0x43, 0x6e, 0x52, 0x42, 0xe8, 0x7d, 0xa8, 0xbe, 0x98, 0x06, 0x8c, 0xda, 0xf6, 0x8a, 0xb1,
+	0xda, 0x5a, 0x6b, 0x7d, 0x2e, 0xdc, 0xf7, 0x03, 0x47, 0x11, 0x49, 0x41, 0x77, 0xa1, 0x19, 0x52,
+	0xc7, 0xe5, 0x36, 0x45, 0xf6, 0x9b, 0x2b, 0xe6, 0x6a, 0x6b, 0xad, 0x2b, 0xd9, 0x88, 0x42, 0x93,
+	0x84, 0x81, 0x7b, 0x6d, 0x4c, 0x7d, 0xc9, 0xfc, 0xd6, 0x8a, 0xc9, 0xbd, 0xa6, 0x61, 0x74, 0x1f,
+	0xc0, 0x61, 0xcc, 0x71, 0x87, 0x1c, 0x63, 0xbf, 0x23, 0x56, 0xb4, 0xa4, 0xaa, 0xf5, 0x18, 0x4f,
+	0x52, 0x3c, 0xe8, 0x43, 0x1e, 0x1b, 0x67, 0x44, 0x07, 0xf6, 0xbb, 0x82, 0xbb, 0x2d, 0xb9, 0x8f,
+	0x04, 0x8e, 0x28, 0x1a, 0xfa, 0x08, 0x6a, 0x6c, 0x36, 0xf1, 0xfc, 0x0b, 0xbb, 0x99, 0xe6, 0x3a,
+	0x16, 0xb8, 0xed, 0x12, 0x51, 0x54, 0xf4, 0x31, 0x54, 0x84, 0x0b, 0x40, 0x70, 0x2d, 0xc6, 0x9b,
+	0x18, 0x10, 0x1e, 0xd7, 0x09, 0xdb, 0x2e, 0x11, 0xc1, 0x80, 0x56, 0xa0, 0xf2, 0x73, 0xe0, 0xf9,
+	0x76, 0x4b, 0x30, 0x82, 0x64, 0x7c, 0x1a, 0x78, 0x3e, 0xe7, 0xe0, 0x14, 0xf4, 0x01, 0x54, 0x47,
+	0xd4, 0xb9, 0xa2, 0x76, 0x3b, 0xed, 0xb7, 0x5d, 0x8e, 0xda, 0x2e, 0x11, 0x49, 0xe3, 0x76, 0xf9,
+	0x01, 0xf3, 0x5c, 0x6a, 0x77, 0xd2, 0x76, 0xed, 0x0b, 0x1c, 0xb7, 0x4b, 0x52, 0xb9, 0x32, 0x1a,
+	0x86, 0x41, 0x68, 0x77, 0xd3, 0xca, 0xfa, 0x1c, 0xc5, 0x95, 0x09, 0x1a, 0x7a, 0x07, 0x4c, 0xc7,
+	0xbd, 0xb4, 0x17, 0x04, 0x4b, 0x53, 0x79, 0xcd, 0xbd, 0xdc, 0x2e, 0x11, 0x8e, 0x47, 0xf7, 0xa0,
+	0x19, 0x4d, 0xcf, 0x22, 0x37, 0xf4, 0xce, 0xa8, 0x6d, 0x09, 0xa6, 0x05, 0xe5, 0x2c, 0x8d, 0xde,
+	0x2e, 0x91, 0x84, 0x07, 0x3d, 0x80, 0xd6, 0xd4, 0x4f, 0x44, 0x16, 0xd3, 0x3e, 0x39, 0x49, 0x08,
+	0xdb, 0x25, 0x92, 0xe6, 0xe3, 0xae, 0xe1, 0x79, 0x68, 0x2f, 0xa5, 0x5d, 0xd3, 0x1f, 0x78, 0xc2,
+	0x79, 0x9c, 0xc2, 0x77, 0x2d, 0x13, 0xd2, 0xbe, 0x9d, 0xde, 0xf5, 0xa6, 0xc0, 0xf1, 0x5d, 0x4b,
+	0x2a, 0x5a, 0x83, 0x86, 0x4e, 0x1b, 0xbb, 0x27, 0x38, 0x97, 0xb2, 0x69, 0xb5, 0x31, 0x74, 0xfc,
+	0x0b, 0x2e, 0x11, 0xf3, 0xa1, 0x4f, 0xa0, 0xae, 0xb2, 0xc9, 0x7e, 0x5b, 0x88, 0x74, 0xa4, 0xc8,
+	0x9e, 0x44, 0x6e, 0x97, 0x88, 0xa6, 0x3f, 0xa9, 0x43, 0x95, 0x5e, 0x51, 0x9f, 0x3d, 0xad, 0x34,
+	0xea, 0x56, 0x03, 0x63, 0xa8, 0xc9, 0x7c, 0xe0, 0xe7, 0x27, 0x62, 0x4e, 0xc8, 0xcf, 0x8f, 0x21,
+	0xcf, 0x8f, 0x02, 0x71, 0x1b, 0x2a, 0x3c, 0xc8, 0x4f, 0x2b, 0x0d, 0xc3, 0x2a, 0xe3, 0xf7, 0xa1,
+	0x2a, 0xe2, 0xc9, 0x05, 0x42, 0x3a, 0x0e, 0xae, 0x12, 0x01, 0x05, 0xe2, 0xb7, 0xa1, 0x26, 0x83,
+	0xc9, 0x0b, 0x0b, 0xa3, 0xbf, 0x30, 0x5d, 0x58, 0xf8, 0x37, 0x7e, 0x00, 0x55, 0x11, 0x43, 0x4e,
+	0x74, 0x83, 0x01, 0x15, 0xc4, 0x2a, 0x11, 0xdf, 0x5c, 0xe9, 0x58, 0x16, 0x25, 0x55, 0x76, 0x34,
+	0x88, 0xef, 0x42, 0x33, 0x0e, 0x19, 0x7a, 0x0f, 0x5a, 0x21, 0x8d, 0xa6, 0x63, 0x7a, 0x7a, 0x1e,
+	0x06, 0x63, 0xa1, 0xc1, 0x24, 0x20, 0x51, 0xdf, 0x87, 0xc1, 0x18, 0x77, 0xa0, 0x95, 0x8a, 0x16,
+	0xfe, 0x12, 0xaa, 0xe2, 0xf0, 0xa6, 0xaa, 0x9a, 0x91, 0xa9, 0x6a, 0xba, 0x02, 0x96, 0x93, 0x0a,
+	0x88, 0x1f, 0x41, 0x43, 0xfb, 0x1c, 0x2d, 0x41, 0x95, 0x8e, 0x83, 0x9f, 0x3d, 0x25, 0x26, 0x01,
+	0xe1, 0x33, 0x21, 0x1f, 0xd9, 0x65, 0x71, 0xa8, 0x35, 0x88, 0xff, 0x61, 0xc0, 0x82, 0x16, 0x26,
+	0xf4, 0xc5, 0x94, 0x46, 0xec, 0xc6, 0xb5, 0x97, 0xa0, 0x7a, 0x11, 0x06, 0xd3, 0x89, 0x5a, 0x5c,
+	0x02, 0x73, 0xb5, 0xd0, 0x2c, 0xa8, 0x85, 0xf9, 0x7a, 0x1b, 0x5b, 0x59, 0x4d, 0x59, 0x89, 0x7f,
+	0x81, 0x6e, 0x36, 0x77, 0x94, 0x9c, 0x11, 0xcb, 0xa9, 0x4a, 0x5c, 0x4e, 0x2a, 0x71, 0xac, 0xc9,
+	0xcc, 0xed, 0x57, 0x87, 0xbc, 0x92, 0x09, 0x39, 0xe7, 0x77, 0x83, 0xa9, 0xcf, 0xc4, 0xca, 0x55,
+	0x22, 0x01, 0xfc, 0x1d, 0xbf, 0x62, 0xfc, 0x94, 0xa9, 0xbf, 0xb6, 0xa4, 0x0e, 0x81, 0x99, 0x0a,
+	0xc1, 0x63, 0xa8, 0xf0, 0x43, 0xf4, 0x1b, 0xa5, 0xef, 0x40, 0x4d, 0x1e, 0xaf, 0x97, 0xcb, 0xe3,
+	0x3e, 0x98, 0xeb, 0xee, 0xe5, 0x2b, 0x2c, 0x94, 0xb9, 0xd3, 0xcc, 0xdc, 0x9d, 0x86, 0xdf, 0x83,
+	0x56, 0xaa, 0x72, 0x6a, 0x71, 0x23, 0x59, 0xe7, 0xcf, 0x80, 0x36, 0x46, 0x1e, 0xf5, 0xd9, 0x6e,
+	0x70, 0xe1, 0xc5, 0xa9, 0xd1, 0x83, 0xc6, 0xc4, 0x89, 0xa2, 0xeb, 0x20, 0xd4, 0x8b, 0xc7, 0x30,
+	0xdf, 0x99, 0xef, 0x8c, 0xf5, 0x79, 0x10, 0xdf, 0x82, 0x3f, 0xa4, 0x57, 0x5e, 0x30, 0x8d, 0xf4,
+	0x25, 0xac, 0x61, 0xfc, 0x13, 0xdc, 0xca, 0xac, 0x10, 0x4d, 0x02, 0x3f, 0xa2, 0x3c, 0x42, 0x2c,
+	0xb8, 0xa4, 0xbe, 0xce, 0x60, 0x01, 0x14, 0x2a, 0xb7, 0xa1, 0x4e, 0x7f, 0x99, 0x78, 0x21, 0x8d,
+	0xd4, 0xfe, 0x34, 0x88, 0x7f, 0x84, 0xee, 0xa1, 0x32, 0x4b, 0x65, 0xd2, 0xfb, 0xd0, 0x0e, 0x46,
+	0x83, 0xd3, 0x9c, 0xf1, 0xad, 0x60, 0x34, 0xd0, 0x8c, 0x9c, 0xc5, 0xa7, 0xd7, 0x09, 0x8b, 0x5c,
+	0xaa, 0xe5, 0xd3, 0x6b, 0xcd, 0x82, 0xbf, 0x85, 0xf6, 0xba, 0x2b, 0x52, 0xe6, 0x88, 0x39, 0x8c,
+	0xc6, 0x56, 0x19, 0xd9, 0x2d, 0x0f, 0xbc, 0xc8, 0x39, 0x1b, 0x51, 0xa9, 0xa2, 0x41, 0x62, 0x18,
+	0xff, 0x21, 0xb5, 0xe5, 0x60, 0xca, 0xb4, 0x57, 0x8b, 0xb7, 0x6c, 0x81, 0xe9, 0x8c, 0x46, 0x4a,
+	0x07, 0xff, 0xc4, 0xcb, 0xb0, 0x94, 0x15, 0x97, 0x2e, 0xc3, 0x6f, 0x41, 0x55, 0xf8, 0xb0, 0xc8,
+	0x1e, 0x5e, 0xe4, 0x24, 0x7b, 0x21, 0xf5, 0x43, 0x80, 0x8d, 0xa1, 0xc3, 0xa4, 0xda, 0x9b, 0x4e,
+	0x3e, 0xfe, 0xaf, 0x01, 0x4d, 0xce, 0xb6, 0x25, 0x4e, 0xfc, 0x32, 0xd4, 0x5c, 0xc1, 0xaf, 0xb9,
+	0x24, 0x54, 0x18, 0xa3, 0x8f, 0x61, 0x21, 0xb8, 0xa2, 0xe1, 0xf9, 0x28, 0xb8, 0x3e, 0x9d, 0x04,
+	0x23, 0xcf, 0xd5, 0x99, 0xdf, 0xd5, 0xe8, 0x43, 0x81, 0xe5, 0xcd, 0xc5, 0x95, 0x17, 0x79, 0x67,
+	0xde, 0xc8, 0x63, 0x33, 0x71, 0x6a, 0xbb, 0xba, 0xb9, 0xf8, 0x31, 0xc6, 0x93, 0x14, 0x4f, 0x26,
+	0x17, 0xab, 0xd9, 0x5c, 0xc4, 0x1f, 0x43, 0x27, 0xb6, 0x77, 0xd7, 0x93, 0x35, 0x4d, 0x94, 0xab,
+	0xc8, 0x36, 0x44, 0x01, 0x54, 0x10, 0xfe, 0x2b, 0x80, 0xdc, 0xfb, 0x8e, 0x7f, 0x1e, 0x14, 0xc6,
+	0x53, 0xf4, 0x6b, 0x57, 0x9e, 0x4b, 0x23, 0xb1, 0xb1, 0x2a, 0xd1, 0x20, 0xd7, 0x19, 0xf8, 0x23,
+	0xcf, 0xa7, 0x62, 0x4b, 0x55, 0xa2, 0x20, 0x74, 0x47, 0x24, 0x7d, 0x44, 0x7d, 0x97, 0x8a, 0x8d,
+	0xc4, 0x0d, 0xd7, 0xa1, 0xc2, 0x92, 0x98, 0x8e, 0x09, 0x74, 0x13, 0xff, 0x0b, 0x4b, 0x6d, 0xa8,
+	0x4b, 0x7f, 0x6a, 0x53, 0x35, 0x88, 0x3e, 0x82, 0xaa, 0xe7, 0x9f, 0x07, 0xb2, 0x86, 0xc7, 0xad,
+	0x57, 0x62, 0x3e, 0x91, 0x64, 0x5c, 0x87, 0x6a, 0x7f, 0x3c, 0x61, 0x33, 0xfc, 0x4f, 0x03, 0xba,
+	0xdb, 0x5e, 0xc4, 0x82, 0x70, 0xa6, 0x53, 0xed, 0x77, 0xba, 0x86, 0x1b, 0xe9, 0x1e, 0x23, 0xf6,
+	0x95, 0x2e, 0xea, 0xcb, 0x50, 0x3b, 0xa3, 0xe7, 0x41, 0x48, 0x55, 0x45, 0x51, 0x10, 0xcf, 0xd4,
+	0x91, 0x37, 0xf6, 0x98, 0xda, 0xb1, 0x04, 0xe6, 0xae, 0x80, 0x4a, 0x71, 0x3b, 0xac, 0x3a, 0xd8,
+	0x6a, 0xba, 0x83, 0xc5, 0x9b, 0x00, 0x49, 0xf3, 0x38, 0x57, 0xd6, 0x8a, 0x52, 0x0a, 0x41, 0x25,
+	0xf2, 0xfe, 0x42, 0xd5, 0x99, 0x17, 0xdf, 0xf8, 0x07, 0x58, 0x48, 0xb4, 0x6c, 0x0c, 0xa7, 0xfe,
+	0x25, 0x67, 0x1b, 0x38, 0xcc, 0x11, 0xca, 0xda, 0x44, 0x7c, 0x8b, 0xfc, 0x1e, 0x3a, 0x6b, 0x0f,
+	0xbe, 0x8e, 0x67, 0x05, 0x01, 0x15, 0xaa, 0xbc, 0x80, 0xc5, 0x44, 0x65, 0xea, 0xa4, 0x26, 0xee,
+	0xbb, 0xf1, 0x0a, 0x2c, 0x17, 0xec, 0x3f, 0xd5, 0x30, 0x98, 0xd9, 0x86, 0xe1, 0x0b, 0x68, 0x1e,
+	0x4e, 0xcf, 0x46, 0x9e, 0xfb, 0x8c, 0xce, 0x0a, 0x33, 0xd0, 0x02, 0xf3, 0x92, 0xca, 0x2b, 0xbf,
+	0x4d, 0xf8, 0x27, 0xfe, 0x02, 0xea, 0xcf, 0xe8, 0x4c, 0xa4, 0x4b, 0x91, 0x00, 0x82, 0xca, 0x25,
+	0x9d, 0xc9, 0x3c, 0x69, 0x13, 0xf1, 0x8d, 0xff, 0x04, 0x35, 0xd9, 0x76, 0xa3, 0x77, 0x00, 0xe4,
+	0xb1, 0x3e, 0xe5, 0x5a, 0xa5, 0x7b, 0x9a, 0x12, 0xc3, 0x2d, 0x58, 0x85, 0x9a, 0x1b, 0x4c, 0x3c,
+	0x1a, 0xd9, 0x95, 0x74, 0x9a, 0x49, 0xe1, 0x8d, 0x60, 0x32, 0x23, 0x8a, 0xfe, 0xb4, 0xd2, 0x28,
+	0x5b, 0xe6, 0xd3, 0x4a, 0xc3, 0xb4, 0x2a, 0xf8, 0x5b, 0x80, 0x84, 0x43, 0x5b, 0x6c, 0xc4, 0x16,
+	0xa3, 0x77, 0x01, 0x5c, 0x6f, 0x32, 0xa4, 0xa1, 0x68, 0xb3, 0xe4, 0x56, 0x52, 0x18, 0xfc, 0x1f,
+	0x03, 0x3a, 0x47, 0xd4, 0x09, 0xdd, 0x61, 0xca, 0xd5, 0x2f, 0xa6, 0x34, 0xd4, 0xc3, 0x9e, 0x04,
+	0x6e, 0xe8, 0x41, 0x92, 0xba, 0x65, 0xe6, 0x3b, 0x96, 0xc8, 0xd3, 0xc7, 0xd0, 0x24, 0x12, 0xe0,
+	0xd8, 0xa9, 0xcf, 0xbc, 0x91, 0x9a, 0xf4, 0x24, 0x90, 0xa4, 0x76, 0x2d, 0x9d, 0xda, 0xc9, 0x41,
+	0xa8, 0xa7, 0x0f, 0x02, 0xde, 0x4f, 0xcc, 0x8d, 0xa6, 0x23, 0x16, 0xa1, 0x4f, 0xa0, 0xa1, 0x02,
+	0x2a, 0xcf, 0x6d, 0xaa, 0xb7, 0x15, 0x58, 0x12, 0x93, 0x45, 0xc8, 0xb4, 0x17, 0x4c, 0x22, 0xbe,
+	0xf1, 0xbf, 0x0c, 0x68, 0x29, 0x4e, 0x11, 0xd6, 0xd7, 0x50, 0x77, 0x4f, 0x8d, 0x45, 0xb2, 0x2a,
+	0xbc, 0x95, 0x61, 0xe3, 0xba, 0xc4, 0x88, 0xd4, 0xf7, 0x59, 0x38, 0x93, 0xe3, 0x51, 0xef, 0x1b,
+	0x68, 0xc6, 0xa8, 0x74, 0xa8, 0x9a, 0x32, 0x54, 0x4b, 0x50, 0xbd, 0x72, 0x46, 0x53, 0x7d, 0xf4,
+	0x25, 0xf0, 0xa8, 0xfc, 0xd0, 0xc0, 0x1f, 0xf0, 0x66, 0x95, 0xab, 0xd8, 0xe0, 0x57, 0x60, 0xd2,
+	0x4b, 0x19, 0xe9, 0x5e, 0xea, 0x6f, 0xd0, 0xde, 0x98, 0xef, 0xfd, 0x32, 0x47, 0x7a, 0x42, 0xe3,
+	0x69, 0x5d, 0x7c, 0xf3, 0xc9, 0x77, 0xe4, 0x44, 0xec, 0x94, 0xf7, 0x20, 0xea, 0x2a, 0xe7, 0xf0,
+	0x11, 0x7d, 0x81, 0xee, 0x43, 0x5b, 0x90, 0xf4, 0xe1, 0xa9, 0x64, 0xe7, 0x06, 0xe9, 0x8c, 0x16,
+	0x67, 0x51, 0x00, 0xde, 0x05, 0x2b, 0x6d, 0x80, 0x70, 0xe7, 0x43, 0xe8, 0xa4, 0x4f, 0xa3, 0xf6,
+	0x29, 0x52, 0xe5, 0x2f, 0x45, 0x22, 0x59, 0x46, 0xfc, 0x3f, 0x03, 0x60, 0xc7, 0xbf, 0xf2, 0x58,
+	0xf1, 0x6e, 0x6c, 0xa8, 0x7b, 0x9c, 0x1a, 0x6f, 0x48, 0x83, 0x09, 0x25, 0x3e, 0xf0, 0x0a, 0x4c,
+	0x72, 0xb8, 0x92, 0xce, 0x61, 0x5e, 0xf7, 0x43, 0xea, 0xf0, 0xb9, 0x46, 0xe6, 0xa5, 0x06, 0xd3,
+	0x7d, 0x4e, 0x2d, 0xd3, 0xe7, 0xa0, 0x4f, 0xa1, 0x1a, 0x31, 0x87, 0xc9, 0xe4, 0xec, 0xae, 0xdd,
+	0x96, 0xdb, 0x49, 0xcc, 0x15, 0x5d, 0x0a, 0x91, 0x3c, 0xaf, 0xf2, 0x68, 0x81, 0x37, 0xa1, 0x9b,
+	0x48, 0x0b, 0xcf, 0xad, 0x41, 0xcb, 0x8b, 0x31, 0xda, 0x6f, 0x56, 0x7e, 0x21, 0x92, 0x66, 0xc2,
+	0x8f, 0x01, 0xa5, 0x48, 0xba, 0xb1, 0xcb, 0xbb, 0x6e, 0x19, 0x6a, 0x8e, 0xeb, 0xd2, 0x09, 0x53,
+	0x2d, 0x8e, 0x82, 0xf0, 0x3a, 0x74, 0x0e, 0x54, 0x6f, 0xc0, 0xed, 0x17, 0xf7, 0xac, 0x6a, 0x1d,
+	0x54, 0xbf, 0x21, 0x21, 0x71, 0x33, 0x87, 0xc1, 0x64, 0xa2, 0x1a, 0x2d, 0x93, 0x68, 0x90, 0x4f,
+	0x35, 0x8b, 0x7b, 0xc1, 0x80, 0x86, 0x4e, 0x6e, 0xae, 0x29, 0xec, 0x5b, 0x6e, 0xac, 0x29, 0x63,
+	0x3a, 0x3e, 0x4b, 0x6a, 0x8a, 0x84, 0x44, 0x7f, 0x37, 0x0d, 0x93, 0x8b, 0xce, 0x24, 0x31, 0xcc,
+	0x73, 0x7b, 0xe4, 0x9d, 0xcb, 0xe1, 0xa2, 0x41, 0xc4, 0x37, 0xbe, 0x86, 0x86, 0xbe, 0xf7, 0x0b,
+	0x8b, 0xf5, 0x27, 0x3a, 0x86, 0x65, 0x11, 0xc3, 0x5b, 0xd9, 0x56, 0x21, 0x13, 0x41, 0x5e, 0xe6,
+	0x98, 0xc3, 0xe2, 0x5e, 0x5a, 0x41, 0xc5, 0x65, 0x0e, 0x3f, 0x86, 0xb6, 0xd6, 0x22, 0x22, 0x79,
+	0x17, 0x9a, 0xba, 0xed, 0xd0, 0x71, 0xcc, 0xf7, 0x25, 0x09, 0xc3, 0x9d, 0x3e, 0x40, 0xd2, 0x77,
+	0x21, 0x80, 0xda, 0xe1, 0xc9, 0x93, 0xdd, 0x9d, 0x0d, 0xab, 0x84, 0xda, 0xd0, 0x38, 0xd9, 0xdf,
+	0xdd, 0x39, 0x3a, 0xee, 0x6f, 0x5a, 0x06, 0x5a, 0x80, 0xd6, 0xce, 0xfe, 0x8f, 0x3b, 0xc7, 0xfd,
+	0xd3, 0x83, 0xfd, 0xdd, 0x9f, 0xac, 0x32, 0x27, 0x1f, 0xae, 0x1f, 0x1d, 0x3d, 0x3f, 0x20, 0x9b,
+	0x96, 0x79, 0x67, 0x0b, 0x16, 0x72, 0xe9, 0x88, 0x5a, 0x50, 0x3f, 0xec, 0xef, 0x6f, 0xee, 0xec,
+	0x6f, 0x49, 0x65, 0xeb, 0x1b, 0x1b, 0xfd, 0x43, 0xa9, 0xac, 0x0d, 0x8d, 0xcd, 0xfe, 0xc6, 0xee,
+	0xce, 0x7e, 0x7f, 0xd3, 0x2a, 0x73, 0xc6, 0xfe, 0x1f, 0x0f, 0x77, 0x48, 0x9f, 0x2b, 0x5a, 0x85,
+	0x0a, 0x09, 0x46, 0x94, 0x5b, 0xb2, 0xd7, 0xdf, 0x7b, 0xd2, 0x27, 0x56, 0x09, 0x35, 0xa1, 0xba,
+	0xbe, 0xb9, 0xb7, 0xb3, 0x6f, 0x19, 0xfc, 0xf3, 0xe0, 0xf9, 0x7e, 0x9f, 0x58, 0xe5, 0x3b, 0x8f,
+	0xa1, 0x93, 0xf1, 0x1e, 0xd7, 0x73, 0xf0, 0xfd, 0xf7, 0x5c, 0xab, 0x55, 0xe2, 0xf2, 0x07, 0xfb,
+	0xe2, 0xdb, 0x40, 0x0d, 0xa8, 0xac, 0x3f, 0x5f, 0xe7, 0x46, 0x37, 0xa0, 0xf2, 0xe4, 0xe4, 0xe8,
+	0x27, 0xcb, 0x5c, 0xfb, 0x37, 0x82, 0x16, 0x6f, 0x87, 0x8e, 0x68, 0xc8, 0xbb, 0x3c, 0xf4, 0xad,
+	0xee, 0xad, 0xed, 0x74, 0xbb, 0x95, 0x1e, 0x8a, 0x7a, 0x6f, 0x16, 0x50, 0x54, 0x67, 0x5e, 0x42,
+	0xeb, 0x71, 0xfb, 0x9d, 0x67, 0x4b, 0x06, 0x80, 0x5e, 0xaf, 0x88, 0x14, 0xab, 0xf8, 0x0e, 0x6a,
+	0x47, 0xde, 0x85, 0x7f, 0x32, 0xf9, 0xad, 0x36, 0x3c, 0x84, 0x36, 0xa1, 0xe7, 0x21, 0x8d, 0x86,
+	0xc7, 0x62, 0xb2, 0xd0, 0x2f, 0x54, 0xbc, 0x49, 0xfc, 0x75, 0xc9, 0x07, 0xa2, 0x3d, 0xf5, 0x2f,
+	0x68, 0x3c, 0x25, 0xa9, 0x47, 0x9e, 0xec, 0x78, 0xd5, 0x4b, 0x6b, 0xc4, 0x25, 0xf4, 0x25, 0x74,
+	0x37, 0xe5, 0xcc, 0xa3, 0xc6, 0x25, 0x84, 0xf4, 0x8b, 0x57, 0x32, 0x3d, 0xe5, 0x85, 0xee, 0x41,
+	0x93, 0x04, 0x53, 0x46, 0xb9, 0xf7, 0x51, 0xb6, 0xc0, 0xf7, 0xb2, 0x20, 0x2e, 0xad, 0x1a, 0xf7,
+	0x0d, 0xf4, 0x19, 0xc0, 0x89, 0x4f, 0xe8, 0x85, 0x17, 0xf1, 0x7a, 0x6b, 0x25, 0xad, 0xac, 0xdc,
+	0x4b, 0x5e, 0xff, 0xa7, 0xd0, 0x78, 0x75, 0xe6, 0x2f, 0x60, 0x61, 0x43, 0x94, 0xdf, 0x64, 0xec,
+	0xc9, 0xf7, 0xca, 0xf3, 0xf6, 0x77, 0xf8, 0xf3, 0xd3, 0xab, 0x0b, 0x7c, 0x0d, 0xd6, 0x16, 0x65,
+	0xd9, 0x39, 0x25, 0x13, 0x9a, 0x5b, 0x39, 0x05, 0x9c, 0x43, 0xa4, 0xd4, 0x72, 0x5a, 0x2e, 0x35,
+	0x3b, 0xcc, 0xad, 0xb8, 0x94, 0xdf, 0xa7, 0x52, 0xf1, 0x10, 0x16, 0x95, 0x8a, 0x94, 0x74, 0x66,
+	0xed, 0x9b, 0x24, 0xef, 0x41, 0x47, 0x3c, 0xab, 0x71, 0x02, 0x09, 0x82, 0xf1, 0x4b, 0x77, 0xf9,
+	0x0d, 0xc0, 0x16, 0x65, 0x6a, 0x0c, 0xd1, 0xe9, 0x93, 0x9d, 0x4a, 0x7a, 0x8b, 0x73, 0x4d, 0x0b,
+	0x2e, 0xa1, 0x35, 0xe8, 0x6e, 0x51, 0x96, 0x6e, 0x38, 0x32, 0x06, 0xc6, 0x6f, 0x9d, 0x31, 0x1d,
+	0x97, 0xd0, 0x23, 0xe1, 0xd2, 0xec, 0xf5, 0x31, 0x67, 0xa0, 0x72, 0x6b, 0x86, 0x4b, 0x24, 0xed,
+	0xe2, 0x11, 0xf5, 0x07, 0x9b, 0x5e, 0x48, 0x5d, 0xdd, 0x4c, 0xbc, 0x2c, 0x0f, 0xd1, 0x23, 0x58,
+	0xe4, 0xe6, 0xa6, 0x3b, 0x88, 0x28, 0x6b, 0xe7, 0xf2, 0x7c, 0x8f, 0xa1, 0x36, 0xf8, 0x35, 0x74,
+	0xf9, 0x82, 0xa9, 0xee, 0x62, 0xee, 0x5e, 0xed, 0xcd, 0x61, 0x84, 0xdc, 0x02, 0xd7, 0x90, 0xe0,
+	0xa2, 0xc2, 0xd0, 0x65, 0x2f, 0x72, 0x91, 0x37, 0x8b, 0xf2, 0x68, 0xa7, 0x97, 0xb4, 0xf3, 0xcc,
+	0xfa, 0xf4, 0x17, 0x2e, 0xfd, 0x00, 0xac, 0xe7, 0x0e, 0x73, 0x87, 0x37, 0xae, 0x5d, 0x20, 0x74,
+	0xdf, 0x40, 0x5f, 0x01, 0x3c, 0xf3, 0xdc, 0xcb, 0x3d, 0x79, 0x83, 0xbe, 0xa1, 0x9c, 0x98, 0xbf,
+	0xa0, 0xe7, 0xab, 0x48, 0xf3, 0x89, 0xe3, 0xbf, 0xa6, 0xd0, 0x57, 0x00, 0x7b, 0x53, 0x46, 0x5f,
+	0x53, 0xea, 0x1b, 0x7e, 0x67, 0x04, 0xe3, 0xe0, 0xb5, 0x05, 0x7f, 0x0f, 0x8b, 0xc7, 0xa1, 0xe3,
+	0x47, 0xe7, 0x34, 0x3c, 0xb8, 0xf6, 0x69, 0x18, 0x0d, 0xbd, 0xc9, 0x2b, 0x0b, 0xdf, 0x85, 0xd6,
+	0x11, 0x65, 0x71, 0x77, 0x90, 0xbb, 0x8d, 0xe7, 0x77, 0xd6, 0x11, 0xbe, 0x8f, 0xf9, 0x33, 0x8e,
+	0x47, 0x59, 0x61, 0x19, 0x72, 0x51, 0x24, 0x5b, 0xfc, 0x65, 0xf2, 0x55, 0xf3, 0xf9, 0x33, 0xe8,
+	0xc8, 0xa7, 0xc8, 0x1b, 0x04, 0xe6, 0x4a, 0x58, 0x73, 0x8b, 0xb2, 0x63, 0xf9, 0x33, 0xea, 0x35,
+	0xce, 0xf6, 0x03, 0x68, 0xad, 0x0f, 0x06, 0xf1, 0xab, 0xf5, 0xed, 0xdc, 0x0f, 0x29, 0x25, 0x3a,
+	0x67, 0xdd, 0x43, 0xfe, 0x42, 0xcc, 0x1f, 0x72, 0x5f, 0x5b, 0xf2, 0x3e, 0xb4, 0xf9, 0xd2, 0x7b,
+	0xfa, 0x5f, 0x56, 0x51, 0x29, 0xc9, 0x9a, 0xf8, 0x18, 0xba, 0x72, 0xc2, 0xdb, 0xd3, 0x83, 0xd6,
+	0xad, 0x78, 0x12, 0x4e, 0xc6, 0xd4, 0x5e, 0x0e, 0x29, 0x86, 0x41, 0x71, 0x67, 0x5b, 0x27, 0x93,
+	0x51, 0xe0, 0x0c, 0x52, 0x8f, 0x1b, 0xb7, 0xf3, 0xff, 0xca, 0xc4, 0x43, 0x45, 0x6f, 0xee, 0x17,
+	0x1a, 0xbf, 0xde, 0xd0, 0x36, 0xa0, 0xcd, 0xe0, 0xda, 0xcf, 0xa9, 0x78, 0x23, 0xcf, 0xab, 0xcd,
+	0x28, 0xd6, 0x2d, 0x32, 0xe0, 0x2e, 0x80, 0x78, 0x5f, 0x88, 0x86, 0x7c, 0xbc, 0x57, 0xd5, 0x30,
+	0x7e, 0x71, 0x98, 0xbf, 0x25, 0xeb, 0x5b, 0x94, 0x3d, 0xa3, 0xb3, 0x68, 0x9e, 0x55, 0x79, 0x55,
+	0x3d, 0x3d, 0xe0, 0xd2, 0x59, 0x4d, 0xfc, 0xa8, 0xfd, 0xf2, 0xff, 0x03, 0x00, 0xfd, 0xda, 0x08,
+	0x29, 0xb9, 0x1d, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// ChatServiceClient is the client API for ChatService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ChatServiceClient interface {
+	Login(ctx context.Context, in *ClientLoginRequest, opts ...grpc.CallOption) (*ClientLoginResponse, error)
+	Logout(ctx context.Context, in *ClientLogoutRequest, opts ...grpc.CallOption) (*ClientLogoutResponse, error)
+	SignUp(ctx context.Context, in *ClientLoginRequest, opts ...grpc.CallOption) (*ClientLoginResponse, error)
+	RefreshToken(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ClientLoginResponse, error)
+	ChangePassword(ctx context.Context, in *PasswordChange, opts ...grpc.CallOption) (*Empty, error)
+	DisableAccount(ctx context.Context, in *AccountState, opts ...grpc.CallOption) (*Empty, error)
+	RouteChat(ctx context.Context, opts ...grpc.CallOption) (ChatService_RouteChatClient, error)
+	UnRegister(ctx context.Context, in *ChatClient, opts ...grpc.CallOption) (*Empty, error)
+	Register(ctx context.Context, in *ChatClient, opts ...grpc.CallOption) (*Empty, error)
+	CreateChatGroup(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*Empty, error)
+	JoinChatGroup(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*Empty, error)
+	GetChatGroupList(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChatGroupList, error)
+	GetChatGroupClientList(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*ChatClientList, error)
+	GetChatClientList(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChatClientList, error)
+	LeaveChatRoom(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*Empty, error)
+	GetHistory(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*MessageList, error)
+	GetUnreadCount(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*UnreadCount, error)
+	GetOverflowStats(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*OverflowStats, error)
+	SendDirectMessage(ctx context.Context, in *Message, opts ...grpc.CallOption) (*Message, error)
+	ListConversations(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ConversationList, error)
+	SendInvitation(ctx context.Context, in *Invitation, opts ...grpc.CallOption) (*Invitation, error)
+	ListInvitations(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*InvitationList, error)
+	RespondInvitation(ctx context.Context, in *InvitationResponse, opts ...grpc.CallOption) (*Invitation, error)
+	WatchInvitations(ctx context.Context, in *Empty, opts ...grpc.CallOption) (ChatService_WatchInvitationsClient, error)
+	KickMember(ctx context.Context, in *ModerationRequest, opts ...grpc.CallOption) (*Empty, error)
+	BanMember(ctx context.Context, in *ModerationRequest, opts ...grpc.CallOption) (*Empty, error)
+	MuteMember(ctx context.Context, in *ModerationRequest, opts ...grpc.CallOption) (*Empty, error)
+	PromoteMember(ctx context.Context, in *ModerationRequest, opts ...grpc.CallOption) (*Empty, error)
+	TransferOwnership(ctx context.Context, in *ModerationRequest, opts ...grpc.CallOption) (*Empty, error)
+	SetPresence(ctx context.Context, in *Presence, opts ...grpc.CallOption) (*Empty, error)
+	WatchPresence(ctx context.Context, in *Empty, opts ...grpc.CallOption) (ChatService_WatchPresenceClient, error)
+	EditMessage(ctx context.Context, in *Message, opts ...grpc.CallOption) (*Message, error)
+	DeleteMessage(ctx context.Context, in *Message, opts ...grpc.CallOption) (*Empty, error)
+	GetThread(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*MessageList, error)
+	AddReaction(ctx context.Context, in *ReactionRequest, opts ...grpc.CallOption) (*Message, error)
+	RemoveReaction(ctx context.Context, in *ReactionRequest, opts ...grpc.CallOption) (*Message, error)
+	ListMentions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*MessageList, error)
+	SearchMessages(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResults, error)
+	UploadAttachment(ctx context.Context, opts ...grpc.CallOption) (ChatService_UploadAttachmentClient, error)
+	DownloadAttachment(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (ChatService_DownloadAttachmentClient, error)
+	PublishKey(ctx context.Context, in *PublicKey, opts ...grpc.CallOption) (*Empty, error)
+	GetKeys(ctx context.Context, in *PublicKey, opts ...grpc.CallOption) (*KeyList, error)
+}
+
+type chatServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChatServiceClient(cc grpc.ClientConnInterface) ChatServiceClient {
+	return &chatServiceClient{cc}
+}
+
+func (c *chatServiceClient) Login(ctx context.Context, in *ClientLoginRequest, opts ...grpc.CallOption) (*ClientLoginResponse, error) {
+	out := new(ClientLoginResponse)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/Login", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) Logout(ctx context.Context, in *ClientLogoutRequest, opts ...grpc.CallOption) (*ClientLogoutResponse, error) {
+	out := new(ClientLogoutResponse)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/Logout", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) SignUp(ctx context.Context, in *ClientLoginRequest, opts ...grpc.CallOption) (*ClientLoginResponse, error) {
+	out := new(ClientLoginResponse)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/SignUp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) RefreshToken(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ClientLoginResponse, error) {
+	out := new(ClientLoginResponse)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/RefreshToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) ChangePassword(ctx context.Context, in *PasswordChange, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/ChangePassword", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) DisableAccount(ctx context.Context, in *AccountState, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/DisableAccount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) RouteChat(ctx context.Context, opts ...grpc.CallOption) (ChatService_RouteChatClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ChatService_serviceDesc.Streams[0], "/chat.ChatService/RouteChat", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatServiceRouteChatClient{stream}
+	return x, nil
+}
+
+type ChatService_RouteChatClient interface {
+	Send(*Message) error
+	Recv() (*Message, error)
+	grpc.ClientStream
+}
+
+type chatServiceRouteChatClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatServiceRouteChatClient) Send(m *Message) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *chatServiceRouteChatClient) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *chatServiceClient) UnRegister(ctx context.Context, in *ChatClient, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/UnRegister", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) Register(ctx context.Context, in *ChatClient, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/Register", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) CreateChatGroup(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/CreateChatGroup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) JoinChatGroup(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/JoinChatGroup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) GetChatGroupList(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChatGroupList, error) {
+	out := new(ChatGroupList)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/GetChatGroupList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) GetChatGroupClientList(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*ChatClientList, error) {
+	out := new(ChatClientList)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/GetChatGroupClientList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) GetChatClientList(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChatClientList, error) {
+	out := new(ChatClientList)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/GetChatClientList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) LeaveChatRoom(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/LeaveChatRoom", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) GetHistory(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*MessageList, error) {
+	out := new(MessageList)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/GetHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) GetUnreadCount(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*UnreadCount, error) {
+	out := new(UnreadCount)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/GetUnreadCount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) GetOverflowStats(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*OverflowStats, error) {
+	out := new(OverflowStats)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/GetOverflowStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) SendDirectMessage(ctx context.Context, in *Message, opts ...grpc.CallOption) (*Message, error) {
+	out := new(Message)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/SendDirectMessage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) ListConversations(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ConversationList, error) {
+	out := new(ConversationList)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/ListConversations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) SendInvitation(ctx context.Context, in *Invitation, opts ...grpc.CallOption) (*Invitation, error) {
+	out := new(Invitation)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/SendInvitation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) ListInvitations(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*InvitationList, error) {
+	out := new(InvitationList)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/ListInvitations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) RespondInvitation(ctx context.Context, in *InvitationResponse, opts ...grpc.CallOption) (*Invitation, error) {
+	out := new(Invitation)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/RespondInvitation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) WatchInvitations(ctx context.Context, in *Empty, opts ...grpc.CallOption) (ChatService_WatchInvitationsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ChatService_serviceDesc.Streams[1], "/chat.ChatService/WatchInvitations", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatServiceWatchInvitationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ChatService_WatchInvitationsClient interface {
+	Recv() (*Invitation, error)
+	grpc.ClientStream
+}
+
+type chatServiceWatchInvitationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatServiceWatchInvitationsClient) Recv() (*Invitation, error) {
+	m := new(Invitation)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *chatServiceClient) KickMember(ctx context.Context, in *ModerationRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/KickMember", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) BanMember(ctx context.Context, in *ModerationRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/BanMember", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) MuteMember(ctx context.Context, in *ModerationRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/MuteMember", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) PromoteMember(ctx context.Context, in *ModerationRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/PromoteMember", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) TransferOwnership(ctx context.Context, in *ModerationRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/TransferOwnership", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) SetPresence(ctx context.Context, in *Presence, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/SetPresence", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) WatchPresence(ctx context.Context, in *Empty, opts ...grpc.CallOption) (ChatService_WatchPresenceClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ChatService_serviceDesc.Streams[2], "/chat.ChatService/WatchPresence", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatServiceWatchPresenceClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ChatService_WatchPresenceClient interface {
+	Recv() (*PresenceList, error)
+	grpc.ClientStream
+}
+
+type chatServiceWatchPresenceClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatServiceWatchPresenceClient) Recv() (*PresenceList, error) {
+	m := new(PresenceList)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *chatServiceClient) EditMessage(ctx context.Context, in *Message, opts ...grpc.CallOption) (*Message, error) {
+	out := new(Message)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/EditMessage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) DeleteMessage(ctx context.Context, in *Message, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/DeleteMessage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) GetThread(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*MessageList, error) {
+	out := new(MessageList)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/GetThread", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) AddReaction(ctx context.Context, in *ReactionRequest, opts ...grpc.CallOption) (*Message, error) {
+	out := new(Message)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/AddReaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) RemoveReaction(ctx context.Context, in *ReactionRequest, opts ...grpc.CallOption) (*Message, error) {
+	out := new(Message)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/RemoveReaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) ListMentions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*MessageList, error) {
+	out := new(MessageList)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/ListMentions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) SearchMessages(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResults, error) {
+	out := new(SearchResults)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/SearchMessages", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) UploadAttachment(ctx context.Context, opts ...grpc.CallOption) (ChatService_UploadAttachmentClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ChatService_serviceDesc.Streams[3], "/chat.ChatService/UploadAttachment", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatServiceUploadAttachmentClient{stream}
+	return x, nil
+}
+
+type ChatService_UploadAttachmentClient interface {
+	Send(*AttachmentChunk) error
+	CloseAndRecv() (*Attachment, error)
+	grpc.ClientStream
+}
+
+type chatServiceUploadAttachmentClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatServiceUploadAttachmentClient) Send(m *AttachmentChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *chatServiceUploadAttachmentClient) CloseAndRecv() (*Attachment, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Attachment)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *chatServiceClient) DownloadAttachment(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (ChatService_DownloadAttachmentClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ChatService_serviceDesc.Streams[4], "/chat.ChatService/DownloadAttachment", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatServiceDownloadAttachmentClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ChatService_DownloadAttachmentClient interface {
+	Recv() (*AttachmentChunk, error)
+	grpc.ClientStream
+}
+
+type chatServiceDownloadAttachmentClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatServiceDownloadAttachmentClient) Recv() (*AttachmentChunk, error) {
+	m := new(AttachmentChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *chatServiceClient) PublishKey(ctx context.Context, in *PublicKey, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/PublishKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) GetKeys(ctx context.Context, in *PublicKey, opts ...grpc.CallOption) (*KeyList, error) {
+	out := new(KeyList)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/GetKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChatServiceServer is the server API for ChatService service.
+type ChatServiceServer interface {
+	Login(context.Context, *ClientLoginRequest) (*ClientLoginResponse, error)
+	Logout(context.Context, *ClientLogoutRequest) (*ClientLogoutResponse, error)
+	SignUp(context.Context, *ClientLoginRequest) (*ClientLoginResponse, error)
+	RefreshToken(context.Context, *Empty) (*ClientLoginResponse, error)
+	ChangePassword(context.Context, *PasswordChange) (*Empty, error)
+	DisableAccount(context.Context, *AccountState) (*Empty, error)
+	RouteChat(ChatService_RouteChatServer) error
+	UnRegister(context.Context, *ChatClient) (*Empty, error)
+	Register(context.Context, *ChatClient) (*Empty, error)
+	CreateChatGroup(context.Context, *ChatGroup) (*Empty, error)
+	JoinChatGroup(context.Context, *ChatGroup) (*Empty, error)
+	GetChatGroupList(context.Context, *Empty) (*ChatGroupList, error)
+	GetChatGroupClientList(context.Context, *ChatGroup) (*ChatClientList, error)
+	GetChatClientList(context.Context, *Empty) (*ChatClientList, error)
+	LeaveChatRoom(context.Context, *ChatGroup) (*Empty, error)
+	GetHistory(context.Context, *HistoryRequest) (*MessageList, error)
+	GetUnreadCount(context.Context, *Empty) (*UnreadCount, error)
+	GetOverflowStats(context.Context, *ChatGroup) (*OverflowStats, error)
+	SendDirectMessage(context.Context, *Message) (*Message, error)
+	ListConversations(context.Context, *Empty) (*ConversationList, error)
+	SendInvitation(context.Context, *Invitation) (*Invitation, error)
+	ListInvitations(context.Context, *Empty) (*InvitationList, error)
+	RespondInvitation(context.Context, *InvitationResponse) (*Invitation, error)
+	WatchInvitations(*Empty, ChatService_WatchInvitationsServer) error
+	KickMember(context.Context, *ModerationRequest) (*Empty, error)
+	BanMember(context.Context, *ModerationRequest) (*Empty, error)
+	MuteMember(context.Context, *ModerationRequest) (*Empty, error)
+	PromoteMember(context.Context, *ModerationRequest) (*Empty, error)
+	TransferOwnership(context.Context, *ModerationRequest) (*Empty, error)
+	SetPresence(context.Context, *Presence) (*Empty, error)
+	WatchPresence(*Empty, ChatService_WatchPresenceServer) error
+	EditMessage(context.Context, *Message) (*Message, error)
+	DeleteMessage(context.Context, *Message) (*Empty, error)
+	GetThread(context.Context, *HistoryRequest) (*MessageList, error)
+	AddReaction(context.Context, *ReactionRequest) (*Message, error)
+	RemoveReaction(context.Context, *ReactionRequest) (*Message, error)
+	ListMentions(context.Context, *Empty) (*MessageList, error)
+	SearchMessages(context.Context, *SearchRequest) (*SearchResults, error)
+	UploadAttachment(ChatService_UploadAttachmentServer) error
+	DownloadAttachment(*AttachmentRequest, ChatService_DownloadAttachmentServer) error
+	PublishKey(context.Context, *PublicKey) (*Empty, error)
+	GetKeys(context.Context, *PublicKey) (*KeyList, error)
+}
+
+// UnimplementedChatServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedChatServiceServer struct {
+}
+
+func (*UnimplementedChatServiceServer) Login(ctx context.Context, req *ClientLoginRequest) (*ClientLoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
+}
+func (*UnimplementedChatServiceServer) Logout(ctx context.Context, req *ClientLogoutRequest) (*ClientLogoutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Logout not implemented")
+}
+func (*UnimplementedChatServiceServer) SignUp(ctx context.Context, req *ClientLoginRequest) (*ClientLoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignUp not implemented")
+}
+func (*UnimplementedChatServiceServer) RefreshToken(ctx context.Context, req *Empty) (*ClientLoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshToken not implemented")
+}
+func (*UnimplementedChatServiceServer) ChangePassword(ctx context.Context, req *PasswordChange) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePassword not implemented")
+}
+func (*UnimplementedChatServiceServer) DisableAccount(ctx context.Context, req *AccountState) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableAccount not implemented")
+}
+func (*UnimplementedChatServiceServer) RouteChat(srv ChatService_RouteChatServer) error {
+	return status.Errorf(codes.Unimplemented, "method RouteChat not implemented")
+}
+func (*UnimplementedChatServiceServer) UnRegister(ctx context.Context, req *ChatClient) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnRegister not implemented")
+}
+func (*UnimplementedChatServiceServer) Register(ctx context.Context, req *ChatClient) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (*UnimplementedChatServiceServer) CreateChatGroup(ctx context.Context, req *ChatGroup) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateChatGroup not implemented")
+}
+func (*UnimplementedChatServiceServer) JoinChatGroup(ctx context.Context, req *ChatGroup) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JoinChatGroup not implemented")
+}
+func (*UnimplementedChatServiceServer) GetChatGroupList(ctx context.Context, req *Empty) (*ChatGroupList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChatGroupList not implemented")
+}
+func (*UnimplementedChatServiceServer) GetChatGroupClientList(ctx context.Context, req *ChatGroup) (*ChatClientList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChatGroupClientList not implemented")
+}
+func (*UnimplementedChatServiceServer) GetChatClientList(ctx context.Context, req *Empty) (*ChatClientList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChatClientList not implemented")
+}
+func (*UnimplementedChatServiceServer) LeaveChatRoom(ctx context.Context, req *ChatGroup) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LeaveChatRoom not implemented")
+}
+func (*UnimplementedChatServiceServer) GetHistory(ctx context.Context, req *HistoryRequest) (*MessageList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHistory not implemented")
+}
+func (*UnimplementedChatServiceServer) GetUnreadCount(ctx context.Context, req *Empty) (*UnreadCount, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUnreadCount not implemented")
+}
+func (*UnimplementedChatServiceServer) GetOverflowStats(ctx context.Context, req *ChatGroup) (*OverflowStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOverflowStats not implemented")
+}
+func (*UnimplementedChatServiceServer) SendDirectMessage(ctx context.Context, req *Message) (*Message, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendDirectMessage not implemented")
+}
+func (*UnimplementedChatServiceServer) ListConversations(ctx context.Context, req *Empty) (*ConversationList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConversations not implemented")
+}
+func (*UnimplementedChatServiceServer) SendInvitation(ctx context.Context, req *Invitation) (*Invitation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendInvitation not implemented")
+}
+func (*UnimplementedChatServiceServer) ListInvitations(ctx context.Context, req *Empty) (*InvitationList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListInvitations not implemented")
+}
+func (*UnimplementedChatServiceServer) RespondInvitation(ctx context.Context, req *InvitationResponse) (*Invitation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RespondInvitation not implemented")
+}
+func (*UnimplementedChatServiceServer) WatchInvitations(req *Empty, srv ChatService_WatchInvitationsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchInvitations not implemented")
+}
+func (*UnimplementedChatServiceServer) KickMember(ctx context.Context, req *ModerationRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KickMember not implemented")
+}
+func (*UnimplementedChatServiceServer) BanMember(ctx context.Context, req *ModerationRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BanMember not implemented")
+}
+func (*UnimplementedChatServiceServer) MuteMember(ctx context.Context, req *ModerationRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MuteMember not implemented")
+}
+func (*UnimplementedChatServiceServer) PromoteMember(ctx context.Context, req *ModerationRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PromoteMember not implemented")
+}
+func (*UnimplementedChatServiceServer) TransferOwnership(ctx context.Context, req *ModerationRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TransferOwnership not implemented")
+}
+func (*UnimplementedChatServiceServer) SetPresence(ctx context.Context, req *Presence) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPresence not implemented")
+}
+func (*UnimplementedChatServiceServer) WatchPresence(req *Empty, srv ChatService_WatchPresenceServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPresence not implemented")
+}
+func (*UnimplementedChatServiceServer) EditMessage(ctx context.Context, req *Message) (*Message, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditMessage not implemented")
+}
+func (*UnimplementedChatServiceServer) DeleteMessage(ctx context.Context, req *Message) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMessage not implemented")
+}
+func (*UnimplementedChatServiceServer) GetThread(ctx context.Context, req *HistoryRequest) (*MessageList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetThread not implemented")
+}
+func (*UnimplementedChatServiceServer) AddReaction(ctx context.Context, req *ReactionRequest) (*Message, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddReaction not implemented")
+}
+func (*UnimplementedChatServiceServer) RemoveReaction(ctx context.Context, req *ReactionRequest) (*Message, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveReaction not implemented")
+}
+func (*UnimplementedChatServiceServer) ListMentions(ctx context.Context, req *Empty) (*MessageList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMentions not implemented")
+}
+func (*UnimplementedChatServiceServer) SearchMessages(ctx context.Context, req *SearchRequest) (*SearchResults, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchMessages not implemented")
+}
+func (*UnimplementedChatServiceServer) UploadAttachment(srv ChatService_UploadAttachmentServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadAttachment not implemented")
+}
+func (*UnimplementedChatServiceServer) DownloadAttachment(req *AttachmentRequest, srv ChatService_DownloadAttachmentServer) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadAttachment not implemented")
+}
+func (*UnimplementedChatServiceServer) PublishKey(ctx context.Context, req *PublicKey) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishKey not implemented")
+}
+func (*UnimplementedChatServiceServer) GetKeys(ctx context.Context, req *PublicKey) (*KeyList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKeys not implemented")
+}
+
+func RegisterChatServiceServer(s *grpc.Server, srv ChatServiceServer) {
+	s.RegisterService(&_ChatService_serviceDesc, srv)
+}
+
+func _ChatService_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClientLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/Login",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).Login(ctx, req.(*ClientLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClientLogoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).Logout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/Logout",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).Logout(ctx, req.(*ClientLogoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_SignUp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClientLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).SignUp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/SignUp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).SignUp(ctx, req.(*ClientLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_RefreshToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).RefreshToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/RefreshToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).RefreshToken(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PasswordChange)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).ChangePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/ChangePassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).ChangePassword(ctx, req.(*PasswordChange))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_DisableAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountState)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).DisableAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/DisableAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).DisableAccount(ctx, req.(*AccountState))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_RouteChat_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChatServiceServer).RouteChat(&chatServiceRouteChatServer{stream})
+}
+
+type ChatService_RouteChatServer interface {
+	Send(*Message) error
+	Recv() (*Message, error)
+	grpc.ServerStream
+}
+
+type chatServiceRouteChatServer struct {
+	grpc.ServerStream
+}
+
+func (x *chatServiceRouteChatServer) Send(m *Message) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *chatServiceRouteChatServer) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ChatService_UnRegister_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChatClient)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).UnRegister(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/UnRegister",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).UnRegister(ctx, req.(*ChatClient))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChatClient)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/Register",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).Register(ctx, req.(*ChatClient))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_CreateChatGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChatGroup)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).CreateChatGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/CreateChatGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).CreateChatGroup(ctx, req.(*ChatGroup))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_JoinChatGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChatGroup)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).JoinChatGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/JoinChatGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).JoinChatGroup(ctx, req.(*ChatGroup))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_GetChatGroupList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).GetChatGroupList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/GetChatGroupList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).GetChatGroupList(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_GetChatGroupClientList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChatGroup)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).GetChatGroupClientList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/GetChatGroupClientList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).GetChatGroupClientList(ctx, req.(*ChatGroup))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_GetChatClientList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).GetChatClientList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/GetChatClientList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).GetChatClientList(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_LeaveChatRoom_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChatGroup)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).LeaveChatRoom(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/LeaveChatRoom",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).LeaveChatRoom(ctx, req.(*ChatGroup))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/GetHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).GetHistory(ctx, req.(*HistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_GetUnreadCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).GetUnreadCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/GetUnreadCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).GetUnreadCount(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_GetOverflowStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChatGroup)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).GetOverflowStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/GetOverflowStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).GetOverflowStats(ctx, req.(*ChatGroup))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_SendDirectMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Message)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).SendDirectMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/SendDirectMessage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).SendDirectMessage(ctx, req.(*Message))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_ListConversations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).ListConversations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/ListConversations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).ListConversations(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_SendInvitation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Invitation)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).SendInvitation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/SendInvitation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).SendInvitation(ctx, req.(*Invitation))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_ListInvitations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).ListInvitations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/ListInvitations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).ListInvitations(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_RespondInvitation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvitationResponse)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).RespondInvitation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/RespondInvitation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).RespondInvitation(ctx, req.(*InvitationResponse))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *chatServiceClient) CreateChatGroup(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
-	err := grpc.Invoke(ctx, "/chat.ChatService/CreateChatGroup", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func _ChatService_WatchInvitations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
 	}
-	return out, nil
+	return srv.(ChatServiceServer).WatchInvitations(m, &chatServiceWatchInvitationsServer{stream})
 }
 
-func (c *chatServiceClient) JoinChatGroup(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
-	err := grpc.Invoke(ctx, "/chat.ChatService/JoinChatGroup", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type ChatService_WatchInvitationsServer interface {
+	Send(*Invitation) error
+	grpc.ServerStream
 }
 
-func (c *chatServiceClient) GetChatGroupList(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChatGroupList, error) {
-	out := new(ChatGroupList)
-	err := grpc.Invoke(ctx, "/chat.ChatService/GetChatGroupList", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type chatServiceWatchInvitationsServer struct {
+	grpc.ServerStream
 }
 
-func (c *chatServiceClient) GetChatGroupClientList(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*ChatClientList, error) {
-	out := new(ChatClientList)
-	err := grpc.Invoke(ctx, "/chat.ChatService/GetChatGroupClientList", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (x *chatServiceWatchInvitationsServer) Send(m *Invitation) error {
+	return x.ServerStream.SendMsg(m)
 }
 
-func (c *chatServiceClient) GetChatClientList(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChatClientList, error) {
-	out := new(ChatClientList)
-	err := grpc.Invoke(ctx, "/chat.ChatService/GetChatClientList", in, out, c.cc, opts...)
-	if err != nil {
+func _ChatService_KickMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModerationRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(ChatServiceServer).KickMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/KickMember",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).KickMember(ctx, req.(*ModerationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *chatServiceClient) LeaveChatRoom(ctx context.Context, in *ChatGroup, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
-	err := grpc.Invoke(ctx, "/chat.ChatService/LeaveChatRoom", in, out, c.cc, opts...)
-	if err != nil {
+func _ChatService_BanMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModerationRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(ChatServiceServer).BanMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/BanMember",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).BanMember(ctx, req.(*ModerationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// Server API for ChatService service
-
-type ChatServiceServer interface {
-	Login(context.Context, *ClientLoginRequest) (*ClientLoginResponse, error)
-	Logout(context.Context, *ClientLogoutRequest) (*ClientLogoutResponse, error)
-	RouteChat(ChatService_RouteChatServer) error
-	UnRegister(context.Context, *ChatClient) (*Empty, error)
-	Register(context.Context, *ChatClient) (*Empty, error)
-	CreateChatGroup(context.Context, *ChatGroup) (*Empty, error)
-	JoinChatGroup(context.Context, *ChatGroup) (*Empty, error)
-	GetChatGroupList(context.Context, *Empty) (*ChatGroupList, error)
-	GetChatGroupClientList(context.Context, *ChatGroup) (*ChatClientList, error)
-	GetChatClientList(context.Context, *Empty) (*ChatClientList, error)
-	LeaveChatRoom(context.Context, *ChatGroup) (*Empty, error)
+func _ChatService_MuteMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModerationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).MuteMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/MuteMember",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).MuteMember(ctx, req.(*ModerationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterChatServiceServer(s *grpc.Server, srv ChatServiceServer) {
-	s.RegisterService(&_ChatService_serviceDesc, srv)
+func _ChatService_PromoteMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModerationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).PromoteMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/PromoteMember",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).PromoteMember(ctx, req.(*ModerationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _ChatService_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ClientLoginRequest)
+func _ChatService_TransferOwnership_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModerationRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ChatServiceServer).Login(ctx, in)
+		return srv.(ChatServiceServer).TransferOwnership(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/chat.ChatService/Login",
+		FullMethod: "/chat.ChatService/TransferOwnership",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ChatServiceServer).Login(ctx, req.(*ClientLoginRequest))
+		return srv.(ChatServiceServer).TransferOwnership(ctx, req.(*ModerationRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ChatService_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ClientLogoutRequest)
+func _ChatService_SetPresence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Presence)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ChatServiceServer).Logout(ctx, in)
+		return srv.(ChatServiceServer).SetPresence(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/chat.ChatService/Logout",
+		FullMethod: "/chat.ChatService/SetPresence",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ChatServiceServer).Logout(ctx, req.(*ClientLogoutRequest))
+		return srv.(ChatServiceServer).SetPresence(ctx, req.(*Presence))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ChatService_RouteChat_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(ChatServiceServer).RouteChat(&chatServiceRouteChatServer{stream})
+func _ChatService_WatchPresence_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChatServiceServer).WatchPresence(m, &chatServiceWatchPresenceServer{stream})
 }
 
-type ChatService_RouteChatServer interface {
-	Send(*Message) error
-	Recv() (*Message, error)
+type ChatService_WatchPresenceServer interface {
+	Send(*PresenceList) error
 	grpc.ServerStream
 }
 
-type chatServiceRouteChatServer struct {
+type chatServiceWatchPresenceServer struct {
 	grpc.ServerStream
 }
 
-func (x *chatServiceRouteChatServer) Send(m *Message) error {
+func (x *chatServiceWatchPresenceServer) Send(m *PresenceList) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-func (x *chatServiceRouteChatServer) Recv() (*Message, error) {
-	m := new(Message)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
+func _ChatService_EditMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Message)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return m, nil
+	if interceptor == nil {
+		return srv.(ChatServiceServer).EditMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/EditMessage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).EditMessage(ctx, req.(*Message))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _ChatService_UnRegister_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ChatClient)
+func _ChatService_DeleteMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Message)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ChatServiceServer).UnRegister(ctx, in)
+		return srv.(ChatServiceServer).DeleteMessage(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/chat.ChatService/UnRegister",
+		FullMethod: "/chat.ChatService/DeleteMessage",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ChatServiceServer).UnRegister(ctx, req.(*ChatClient))
+		return srv.(ChatServiceServer).DeleteMessage(ctx, req.(*Message))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ChatService_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ChatClient)
+func _ChatService_GetThread_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ChatServiceServer).Register(ctx, in)
+		return srv.(ChatServiceServer).GetThread(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/chat.ChatService/Register",
+		FullMethod: "/chat.ChatService/GetThread",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ChatServiceServer).Register(ctx, req.(*ChatClient))
+		return srv.(ChatServiceServer).GetThread(ctx, req.(*HistoryRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ChatService_CreateChatGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ChatGroup)
+func _ChatService_AddReaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReactionRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ChatServiceServer).CreateChatGroup(ctx, in)
+		return srv.(ChatServiceServer).AddReaction(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/chat.ChatService/CreateChatGroup",
+		FullMethod: "/chat.ChatService/AddReaction",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ChatServiceServer).CreateChatGroup(ctx, req.(*ChatGroup))
+		return srv.(ChatServiceServer).AddReaction(ctx, req.(*ReactionRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ChatService_JoinChatGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ChatGroup)
+func _ChatService_RemoveReaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReactionRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ChatServiceServer).JoinChatGroup(ctx, in)
+		return srv.(ChatServiceServer).RemoveReaction(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/chat.ChatService/JoinChatGroup",
+		FullMethod: "/chat.ChatService/RemoveReaction",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ChatServiceServer).JoinChatGroup(ctx, req.(*ChatGroup))
+		return srv.(ChatServiceServer).RemoveReaction(ctx, req.(*ReactionRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ChatService_GetChatGroupList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _ChatService_ListMentions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ChatServiceServer).GetChatGroupList(ctx, in)
+		return srv.(ChatServiceServer).ListMentions(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/chat.ChatService/GetChatGroupList",
+		FullMethod: "/chat.ChatService/ListMentions",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ChatServiceServer).GetChatGroupList(ctx, req.(*Empty))
+		return srv.(ChatServiceServer).ListMentions(ctx, req.(*Empty))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ChatService_GetChatGroupClientList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ChatGroup)
+func _ChatService_SearchMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ChatServiceServer).GetChatGroupClientList(ctx, in)
+		return srv.(ChatServiceServer).SearchMessages(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/chat.ChatService/GetChatGroupClientList",
+		FullMethod: "/chat.ChatService/SearchMessages",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ChatServiceServer).GetChatGroupClientList(ctx, req.(*ChatGroup))
+		return srv.(ChatServiceServer).SearchMessages(ctx, req.(*SearchRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ChatService_GetChatClientList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
+func _ChatService_UploadAttachment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChatServiceServer).UploadAttachment(&chatServiceUploadAttachmentServer{stream})
+}
+
+type ChatService_UploadAttachmentServer interface {
+	SendAndClose(*Attachment) error
+	Recv() (*AttachmentChunk, error)
+	grpc.ServerStream
+}
+
+type chatServiceUploadAttachmentServer struct {
+	grpc.ServerStream
+}
+
+func (x *chatServiceUploadAttachmentServer) SendAndClose(m *Attachment) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *chatServiceUploadAttachmentServer) Recv() (*AttachmentChunk, error) {
+	m := new(AttachmentChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ChatService_DownloadAttachment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AttachmentRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChatServiceServer).DownloadAttachment(m, &chatServiceDownloadAttachmentServer{stream})
+}
+
+type ChatService_DownloadAttachmentServer interface {
+	Send(*AttachmentChunk) error
+	grpc.ServerStream
+}
+
+type chatServiceDownloadAttachmentServer struct {
+	grpc.ServerStream
+}
+
+func (x *chatServiceDownloadAttachmentServer) Send(m *AttachmentChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ChatService_PublishKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublicKey)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ChatServiceServer).GetChatClientList(ctx, in)
+		return srv.(ChatServiceServer).PublishKey(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/chat.ChatService/GetChatClientList",
+		FullMethod: "/chat.ChatService/PublishKey",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ChatServiceServer).GetChatClientList(ctx, req.(*Empty))
+		return srv.(ChatServiceServer).PublishKey(ctx, req.(*PublicKey))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ChatService_LeaveChatRoom_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ChatGroup)
+func _ChatService_GetKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublicKey)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ChatServiceServer).LeaveChatRoom(ctx, in)
+		return srv.(ChatServiceServer).GetKeys(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/chat.ChatService/LeaveChatRoom",
+		FullMethod: "/chat.ChatService/GetKeys",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ChatServiceServer).LeaveChatRoom(ctx, req.(*ChatGroup))
+		return srv.(ChatServiceServer).GetKeys(ctx, req.(*PublicKey))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -657,6 +4834,22 @@ var _ChatService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Logout",
 			Handler:    _ChatService_Logout_Handler,
 		},
+		{
+			MethodName: "SignUp",
+			Handler:    _ChatService_SignUp_Handler,
+		},
+		{
+			MethodName: "RefreshToken",
+			Handler:    _ChatService_RefreshToken_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _ChatService_ChangePassword_Handler,
+		},
+		{
+			MethodName: "DisableAccount",
+			Handler:    _ChatService_DisableAccount_Handler,
+		},
 		{
 			MethodName: "UnRegister",
 			Handler:    _ChatService_UnRegister_Handler,
@@ -689,6 +4882,98 @@ var _ChatService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "LeaveChatRoom",
 			Handler:    _ChatService_LeaveChatRoom_Handler,
 		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _ChatService_GetHistory_Handler,
+		},
+		{
+			MethodName: "GetUnreadCount",
+			Handler:    _ChatService_GetUnreadCount_Handler,
+		},
+		{
+			MethodName: "GetOverflowStats",
+			Handler:    _ChatService_GetOverflowStats_Handler,
+		},
+		{
+			MethodName: "SendDirectMessage",
+			Handler:    _ChatService_SendDirectMessage_Handler,
+		},
+		{
+			MethodName: "ListConversations",
+			Handler:    _ChatService_ListConversations_Handler,
+		},
+		{
+			MethodName: "SendInvitation",
+			Handler:    _ChatService_SendInvitation_Handler,
+		},
+		{
+			MethodName: "ListInvitations",
+			Handler:    _ChatService_ListInvitations_Handler,
+		},
+		{
+			MethodName: "RespondInvitation",
+			Handler:    _ChatService_RespondInvitation_Handler,
+		},
+		{
+			MethodName: "KickMember",
+			Handler:    _ChatService_KickMember_Handler,
+		},
+		{
+			MethodName: "BanMember",
+			Handler:    _ChatService_BanMember_Handler,
+		},
+		{
+			MethodName: "MuteMember",
+			Handler:    _ChatService_MuteMember_Handler,
+		},
+		{
+			MethodName: "PromoteMember",
+			Handler:    _ChatService_PromoteMember_Handler,
+		},
+		{
+			MethodName: "TransferOwnership",
+			Handler:    _ChatService_TransferOwnership_Handler,
+		},
+		{
+			MethodName: "SetPresence",
+			Handler:    _ChatService_SetPresence_Handler,
+		},
+		{
+			MethodName: "EditMessage",
+			Handler:    _ChatService_EditMessage_Handler,
+		},
+		{
+			MethodName: "DeleteMessage",
+			Handler:    _ChatService_DeleteMessage_Handler,
+		},
+		{
+			MethodName: "GetThread",
+			Handler:    _ChatService_GetThread_Handler,
+		},
+		{
+			MethodName: "AddReaction",
+			Handler:    _ChatService_AddReaction_Handler,
+		},
+		{
+			MethodName: "RemoveReaction",
+			Handler:    _ChatService_RemoveReaction_Handler,
+		},
+		{
+			MethodName: "ListMentions",
+			Handler:    _ChatService_ListMentions_Handler,
+		},
+		{
+			MethodName: "SearchMessages",
+			Handler:    _ChatService_SearchMessages_Handler,
+		},
+		{
+			MethodName: "PublishKey",
+			Handler:    _ChatService_PublishKey_Handler,
+		},
+		{
+			MethodName: "GetKeys",
+			Handler:    _ChatService_GetKeys_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -697,41 +4982,26 @@ var _ChatService_serviceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "WatchInvitations",
+			Handler:       _ChatService_WatchInvitations_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchPresence",
+			Handler:       _ChatService_WatchPresence_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UploadAttachment",
+			Handler:       _ChatService_UploadAttachment_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadAttachment",
+			Handler:       _ChatService_DownloadAttachment_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "grpchat.proto",
 }
-
-func init() { proto.RegisterFile("grpchat.proto", fileDescriptor0) }
-
-var fileDescriptor0 = []byte{
-	// 462 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0x5d, 0x8b, 0xd3, 0x40,
-	0x14, 0x6d, 0xdc, 0x7e, 0x6c, 0xef, 0x12, 0x77, 0x9d, 0x2d, 0x25, 0x46, 0x1f, 0x96, 0x41, 0x70,
-	0x71, 0x71, 0xeb, 0x07, 0xa8, 0x4f, 0x82, 0x54, 0x59, 0x90, 0xfa, 0x60, 0xc4, 0x1f, 0x90, 0xa6,
-	0x97, 0x34, 0x68, 0x67, 0xe2, 0xcc, 0xa4, 0xd2, 0x5f, 0xec, 0xdf, 0x90, 0xf9, 0x48, 0x9a, 0xa4,
-	0x11, 0xfb, 0x36, 0x67, 0xee, 0x39, 0xe7, 0xde, 0x39, 0x37, 0x04, 0xfc, 0x54, 0xe4, 0xc9, 0x3a,
-	0x56, 0xb7, 0xb9, 0xe0, 0x8a, 0x93, 0xbe, 0x3e, 0xd3, 0xaf, 0x30, 0xfa, 0x82, 0x52, 0xc6, 0x29,
-	0x12, 0x02, 0xfd, 0x25, 0x5f, 0xed, 0x02, 0xef, 0xca, 0xbb, 0x1e, 0x47, 0xe6, 0x4c, 0xa6, 0x30,
-	0x94, 0xc8, 0x56, 0x28, 0x82, 0x7b, 0xe6, 0xd6, 0x21, 0x12, 0xc2, 0xa9, 0xc0, 0x04, 0xb3, 0x2d,
-	0x8a, 0xe0, 0xc4, 0x54, 0x2a, 0x4c, 0x3f, 0x02, 0x99, 0xff, 0xcc, 0x90, 0xa9, 0x05, 0x4f, 0x33,
-	0x16, 0xe1, 0xaf, 0x02, 0xa5, 0xd2, 0x8a, 0x3c, 0x96, 0xf2, 0x37, 0x17, 0x2b, 0xd7, 0xa1, 0xc2,
-	0xba, 0x33, 0x8b, 0x37, 0xe8, 0x7a, 0x98, 0x33, 0xbd, 0x81, 0xcb, 0x86, 0x8b, 0xcc, 0x39, 0x93,
-	0x48, 0x26, 0x30, 0x50, 0xfc, 0x07, 0x32, 0xe7, 0x61, 0x41, 0x83, 0xcc, 0x0b, 0x55, 0xf6, 0xec,
-	0x26, 0x4f, 0x61, 0xd2, 0x24, 0x5b, 0x6b, 0xfa, 0x08, 0x06, 0xa6, 0x57, 0x35, 0x8e, 0x57, 0x1b,
-	0xe7, 0x31, 0x0c, 0x2d, 0xbd, 0xb3, 0xfa, 0x04, 0x60, 0xbe, 0x8e, 0x95, 0xb5, 0xad, 0x85, 0xe6,
-	0xd5, 0x43, 0xa3, 0x6f, 0x61, 0xac, 0x59, 0x77, 0x82, 0x17, 0xb9, 0x26, 0x25, 0x86, 0x5e, 0x92,
-	0x2c, 0xea, 0xcc, 0xe2, 0x29, 0xf8, 0x95, 0x70, 0x91, 0x49, 0xd3, 0x21, 0xd5, 0x40, 0x06, 0xde,
-	0xd5, 0x89, 0x16, 0x5b, 0x44, 0x9f, 0xc1, 0xfd, 0xfd, 0x1c, 0x86, 0x19, 0xc0, 0xc8, 0x1a, 0x97,
-	0xd4, 0x12, 0xd2, 0x11, 0x0c, 0x3e, 0x6d, 0x72, 0xb5, 0x7b, 0xf5, 0xa7, 0x0f, 0x67, 0x5a, 0xf5,
-	0x0d, 0xc5, 0x36, 0x4b, 0x90, 0xbc, 0x2f, 0x73, 0x08, 0x6e, 0xcd, 0xe7, 0x72, 0xb8, 0xcc, 0xf0,
-	0x61, 0x47, 0xc5, 0xa5, 0xd8, 0x23, 0x1f, 0xaa, 0xa8, 0xda, 0xb4, 0xfd, 0x6a, 0xc2, 0xb0, 0xab,
-	0x54, 0x59, 0xcc, 0x60, 0x1c, 0xf1, 0x42, 0xa1, 0x1e, 0x8b, 0xf8, 0x96, 0xea, 0x3e, 0xd3, 0xb0,
-	0x09, 0x69, 0xef, 0xda, 0x7b, 0xe1, 0x91, 0xe7, 0x00, 0xdf, 0x59, 0x84, 0x69, 0x26, 0x15, 0x0a,
-	0x72, 0xe1, 0xcc, 0xab, 0x28, 0xc2, 0x33, 0x7b, 0x63, 0x1e, 0x4c, 0x7b, 0xe4, 0x06, 0x4e, 0x8f,
-	0x27, 0xbf, 0x84, 0xf3, 0xb9, 0xc0, 0xd8, 0x4e, 0x63, 0x97, 0x77, 0xbe, 0xd7, 0x98, 0x8b, 0xb6,
-	0x64, 0x06, 0xfe, 0x67, 0x9e, 0xb1, 0xe3, 0x05, 0x6f, 0xe0, 0xe2, 0x0e, 0x55, 0x73, 0xc9, 0x75,
-	0x4a, 0x78, 0xd9, 0x32, 0xd0, 0x0c, 0x93, 0xf5, 0xb4, 0xae, 0xab, 0x2d, 0xfe, 0xa0, 0xe3, 0xa4,
-	0xfd, 0x4e, 0x67, 0xf1, 0x0e, 0x1e, 0x38, 0x8b, 0x9a, 0xba, 0xd1, 0xfb, 0x5f, 0xca, 0x19, 0xf8,
-	0x0b, 0x8c, 0xb7, 0x26, 0x97, 0x88, 0xf3, 0xcd, 0xff, 0x5e, 0xb9, 0x1c, 0x9a, 0x3f, 0xcf, 0xeb,
-	0xbf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x8e, 0x03, 0x32, 0x75, 0x8a, 0x04, 0x00, 0x00,
-}
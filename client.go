@@ -2,25 +2,511 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/baadjis/grpchat/chat"
+	"github.com/baadjis/grpchat/e2e"
+	"github.com/baadjis/grpchat/tokens"
 	"github.com/fatih/color"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
+// The metadata header carrying the session token, the number of messages shown when entering
+// a group, the bytes of an uploaded chunk and the bounds of the delay between two reconnection
+// attempts.
+const (
+	tokenHeader   = "x-chat-token"
+	historySize   = 20
+	chunkSize     = 64 << 10
+	minRetryDelay = 500 * time.Millisecond
+	maxRetryDelay = 30 * time.Second
+)
+
+// Session holds the credentials of the user and the token handed out by the server on Login.
+type Session struct {
+	lock     sync.RWMutex
+	name     string
+	password string
+	token    string
+	expires  time.Time
+	roster   *Roster // presence of the users, kept up to date by WatchPresence
+}
+
+// keep keeps the credentials of a successful login.
+func (s *Session) keep(res *chat.ClientLoginResponse, name string, password string) {
+	if res.Name != "" {
+		name = res.Name
+	}
+
+	s.lock.Lock()
+	s.name, s.password, s.token = name, password, res.Token
+	s.expires = time.Unix(0, res.Expires)
+	s.lock.Unlock()
+}
+
+// Login logs the user in with name and password and keeps them to log in again after a reconnection.
+// The server may log in the user under the name of its certificate instead.
+// It returns an error.
+func (s *Session) Login(c chat.ChatServiceClient, name string, password string) error {
+
+	res, err := c.Login(context.Background(), &chat.ClientLoginRequest{Name: name, Password: password})
+	if err != nil {
+		return err
+	}
+
+	s.keep(res, name, password)
+	return nil
+}
+
+// SignUp creates the account name with password and logs it in like Login.
+// It returns an error.
+func (s *Session) SignUp(c chat.ChatServiceClient, name string, password string) error {
+
+	res, err := c.SignUp(context.Background(), &chat.ClientLoginRequest{Name: name, Password: password})
+	if err != nil {
+		return err
+	}
+
+	s.keep(res, name, password)
+	return nil
+}
+
+// ChangePassword changes the password of the account and keeps the new one to log in again.
+// It returns an error.
+func (s *Session) ChangePassword(c chat.ChatServiceClient, old string, password string) error {
+
+	_, err := c.ChangePassword(context.Background(), &chat.PasswordChange{OldPassword: old, NewPassword: password})
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	s.password = password
+	s.lock.Unlock()
+	return nil
+}
+
+// Name returns the name the user logged in with.
+func (s *Session) Name() string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.name
+}
+
+// Relogin carries on the session after a reconnection: the token is refreshed while it is
+// valid, otherwise the user logs in again with the credentials of the last successful Login in
+// place of it. The device stays the same either way, with the messages waiting for it.
+// It returns an error.
+func (s *Session) Relogin(c chat.ChatServiceClient) error {
+
+	err := s.Refresh(c)
+	if err == nil || Revoked(err) {
+		return err
+	}
+
+	s.lock.RLock()
+	name, password, previous := s.name, s.password, s.token
+	s.lock.RUnlock()
+
+	res, err := c.Login(context.Background(), &chat.ClientLoginRequest{Name: name, Password: password, Previous: previous})
+	if err != nil {
+		return err
+	}
+	s.keep(res, name, password)
+	return nil
+}
+
+// Refresh swaps the session token for a new one before it expires.
+// It returns an error.
+func (s *Session) Refresh(c chat.ChatServiceClient) error {
+
+	res, err := c.RefreshToken(context.Background(), &chat.Empty{})
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	s.token, s.expires = res.Token, time.Unix(0, res.Expires)
+	s.lock.Unlock()
+	return nil
+}
+
+// KeepFresh refreshes the session token once four fifths of its lifetime went by, logging in
+// again if it can't.
+// It doesn't return anything.
+func (s *Session) KeepFresh(c chat.ChatServiceClient) {
+
+	for {
+		s.lock.RLock()
+		wait := time.Until(s.expires) * 4 / 5
+		s.lock.RUnlock()
+
+		if wait < minRetryDelay {
+			wait = minRetryDelay
+		}
+		time.Sleep(wait)
+
+		err := s.Refresh(c)
+		if err == nil {
+			continue
+		} else if Revoked(err) {
+			SessionEnded()
+		}
+		log.Printf("could not refresh the session: %v", err)
+		if err := s.Relogin(c); Revoked(err) {
+			SessionEnded()
+		} else if err != nil {
+			log.Printf("login failed: %v", err)
+			time.Sleep(maxRetryDelay)
+		}
+	}
+}
+
+// Logout ends the session, or every session of the user if all is set.
+// It returns an error.
+func (s *Session) Logout(c chat.ChatServiceClient, all bool) error {
+
+	_, err := c.Logout(context.Background(), &chat.ClientLogoutRequest{All: all})
+
+	s.lock.Lock()
+	s.token = ""
+	s.lock.Unlock()
+	return err
+}
+
+// Revoked checks if err tells the session was ended on the server, by a logout from another
+// device or by an admin. The client doesn't log in again by itself then.
+func Revoked(err error) bool {
+	st := status.Convert(err)
+	return st.Code() == codes.Unauthenticated && st.Message() == tokens.ErrRevoked.Error()
+}
+
+// SessionEnded tells the user their session was ended and exits.
+// It doesn't return anything.
+func SessionEnded() {
+
+	AddSpacing(1)
+	color.New(color.FgHiRed).Println("Your session was ended, please log in again.")
+	os.Exit(1)
+}
+
+// attach adds the session token to the outgoing metadata of ctx.
+func (s *Session) attach(ctx context.Context) context.Context {
+	s.lock.RLock()
+	tkn := s.token
+	s.lock.RUnlock()
+
+	if tkn == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, tokenHeader, tkn)
+}
+
+// UnaryInterceptor attaches the session token to every unary call.
+func (s *Session) UnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(s.attach(ctx), method, req, reply, cc, opts...)
+}
+
+// StreamInterceptor attaches the session token to every stream.
+func (s *Session) StreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(s.attach(ctx), desc, cc, method, opts...)
+}
+
+var errStreamClosed = errors.New("chat stream closed")
+
+// Room is what a chat session talks to: a group or a direct conversation with a peer.
+type Room struct {
+	Group        string
+	Peer         string
+	Conversation string
+}
+
+// Name returns the name the room is displayed with.
+func (r Room) Name() string {
+	if r.Conversation != "" {
+		return "@" + r.Peer
+	}
+	return r.Group
+}
+
+// Owns checks if msg was sent to the room.
+func (r Room) Owns(msg *chat.Message) bool {
+	if r.Conversation != "" {
+		return msg.Conversation == r.Conversation
+	}
+	return msg.Conversation == "" && msg.Receiver == r.Group
+}
+
+// ChatStream is the RouteChat stream of a chat, subscribed to its room and to the other rooms the
+// user follows meanwhile. When the connection drops it logs in again, reopens the stream and
+// subscribes again, asking the server to replay the messages it missed.
+type ChatStream struct {
+	lock    sync.Mutex
+	c       chat.ChatServiceClient
+	session *Session
+	stream  chat.ChatService_RouteChatClient
+	cancel  context.CancelFunc
+	closed  bool
+	user    string
+	room    Room
+	rooms   map[Room]int64 // rooms subscribed to, with the sequence number of the last message received
+
+	receipts *Receipts // how far the members of the room read, used by the chat loop only
+}
+
+// OpenChatStream opens a stream subscribed to room for user u, lastSeq is the sequence number of
+// the last message of the room the user has already seen.
+// It returns the stream and an error.
+func OpenChatStream(c chat.ChatServiceClient, session *Session, u string, room Room, lastSeq int64) (*ChatStream, error) {
+
+	cs := &ChatStream{c: c, session: session, user: u, room: room, rooms: map[Room]int64{room: lastSeq}}
+	if err := cs.open(false); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// SubscribeEvent returns the frame subscribing the stream to room. A resumeFrom greater than 0
+// asks the server to replay the messages from that sequence number.
+func SubscribeEvent(u string, room Room, resumeFrom int64) *chat.Message {
+	return &chat.Message{Sender: u, Receiver: room.Group, Conversation: room.Conversation, Event: &chat.Message_Subscribe{Subscribe: &chat.Subscribe{ResumeFrom: resumeFrom}}}
+}
+
+// open opens a RouteChat stream and subscribes it to the rooms. With resume, the server replays
+// the messages that came after the last one received from each room.
+// The stream lock must be held, or the stream not shared yet.
+func (cs *ChatStream) open(resume bool) error {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := cs.c.RouteChat(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	for room, last := range cs.rooms {
+		var from int64
+		if resume && last > 0 {
+			from = last + 1
+		}
+		if err := stream.Send(SubscribeEvent(cs.user, room, from)); err != nil {
+			cancel()
+			return err
+		}
+	}
+
+	cs.stream, cs.cancel = stream, cancel
+	return nil
+}
+
+// Subscribe adds room to the stream, its new messages come along with the ones of the chat.
+// It returns an error.
+func (cs *ChatStream) Subscribe(room Room) error {
+
+	cs.lock.Lock()
+	if _, ok := cs.rooms[room]; ok {
+		cs.lock.Unlock()
+		return nil
+	}
+	cs.rooms[room] = 0
+	cs.lock.Unlock()
+
+	return cs.Send(SubscribeEvent(cs.user, room, 0))
+}
+
+// Unsubscribe removes room from the stream, its messages wait for a later chat.
+// It returns an error.
+func (cs *ChatStream) Unsubscribe(room Room) error {
+
+	cs.lock.Lock()
+	if _, ok := cs.rooms[room]; !ok || room == cs.room {
+		cs.lock.Unlock()
+		return nil
+	}
+	delete(cs.rooms, room)
+	cs.lock.Unlock()
+
+	return cs.Send(&chat.Message{Sender: cs.user, Receiver: room.Group, Conversation: room.Conversation, Event: &chat.Message_Unsubscribe{Unsubscribe: &chat.Unsubscribe{}}})
+}
+
+// current returns the stream in use.
+func (cs *ChatStream) current() chat.ChatService_RouteChatClient {
+
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	return cs.stream
+}
+
+// rejoin registers the user, publishes its key and joins the group again in case the server lost
+// them on a restart. A group the server lost comes back from its history, a group that is gone
+// is only reported.
+func (cs *ChatStream) rejoin() {
+
+	ctx := context.Background()
+	cs.c.Register(ctx, &chat.ChatClient{Sender: cs.user})
+	keyring.Publish(cs.c)
+	if cs.room.Group == "" {
+		return
+	}
+
+	g := &chat.ChatGroup{Client: cs.user, Name: cs.room.Group}
+	if _, err := cs.c.JoinChatGroup(ctx, g); err != nil {
+		color.New(color.FgRed).Println("Could not join " + cs.room.Group + " again: " + status.Convert(err).Message())
+	}
+}
+
+// reconnect replaces the failed stream, retrying with an increasing delay until it succeeds or
+// the stream is closed. Nothing is done if another caller already replaced it.
+// It returns an error.
+func (cs *ChatStream) reconnect(failed chat.ChatService_RouteChatClient, err error) error {
+
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	if cs.closed {
+		return errStreamClosed
+	} else if cs.stream != failed {
+		return nil
+	}
+
+	cs.cancel()
+	if Revoked(err) {
+		SessionEnded()
+	}
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Println("Connection lost, reconnecting...")
+
+	for delay := minRetryDelay; ; delay *= 2 {
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+		time.Sleep(delay)
+
+		if err := cs.session.Relogin(cs.c); Revoked(err) {
+			SessionEnded()
+		} else if err != nil {
+			log.Printf("login failed: %v", err)
+			continue
+		}
+		cs.rejoin()
+
+		if err := cs.open(true); err != nil {
+			log.Printf("could not reopen stream: %v", err)
+			continue
+		}
+
+		color.New(color.FgHiGreen).Println("Reconnected to " + cs.room.Name() + ".")
+		return nil
+	}
+}
+
+// Send sends msg, waiting for the stream to be reopened if the connection dropped.
+// It returns an error.
+func (cs *ChatStream) Send(msg *chat.Message) error {
+
+	for {
+		stream := cs.current()
+		err := stream.Send(msg)
+		if err == nil {
+			return nil
+		}
+
+		if rerr := cs.reconnect(stream, err); rerr != nil {
+			return err
+		}
+	}
+}
+
+// Recv receives the next message, reopening the stream if the connection dropped.
+// Messages of the rooms already received are skipped.
+// It returns the message and an error.
+func (cs *ChatStream) Recv() (*chat.Message, error) {
+
+	for {
+		stream := cs.current()
+		msg, err := stream.Recv()
+		if err != nil {
+			if rerr := cs.reconnect(stream, err); rerr != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if cs.skip(msg) {
+			continue
+		}
+		return msg, nil
+	}
+}
+
+// skip keeps track of the last message received from each room, and of the rooms the user was
+// removed from.
+// It returns true if msg was already received.
+func (cs *ChatStream) skip(msg *chat.Message) bool {
+
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	for room, last := range cs.rooms {
+		if !room.Owns(msg) {
+			continue
+		}
+		if msg.GetLeave() != nil && msg.Sender == cs.user && room != cs.room {
+			delete(cs.rooms, room)
+		} else if msg.Seq > 0 {
+			if msg.Seq <= last {
+				return true
+			}
+			cs.rooms[room] = msg.Seq
+		}
+		return false
+	}
+	return false
+}
+
+// Close closes the stream for good.
+func (cs *ChatStream) Close() {
+
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	cs.closed = true
+	cs.stream.CloseSend()
+	cs.cancel()
+}
+
 type Listener struct {
 	MessageChanel    chan chat.Message
 	MessageWaitGroup *sync.WaitGroup
 	chattingState    bool
-	stream           chat.ChatService_RouteChatClient
+	stream           *ChatStream
 	SignalWaitGroup  *sync.WaitGroup
 	SignalChanel     chan os.Signal
 }
@@ -52,7 +538,7 @@ func (s *Listener) StopListeningMessage() {
 // Note: The routine control is dictated by the existence of a stream. If one is present, the user is in a group and needs
 // to be removed. Otherwise, the user is still in the menu system.
 // It doesn't return anything.
-func (s *Listener) ControlExit(c chat.ChatServiceClient, u string, g string) {
+func (s *Listener) ControlExit(c chat.ChatServiceClient, session *Session, u string, g string) {
 
 	log.Print("[ControlExit]: Entered.")
 
@@ -65,61 +551,144 @@ func (s *Listener) ControlExit(c chat.ChatServiceClient, u string, g string) {
 
 			if s.chattingState {
 				log.Print("I am still chatting.")
-				s.stream.Send(&chat.Message{Sender: u, Receiver: g, Body: u + " left chat!\n"})
-				ExitClient(c, u, g)
+				s.stream.Send(LeaveEvent(u, s.stream.room))
+				ExitClient(c, session, u, g)
 				return
 			}
 
 			log.Print("I am no longer chatting.")
-			ExitClient(c, u, g)
+			ExitClient(c, session, u, g)
 			os.Exit(1)
 			return
 		}
 	}
 }
 
-// ExitClient handles removing the client from the server and exiting the program.
+// ExitClient handles removing the client from the server, logging out and exiting the program.
 // It doesn't return anything.
-func ExitClient(c chat.ChatServiceClient, u string, g string) {
+func ExitClient(c chat.ChatServiceClient, session *Session, u string, g string) {
 
 	c.UnRegister(context.Background(), &chat.ChatClient{Sender: u})
+	session.Logout(c, false)
 	os.Exit(1)
 }
 
 // ListenToClient listens to the client for input and adds that input to the sQueue with
-// the username of the sender, group name, and the message.
+// the username of the sender, room, and the message.
 // It doesn't return anything.
-func ListenToClient(messagesQueue *Listener, reader *bufio.Reader, uName string, gName string) {
+func ListenToClient(messagesQueue *Listener, reader *bufio.Reader, uName string, room Room) {
 
 	log.Println("Start listening.")
 	defer messagesQueue.MessageWaitGroup.Done()
 
+	// a line ending with a backslash goes on on the next one, the others see the user typing
+	draft := ""
 	for {
 		msg, _ := reader.ReadString('\n')
 		if strings.TrimSpace(msg) == "!leave" {
 			log.Println("Stop listening chat.")
-			messagesQueue.MessageChanel <- chat.Message{Sender: uName, Body: msg, Receiver: gName}
+			if draft != "" {
+				messagesQueue.MessageChanel <- TypingEvent(uName, room, false)
+			}
+			messagesQueue.MessageChanel <- chat.Message{Sender: uName, Body: msg, Receiver: room.Group, Conversation: room.Conversation}
 			return
 		}
+		if line := strings.TrimRight(msg, "\r\n"); strings.HasSuffix(line, "\\") {
+			if draft == "" {
+				messagesQueue.MessageChanel <- TypingEvent(uName, room, true)
+			}
+			draft += strings.TrimSuffix(line, "\\") + "\n"
+			continue
+		}
 		log.Println("Adding message to the queue.")
-		messagesQueue.MessageChanel <- chat.Message{Sender: uName, Body: msg, Receiver: gName}
+		messagesQueue.MessageChanel <- chat.Message{Sender: uName, Body: draft + msg, Receiver: room.Group, Conversation: room.Conversation}
+		draft = ""
+	}
+}
+
+// TypingEvent returns the event telling the room the user started or stopped typing.
+func TypingEvent(u string, room Room, started bool) chat.Message {
+	return chat.Message{Sender: u, Receiver: room.Group, Conversation: room.Conversation, Event: &chat.Message_Typing{Typing: &chat.Typing{Started: started}}}
+}
+
+// LeaveEvent returns the event telling the room the user closed it.
+func LeaveEvent(u string, room Room) *chat.Message {
+	return &chat.Message{Sender: u, Receiver: room.Group, Conversation: room.Conversation, Event: &chat.Message_Leave{Leave: &chat.Leave{}}}
+}
+
+// ReadReceipt returns the event telling the room the user read its messages up to seq.
+func ReadReceipt(u string, room Room, seq int64) *chat.Message {
+	return &chat.Message{Sender: u, Receiver: room.Group, Conversation: room.Conversation, Event: &chat.Message_Read{Read: &chat.ReadReceipt{Seq: seq}}}
+}
+
+// Receipts keeps how far the members of a room read, to tell the user who saw its messages.
+type Receipts struct {
+	user string
+	read map[string]int64 // sequence number each member read up to
+	mine []int64          // sequence numbers of the messages of the user, oldest first
+}
+
+// NewReceipts returns the receipts of a room for user u.
+func NewReceipts(u string) *Receipts {
+	return &Receipts{user: u, read: make(map[string]int64)}
+}
+
+// Sent records that the message seq is one of the user.
+func (r *Receipts) Sent(seq int64) {
+	if len(r.mine) == 0 || seq > r.mine[len(r.mine)-1] {
+		r.mine = append(r.mine, seq)
+	}
+}
+
+// Update records that name read the room up to seq.
+// It returns the latest message of the user this receipt was the first from name to cover,
+// 0 if there is none.
+func (r *Receipts) Update(name string, seq int64) int64 {
+
+	old := r.read[name]
+	if seq <= old {
+		return 0
+	}
+	r.read[name] = seq
+	for i := len(r.mine) - 1; i >= 0 && r.mine[i] > old; i-- {
+		if r.mine[i] <= seq {
+			return r.mine[i]
+		}
+	}
+	return 0
+}
+
+// SeenBy returns the members other than the user who read the message seq, sorted.
+func (r *Receipts) SeenBy(seq int64) []string {
+
+	var l []string
+	for name, n := range r.read {
+		if name != r.user && n >= seq {
+			l = append(l, name)
+		}
 	}
+	sort.Strings(l)
+	return l
 }
 
 // ReceiveMessages listens on the client's (NOT the client's group) stream and adds any incoming
 // message to the client's inbox.
 // It doesn't return anything.
-func ReceiveMessages(inbox *Listener, stream chat.ChatService_RouteChatClient, u string) {
+func ReceiveMessages(inbox *Listener, stream *ChatStream, u string) {
 
 	log.Println("[ReceiveMessages]: Starting.")
 	defer inbox.MessageWaitGroup.Done()
 
 	for {
 		log.Println("listening for new messages.")
-		msg, _ := stream.Recv()
+		msg, err := stream.Recv()
+		if err != nil {
+			log.Println("Stream closed: " + err.Error())
+			return
+		}
 		log.Println("Received message: " + msg.Body)
-		if msg.Body == u+" left chat!\n" {
-			log.Println("Found special signal!")
+		if msg.GetLeave() != nil && msg.Sender == u && stream.room.Owns(msg) {
+			log.Println("Left the room.")
 			return
 		}
 
@@ -127,128 +696,1515 @@ func ReceiveMessages(inbox *Listener, stream chat.ChatService_RouteChatClient, u
 	}
 }
 
-// DisplayCurrentMembers displays the members who are currently in the group chat.
+// DisplayCurrentMembers displays the members who are currently in the group chat, with the
+// presence of those who are not online.
 // It doesn't return anything.
-func CurrentMembers(c chat.ChatServiceClient, g string) {
+func CurrentMembers(c chat.ChatServiceClient, roster *Roster, g string) {
 
 	m, _ := c.GetChatGroupClientList(context.Background(), &chat.ChatGroup{Name: g})
 	if len(m.Clients) > 0 {
 		fmt.Print("Current Members: ")
 		for i := 0; i < len(m.Clients); i++ {
+			name := m.Clients[i]
+			if label := PresenceLabel(roster.Get(name)); label != "" {
+				name += " (" + label + ")"
+			}
 			if i == len(m.Clients)-1 {
-				fmt.Print(m.Clients[i])
+				fmt.Print(name)
 			} else {
-				fmt.Print(m.Clients[i] + ", ")
+				fmt.Print(name + ", ")
 			}
 		}
 
 	}
 }
 
-//initialise message listener and start chatting
-
-func StartChat(stream chat.ChatService_RouteChatClient, c chat.ChatServiceClient, listener *Listener, r *bufio.Reader, u string, g string) (*Listener, *Listener) {
-
-	//t, cancel := context.WithCancel(ctx)
-	//stream, serr := c.RouteChat(t)
-	//defer cancel()
+// Keyring holds the key the device encrypts the direct messages of the user with, and the
+// fingerprints of the keys of the peers the user verified. Both are kept in a directory.
+type Keyring struct {
+	lock     sync.Mutex
+	user     string
+	key      *e2e.Key
+	file     string                     // where verified is kept
+	verified map[string]map[string]bool // fingerprints of the keys of each peer the user checked
+	known    map[string][][]byte        // keys of the devices of each user, as the server last gave them
+	clear    map[string]bool            // peers without a key the user agreed to send in clear to
+}
 
-	CurrentMembers(c, g)
+// errNoKey is returned by Seal for a peer without a key the user didn't agree to send in clear to.
+var errNoKey = errors.New("no key to encrypt with")
 
-	//if serr != nil {
-	//	fmt.Print(serr)
-	//} else {
-	sendingQueue := NewListener() // Creates the sQueue with a channel and waitgroup.
-	receivingQueue := NewListener()
+// keyring is the keyring of the user, nil if the direct messages are sent in clear.
+var keyring *Keyring
 
-	go ListenToClient(sendingQueue, r, u, g)
-	go ReceiveMessages(receivingQueue, stream, u)
+// OpenKeyring opens the keyring of user u in dir, the key is made the first time.
+// It returns the keyring and an error.
+func OpenKeyring(dir string, u string) (*Keyring, error) {
 
-	// TODO: Find out why the first message is always dropped so an empty message needn't be sent.
-	stream.Send(&chat.Message{Sender: u, Receiver: g, Body: ""})
-	stream.Send(&chat.Message{Sender: u, Receiver: g, Body: "joined chat!\n"})
-	listener.chattingState = true
-	listener.stream = stream
-	return sendingQueue, receivingQueue
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	key, err := e2e.LoadKey(filepath.Join(dir, u+".key"))
+	if err != nil {
+		return nil, err
+	}
 
+	k := &Keyring{user: u, key: key, file: filepath.Join(dir, u+".verified"), verified: make(map[string]map[string]bool), known: make(map[string][][]byte), clear: make(map[string]bool)}
+	b, err := ioutil.ReadFile(k.file)
+	if err == nil {
+		err = json.Unmarshal(b, &k.verified)
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
+	return k, err
 }
 
-// print help instruction
-func help() {
-	log.Println("!help.")
-	AddSpacing(1)
-	fmt.Println("The following commands are available to you: ")
-	color.New(color.FgHiYellow).Print("   !members")
-	fmt.Print(": Lists the current members in the group.")
-
-	AddSpacing(1)
-	color.New(color.FgHiYellow).Print("   !exit")
-	fmt.Println(": Leaves the chat server.")
-	AddSpacing(1)
+// Publish hands the public key of the device to the server for the peers to encrypt with.
+// It returns an error.
+func (k *Keyring) Publish(c chat.ChatServiceClient) error {
+	if k == nil {
+		return nil
+	}
+	_, err := c.PublishKey(context.Background(), &chat.PublicKey{Name: k.user, Key: k.key.Public()})
+	return err
 }
 
-func Chat(conn *grpc.ClientConn, stream chat.ChatService_RouteChatClient, c chat.ChatServiceClient, listener *Listener, r *bufio.Reader, u string, g string) bool {
-
-	sendingQueue, receivingQueue := StartChat(stream, c, listener, r, u, g)
-	AddSpacing(1)
-	fmt.Println("good chat with " + g + ".")
-	Frame()
-
-	for {
-		select {
-		case toSend := <-sendingQueue.MessageChanel:
-			switch msg := strings.TrimSpace(toSend.Body); msg {
-			case "!members":
-				log.Println("!members:")
-				CurrentMembers(c, g)
-			case "!leave":
-				log.Println("!leave.")
-				c.LeaveChatRoom(context.Background(), &chat.ChatGroup{Client: u, Name: g})
-				sendingQueue.StopListeningMessage()
+// Keys returns the public keys the devices of name published, and remembers them. The keys
+// remembered are kept when the server gives none, so that Seal can tell.
+// It returns the keys, none if name has no key, and an error.
+func (k *Keyring) Keys(c chat.ChatServiceClient, name string) ([][]byte, error) {
 
-				//stream.CloseSend()
-				log.Println("HEY LOOK")
-				//cancel()
-				log.Println(context.Canceled)
-				return true
-			case "!exit":
-				log.Println("!exit.")
-				stream.Send(&chat.Message{Sender: u, Receiver: g, Body: u + " left chat!\n"})
-				ExitClient(c, u, g)
-				//stream.CloseSend()
-				//cancel()
-				conn.Close()
-				return false
-			case "!help":
-				help()
+	res, err := c.GetKeys(context.Background(), &chat.PublicKey{Name: name})
+	if status.Code(err) != codes.NotFound && err != nil {
+		return nil, err
+	}
 
-			default:
-				log.Println("Sending the message.")
-				stream.Send(&toSend)
-			}
-		case received := <-receivingQueue.MessageChanel:
-			log.Println("Receiving the message.")
-			if received.Body != "!leave" {
-				
-				fmt.Printf("%s:%s> %s", g, received.Sender, received.Body)
-				
+	k.lock.Lock()
+	if len(res.GetKeys()) > 0 {
+		k.known[name] = res.GetKeys()
+	}
+	k.lock.Unlock()
+	return res.GetKeys(), nil
+}
 
-			}
+// hasKey checks if key is one of keys.
+func hasKey(keys [][]byte, key []byte) bool {
+	for _, k := range keys {
+		if bytes.Equal(k, key) {
+			return true
 		}
 	}
-
+	return false
 }
-func main() {
 
-	r := bufio.NewReader(os.Stdin)
+// Changed checks if key is not one of the keys of peer the user verified, if it verified any.
+func (k *Keyring) Changed(peer string, key []byte) bool {
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	fps, ok := k.verified[peer]
+	return ok && !fps[e2e.Fingerprint(key)]
+}
+
+// Trusted checks if key is one of the keys of the devices of name: one the user verified if it
+// verified any, or else one the server gives for name.
+func (k *Keyring) Trusted(c chat.ChatServiceClient, name string, key []byte) bool {
+
+	k.lock.Lock()
+	_, pinned := k.verified[name]
+	known := k.known[name]
+	k.lock.Unlock()
+
+	if pinned {
+		return !k.Changed(name, key)
+	}
+	if !hasKey(known, key) {
+		// a new device of name, or the server changed the keys since
+		known, _ = k.Keys(c, name)
+	}
+	return hasKey(known, key)
+}
+
+// Trust records the key of peer with fingerprint fp as verified.
+// It returns an error.
+func (k *Keyring) Trust(peer string, fp string) error {
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if k.verified[peer] == nil {
+		k.verified[peer] = make(map[string]bool)
+	}
+	k.verified[peer][fp] = true
+	b, err := json.MarshalIndent(k.verified, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(k.file, b, 0600)
+}
+
+// AllowClear records that the user agreed to send its messages to peer in clear while peer
+// has no key.
+// It doesn't return anything.
+func (k *Keyring) AllowClear(peer string) {
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.clear[peer] = true
+}
+
+// sealedData returns what a sealed message authenticates along with its body, who sent it to
+// whom, so that the server can't pass it off as another one.
+func sealedData(sender string, receiver string) []byte {
+	return []byte(sender + "\x00" + receiver)
+}
+
+// Seal encrypts the body of msg for every device of peer and of the user. Nothing is done
+// without a keyring, in a group, where peer is empty, or if peer has no key and the user agreed
+// to send in clear to it, see AllowClear.
+// It returns an error, errNoKey if peer has no key, msg must not be sent then.
+func (k *Keyring) Seal(c chat.ChatServiceClient, peer string, msg *chat.Message) error {
+
+	if k == nil || peer == "" || msg.Body == "" {
+		return nil
+	}
+	k.lock.Lock()
+	_, pinned := k.verified[peer]
+	had := len(k.known[peer]) > 0
+	allowed := k.clear[peer]
+	k.lock.Unlock()
+
+	keys, err := k.Keys(c, peer)
+	if err != nil {
+		return err
+	} else if len(keys) == 0 {
+		// a peer doesn't lose its keys, the server giving none wants to read the message
+		if pinned || had {
+			return errors.New(peer + " had keys and the server gives none now, the message is not sent in clear")
+		} else if !allowed {
+			return errNoKey
+		}
+		return nil
+	}
+	mine, err := k.Keys(c, k.user)
+	if err != nil {
+		return err
+	}
+	if !hasKey(mine, k.key.Public()) {
+		mine = append(mine, k.key.Public())
+	}
+
+	sealed := &chat.Sealed{SenderKey: k.key.Public()}
+	for _, key := range append(keys, mine...) {
+		b, err := k.key.Seal(key, []byte(msg.Body), sealedData(msg.Sender, peer))
+		if err != nil {
+			return err
+		}
+		sealed.Copies = append(sealed.Copies, &chat.SealedCopy{Key: key, Ciphertext: b})
+	}
+	msg.Body = ""
+	msg.Sealed = sealed
+	return nil
+}
+
+// Open decrypts the body of the sealed message m, one of the user or of its peer.
+// It returns the body and false if it can't be decrypted or it was sealed with a key that is
+// not one of its sender, see Trusted.
+func (k *Keyring) Open(c chat.ChatServiceClient, m *chat.Message) (string, bool) {
+
+	b := m.Sealed
+	if k == nil {
+		return "(encrypted)\n", false
+	}
+	var sealed []byte
+	for _, cp := range b.Copies {
+		if bytes.Equal(cp.Key, k.key.Public()) {
+			sealed = cp.Ciphertext
+		}
+	}
+	if sealed == nil {
+		return "(encrypted for another device of yours)\n", false
+	}
+	data, err := k.key.Open(b.SenderKey, sealed, sealedData(m.Sender, m.Receiver))
+	if err != nil {
+		return "(could not decrypt: " + err.Error() + ")\n", false
+	}
+	return string(data), k.Trusted(c, m.Sender, b.SenderKey)
+}
+
+// CheckPeer warns the user when its direct messages to peer are sent in clear, or when peer
+// has a key the user didn't verify though it verified others.
+// It doesn't return anything.
+func (k *Keyring) CheckPeer(c chat.ChatServiceClient, peer string) {
+
+	if k == nil {
+		color.New(color.FgHiBlack).Println("Your messages to " + peer + " are not encrypted, run the client with -keys to encrypt them.")
+		return
+	}
+	keys, err := k.Keys(c, peer)
+	if err != nil {
+		color.New(color.FgRed).Println("Could not get the keys of " + peer + ": " + status.Convert(err).Message())
+		return
+	} else if len(keys) == 0 {
+		color.New(color.FgHiBlack).Println("Your messages to " + peer + " are not encrypted, " + peer + " has no key.")
+		return
+	}
+	for _, key := range keys {
+		if k.Changed(peer, key) {
+			color.New(color.FgRed, color.Bold).Println("⚠ " + peer + " has a key you didn't verify, check it with !verify.")
+			return
+		}
+	}
+}
+
+// DefaultKeyDir returns the directory the keys are kept in by default, in the home directory of
+// the user, empty if it has none.
+func DefaultKeyDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".grpchat")
+}
+
+// Verify handles !verify [fingerprint] in the room. It shows the fingerprints of the keys of the
+// devices of the user and of the peer, to compare with the peer by other means, and records a
+// key of the peer as verified once given the fingerprint the peer reads out.
+// It doesn't return anything.
+func Verify(c chat.ChatServiceClient, room Room, arg string) {
+
+	k := keyring
+	if room.Conversation == "" {
+		color.New(color.FgRed).Println("Keys are verified in direct conversations only.")
+		return
+	} else if k == nil {
+		color.New(color.FgRed).Println("Encryption is off, run the client with -keys to turn it on.")
+		return
+	}
+
+	keys, err := k.Keys(c, room.Peer)
+	if err != nil {
+		color.New(color.FgRed).Println("Could not get the keys of " + room.Peer + ": " + status.Convert(err).Message())
+		return
+	} else if len(keys) == 0 {
+		color.New(color.FgRed).Println(room.Peer + " has no key, your messages to them are not encrypted.")
+		return
+	}
+
+	fmt.Println("Your key: " + e2e.Fingerprint(k.key.Public()))
+	mine, _ := k.Keys(c, k.user)
+	for _, key := range mine {
+		if !bytes.Equal(key, k.key.Public()) {
+			fmt.Println("Your other device: " + e2e.Fingerprint(key))
+		}
+	}
+
+	k.lock.Lock()
+	_, pinned := k.verified[room.Peer]
+	k.lock.Unlock()
+
+	compact := func(s string) string { return strings.ToLower(strings.Join(strings.Fields(s), "")) }
+	match, unverified := "", 0
+	for _, key := range keys {
+		fp := e2e.Fingerprint(key)
+		fmt.Print("A key of " + room.Peer + ": " + fp)
+		if arg != "" && compact(arg) == compact(fp) {
+			match = fp
+		}
+		if pinned && !k.Changed(room.Peer, key) {
+			color.New(color.FgHiGreen).Println(" ✓")
+		} else {
+			unverified++
+			fmt.Println()
+		}
+	}
+
+	switch {
+	case arg != "" && match == "":
+		color.New(color.FgRed, color.Bold).Println("⚠ That is not the fingerprint of a key the server gave for " + room.Peer + ", don't trust it.")
+	case arg != "":
+		if err := k.Trust(room.Peer, match); err != nil {
+			color.New(color.FgRed).Println("Could not save it: " + err.Error())
+			return
+		}
+		color.New(color.FgHiGreen).Println("✓ That key of " + room.Peer + " is verified.")
+	case pinned && unverified == 0:
+		color.New(color.FgHiGreen).Println("✓ You verified every key of " + room.Peer + ".")
+	case pinned:
+		color.New(color.FgRed, color.Bold).Println("⚠ " + room.Peer + " has a key you didn't verify, a new device or someone else. Compare it with " + room.Peer + " again.")
+	default:
+		color.New(color.FgHiBlack).Println("Compare them with " + room.Peer + " in person or on a call, then type !verify followed by each of theirs.")
+	}
+}
+
+// shortID is how many characters of a message id are shown, enough to tell the messages of a
+// room apart.
+const shortID = 6
+
+// Short returns the start of the message id shown, all of it if it is shorter.
+func Short(id string) string {
+	if len(id) > shortID {
+		return id[:shortID]
+	}
+	return id
+}
+
+// PrintMessage displays a received message or notice prefixed with the time the server got it
+// and the start of its id, under the message it replies to. Messages naming user u stand out,
+// encrypted ones are decrypted behind a lock, or a warning if their key can't be trusted.
+// It doesn't return anything.
+func PrintMessage(c chat.ChatServiceClient, m *chat.Message, u string) {
+
+	if q := m.Quote; q != nil {
+		color.New(color.FgHiBlack).Println("         ┌ " + q.Sender + ": " + q.Body)
+	}
+	t := time.Unix(0, m.Timestamp)
+	color.New(color.FgHiBlack).Print(t.Format("15:04:05") + " ")
+	if n := m.GetNotice(); n != nil {
+		color.New(color.FgHiYellow).Println(m.Receiver + ": " + n.Text)
+		return
+	}
+
+	if m.Id != "" {
+		color.New(color.FgHiBlack).Print(Short(m.Id) + " ")
+	}
+	body, trusted := m.Body, true
+	if m.Sealed != nil && !m.Deleted {
+		body, trusted = keyring.Open(c, m)
+	}
+	shared := m.Attachment != nil && !m.Deleted
+	if shared && body == "" {
+		color.New(color.FgHiCyan).Println(SharedFile(m))
+		if len(m.Reactions) > 0 {
+			color.New(color.FgHiBlack).Println("         " + ReactionCounts(m.Reactions))
+		}
+		return
+	}
+	if m.Conversation != "" {
+		fmt.Printf("@%s> ", m.Sender)
+	} else {
+		fmt.Printf("%s:%s> ", m.Receiver, m.Sender)
+	}
+	show := fmt.Print
+	for _, name := range m.Mentions {
+		if name == u {
+			show = color.New(color.FgHiMagenta, color.Bold).Print
+		}
+	}
+	if m.Sealed != nil && !m.Deleted && trusted {
+		color.New(color.FgHiBlack).Print("🔒 ")
+	} else if m.Sealed != nil && !m.Deleted {
+		color.New(color.FgRed, color.Bold).Print("⚠ ")
+	}
+	switch {
+	case m.Deleted:
+		color.New(color.FgHiBlack).Println("(deleted)")
+	case m.Edited != 0:
+		show(strings.TrimRight(body, "\n"))
+		color.New(color.FgHiBlack).Println(" (edited)")
+	default:
+		show(body)
+	}
+	if shared {
+		color.New(color.FgHiCyan).Println("         " + SharedFile(m))
+	}
+	if len(m.Reactions) > 0 {
+		color.New(color.FgHiBlack).Println("         " + ReactionCounts(m.Reactions))
+	}
+}
+
+// SharedFile returns the line telling the file attached to m and how to get it.
+func SharedFile(m *chat.Message) string {
+	a := m.Attachment
+	return fmt.Sprintf("%s shared %s (%s) — !get %s", m.Sender, a.Name, FileSize(a.Size), Short(a.Id))
+}
+
+// FileSize returns n bytes in the largest unit that keeps it above 1.
+func FileSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%d KB", n>>10)
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// Upload sends the file at path to the server in chunks.
+// It returns the attachment to put in a message and an error.
+func Upload(c chat.ChatServiceClient, path string) (*chat.Attachment, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	stream, err := c.UploadAttachment(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	chunk := &chat.AttachmentChunk{Sha256: hex.EncodeToString(h.Sum(nil))}
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 || chunk.Sha256 != "" {
+			chunk.Data = buf[:n]
+			if err := stream.Send(chunk); err != nil {
+				break // the server tells why in CloseAndRecv
+			}
+			chunk = &chat.AttachmentChunk{}
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			stream.CloseSend()
+			return nil, err
+		}
+	}
+
+	a, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+	a.Name = filepath.Base(path)
+	return a, nil
+}
+
+// Attach handles !attach <path> [text] in the room, it uploads the file.
+// It returns the message sharing it, nil if there is none.
+func Attach(c chat.ChatServiceClient, u string, room Room, arg string) *chat.Message {
+
+	path, text := SplitCommand(arg)
+	if path == "" {
+		color.New(color.FgRed).Println("Usage: !attach <path> [text]")
+		return nil
+	}
+
+	color.New(color.FgHiBlack).Println("Uploading " + filepath.Base(path) + "...")
+	a, err := Upload(c, path)
+	if err != nil {
+		color.New(color.FgRed).Println("Could not upload: " + status.Convert(err).Message())
+		return nil
+	}
+
+	msg := &chat.Message{Sender: u, Receiver: room.Group, Conversation: room.Conversation, Attachment: a}
+	if text != "" {
+		msg.Body = text + "\n"
+	}
+	return msg
+}
+
+// Get handles !get <id> [path] in the room, it saves the file shared in a recent message of the
+// room whose attachment id starts with id. The file keeps its name in the current directory
+// unless path is set, an existing file is never overwritten.
+// It doesn't return anything.
+func Get(c chat.ChatServiceClient, u string, room Room, arg string) {
+
+	prefix, path := SplitCommand(arg)
+	if prefix == "" {
+		color.New(color.FgRed).Println("Usage: !get <id> [path]")
+		return
+	}
+
+	req := &chat.HistoryRequest{Limit: 100, Conversation: room.Conversation}
+	if room.Group != "" {
+		req.Group = &chat.ChatGroup{Client: u, Name: room.Group}
+	}
+	h, err := c.GetHistory(context.Background(), req)
+	if err != nil {
+		color.New(color.FgRed).Println(status.Convert(err).Message())
+		return
+	}
+	var found *chat.Message
+	for _, m := range h.Messages {
+		if a := m.Attachment; a != nil && !m.Deleted && strings.HasPrefix(a.Id, prefix) {
+			if found != nil && found.Attachment.Id != a.Id {
+				color.New(color.FgRed).Println("Several files start with " + prefix + ", give more of the id.")
+				return
+			}
+			found = m
+		}
+	}
+	if found == nil {
+		color.New(color.FgRed).Println("No recent file " + prefix + " in " + room.Name() + ".")
+		return
+	}
+	if path == "" {
+		path = found.Attachment.Name
+	}
+
+	if err := Download(c, room, found, path); err != nil {
+		color.New(color.FgRed).Println("Could not get " + found.Attachment.Name + ": " + status.Convert(err).Message())
+		return
+	}
+	color.New(color.FgHiGreen).Println("Saved " + path + " (" + FileSize(found.Attachment.Size) + ").")
+}
+
+// Download saves the file attached to m, a message of the room, at path. The file is removed
+// again if it doesn't match the checksum.
+// It returns an error.
+func Download(c chat.ChatServiceClient, room Room, m *chat.Message, path string) error {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := c.DownloadAttachment(ctx, &chat.AttachmentRequest{Group: room.Group, Conversation: room.Conversation, Message: m.Id})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	h := sha256.New()
+	var sum string
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			f.Close()
+			os.Remove(path)
+			return err
+		}
+		if chunk.Sha256 != "" {
+			sum = chunk.Sha256
+		}
+		h.Write(chunk.Data)
+		if _, err := f.Write(chunk.Data); err != nil {
+			f.Close()
+			os.Remove(path)
+			return err
+		}
+	}
+
+	err = f.Close()
+	if err == nil && hex.EncodeToString(h.Sum(nil)) != sum {
+		err = errors.New("the file doesn't match its checksum")
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// searchPage is how many messages a search shows at once.
+const searchPage = 10
+
+// Search handles !search <words> [from:<name>] [in:<group>] [days:<n>], it displays the latest
+// messages of the groups and conversations of the user containing all the words. Without
+// arguments it goes on with the previous search, req is the search shown last.
+// It returns the search to go on with, nil once it is over.
+func Search(c chat.ChatServiceClient, u string, arg string, req *chat.SearchRequest) *chat.SearchRequest {
+
+	if arg != "" {
+		req = &chat.SearchRequest{Limit: searchPage}
+		var words []string
+		for _, w := range strings.Fields(arg) {
+			switch {
+			case strings.HasPrefix(w, "from:"):
+				req.Sender = strings.TrimPrefix(w, "from:")
+			case strings.HasPrefix(w, "in:"):
+				req.Group = strings.TrimPrefix(w, "in:")
+			case strings.HasPrefix(w, "days:"):
+				days, err := strconv.Atoi(strings.TrimPrefix(w, "days:"))
+				if err != nil || days <= 0 {
+					color.New(color.FgRed).Println("Usage: !search <words> [from:<name>] [in:<group>] [days:<n>]")
+					return nil
+				}
+				req.Since = time.Now().Add(-time.Duration(days) * 24 * time.Hour).UnixNano()
+			default:
+				words = append(words, w)
+			}
+		}
+		req.Query = strings.Join(words, " ")
+	} else if req == nil {
+		color.New(color.FgRed).Println("Usage: !search <words> [from:<name>] [in:<group>] [days:<n>]")
+		return nil
+	}
+
+	res, err := c.SearchMessages(context.Background(), req)
+	if err != nil {
+		color.New(color.FgRed).Println(status.Convert(err).Message())
+		return nil
+	} else if len(res.Messages) == 0 {
+		color.New(color.FgHiBlack).Println("No messages found.")
+		return nil
+	}
+
+	for _, m := range res.Messages {
+		PrintMessage(c, m, u)
+	}
+	if res.Next == 0 {
+		Frame()
+		return nil
+	}
+	color.New(color.FgHiBlack).Println("!search again for older messages.")
+	Frame()
+	next := *req
+	next.Before = res.Next
+	return &next
+}
+
+// ShowMentions handles !mentions, it displays the latest messages naming the user in its groups.
+// It doesn't return anything.
+func ShowMentions(c chat.ChatServiceClient, u string) {
+
+	l, err := c.ListMentions(context.Background(), &chat.Empty{})
+	if err != nil {
+		color.New(color.FgRed).Println(status.Convert(err).Message())
+		return
+	} else if len(l.Messages) == 0 {
+		color.New(color.FgHiBlack).Println("Nobody mentioned you yet.")
+		return
+	}
+
+	color.New(color.FgHiBlack).Println("Mentions:")
+	for _, m := range l.Messages {
+		PrintMessage(c, m, u)
+	}
+	Frame()
+}
+
+// ReactionCounts returns the reactions to a message with how many members chose each one.
+func ReactionCounts(l []*chat.Reaction) string {
+	counts := make([]string, len(l))
+	for i, r := range l {
+		counts[i] = r.Emoji + " " + strconv.Itoa(len(r.Senders))
+	}
+	return strings.Join(counts, "  ")
+}
+
+// React handles !react <id> <emoji> in the room, it adds the reaction of the user to the message
+// or takes it back if it is already there.
+// It doesn't return anything.
+func React(c chat.ChatServiceClient, u string, room Room, arg string) {
+
+	prefix, emoji := SplitCommand(arg)
+	if prefix == "" || emoji == "" {
+		color.New(color.FgRed).Println("Usage: !react <id> <emoji>")
+		return
+	}
+
+	m, err := FindMessage(c, u, room, prefix)
+	if err == nil {
+		req := &chat.ReactionRequest{Group: room.Group, Conversation: room.Conversation, Id: m.Id, Emoji: emoji}
+		mine := false
+		for _, r := range m.Reactions {
+			if r.Emoji == emoji {
+				for _, name := range r.Senders {
+					mine = mine || name == u
+				}
+			}
+		}
+		if mine {
+			_, err = c.RemoveReaction(context.Background(), req)
+		} else {
+			_, err = c.AddReaction(context.Background(), req)
+		}
+	}
+	if err != nil {
+		color.New(color.FgRed).Println(status.Convert(err).Message())
+	}
+}
+
+// FindMessage looks for the message of the room whose id starts with prefix among the latest
+// ones.
+// It returns the message and an error.
+func FindMessage(c chat.ChatServiceClient, u string, room Room, prefix string) (*chat.Message, error) {
+
+	req := &chat.HistoryRequest{Limit: 100, Conversation: room.Conversation}
+	if room.Group != "" {
+		req.Group = &chat.ChatGroup{Client: u, Name: room.Group}
+	}
+	h, err := c.GetHistory(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *chat.Message
+	for _, m := range h.Messages {
+		if !strings.HasPrefix(m.Id, prefix) || m.Deleted || m.Event != nil {
+			continue
+		}
+		if found != nil {
+			return nil, errors.New("several messages start with " + prefix + ", give more of the id")
+		}
+		found = m
+	}
+	if found == nil {
+		return nil, errors.New("no recent message " + prefix + " in " + room.Name())
+	}
+	return found, nil
+}
+
+// Reply handles !reply <id> <text> in the room.
+// It returns the reply to send, nil if there is none.
+func Reply(c chat.ChatServiceClient, u string, room Room, arg string) *chat.Message {
+
+	prefix, text := SplitCommand(arg)
+	if prefix == "" || text == "" {
+		color.New(color.FgRed).Println("Usage: !reply <id> <text>")
+		return nil
+	}
+
+	m, err := FindMessage(c, u, room, prefix)
+	if err != nil {
+		color.New(color.FgRed).Println(status.Convert(err).Message())
+		return nil
+	}
+	return &chat.Message{Sender: u, Receiver: room.Group, Conversation: room.Conversation, Body: text + "\n", ReplyTo: m.Id}
+}
+
+// ShowThread handles !thread <id> in the room, it displays the thread of the message.
+// It doesn't return anything.
+func ShowThread(c chat.ChatServiceClient, u string, room Room, arg string) {
+
+	prefix, _ := SplitCommand(arg)
+	if prefix == "" {
+		color.New(color.FgRed).Println("Usage: !thread <id>")
+		return
+	}
+
+	found, err := FindMessage(c, u, room, prefix)
+	var t *chat.MessageList
+	if err == nil {
+		req := &chat.HistoryRequest{Limit: historySize, Conversation: room.Conversation, Thread: found.Id}
+		if room.Group != "" {
+			req.Group = &chat.ChatGroup{Client: u, Name: room.Group}
+		}
+		t, err = c.GetThread(context.Background(), req)
+	}
+	if err != nil {
+		color.New(color.FgRed).Println(status.Convert(err).Message())
+		return
+	}
+
+	color.New(color.FgHiBlack).Println("Thread:")
+	for _, m := range t.Messages {
+		// the thread is the quote
+		m.Quote = nil
+		PrintMessage(c, m, u)
+	}
+	Frame()
+}
+
+// ChangeMessage handles !edit <id> <text> and !delete <id> in the room.
+// It doesn't return anything.
+func ChangeMessage(c chat.ChatServiceClient, u string, room Room, cmd string, arg string) {
+
+	prefix, text := SplitCommand(arg)
+	if prefix == "" || (cmd == "!edit" && text == "") {
+		usage := "Usage: " + cmd + " <id>"
+		if cmd == "!edit" {
+			usage += " <text>"
+		}
+		color.New(color.FgRed).Println(usage)
+		return
+	}
+
+	m, err := FindMessage(c, u, room, prefix)
+	if err == nil && cmd == "!edit" && m.Sealed != nil {
+		color.New(color.FgRed).Println("An encrypted message can't be edited, delete it and send it again.")
+		return
+	}
+	if err == nil {
+		req := &chat.Message{Receiver: room.Group, Conversation: room.Conversation, Id: m.Id}
+		if cmd == "!edit" {
+			req.Body = text + "\n"
+			_, err = c.EditMessage(context.Background(), req)
+		} else {
+			_, err = c.DeleteMessage(context.Background(), req)
+		}
+	}
+	if err != nil {
+		color.New(color.FgRed).Println(status.Convert(err).Message())
+	}
+}
+
+// ShowHistory displays the last n messages of the room, with how many members saw the ones of
+// the user, and keeps the read positions in receipts.
+// It returns the sequence number of the last message shown.
+func ShowHistory(c chat.ChatServiceClient, u string, room Room, n int, receipts *Receipts) int64 {
+
+	req := &chat.HistoryRequest{Limit: int32(n), Conversation: room.Conversation}
+	if room.Group != "" {
+		req.Group = &chat.ChatGroup{Client: u, Name: room.Group}
+	}
+	h, err := c.GetHistory(context.Background(), req)
+	if err != nil {
+		return 0
+	}
+	for name, seq := range h.Read {
+		receipts.Update(name, seq)
+	}
+	if len(h.Messages) == 0 {
+		return 0
+	}
+
+	color.New(color.FgHiBlack).Println("Last messages:")
+	for _, m := range h.Messages {
+		PrintMessage(c, m, u)
+		if m.Sender == u {
+			receipts.Sent(m.Seq)
+			if seen := receipts.SeenBy(m.Seq); len(seen) > 0 {
+				color.New(color.FgHiBlack).Println("  ✓ seen by " + strings.Join(seen, ", "))
+			}
+		}
+	}
+	Frame()
+	return h.Messages[len(h.Messages)-1].Seq
+}
+
+//initialise message listener and start chatting
+
+func StartChat(c chat.ChatServiceClient, session *Session, listener *Listener, r *bufio.Reader, u string, room Room) (*ChatStream, *Listener, *Listener, error) {
+
+	receipts := NewReceipts(u)
+	last := ShowHistory(c, u, room, historySize, receipts)
+	if room.Group != "" {
+		CurrentMembers(c, session.roster, room.Group)
+	} else {
+		if label := PresenceLabel(session.roster.Get(room.Peer)); label != "" {
+			color.New(color.FgHiBlack).Println(room.Peer + " is " + label)
+		}
+		keyring.CheckPeer(c, room.Peer)
+	}
+
+	stream, err := OpenChatStream(c, session, u, room, last)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stream.receipts = receipts
+	if last > 0 {
+		stream.Send(ReadReceipt(u, room, last))
+	}
+	// direct messages show up in any chat
+	if l, err := c.ListConversations(context.Background(), &chat.Empty{}); err == nil {
+		for _, conv := range l.Conversations {
+			stream.Subscribe(Room{Peer: conv.Peer, Conversation: conv.Id})
+		}
+	}
+
+	sendingQueue := NewListener() // Creates the sQueue with a channel and waitgroup.
+	receivingQueue := NewListener()
+
+	go ListenToClient(sendingQueue, r, u, room)
+	go ReceiveMessages(receivingQueue, stream, u)
+
+	listener.chattingState = true
+	listener.stream = stream
+	return stream, sendingQueue, receivingQueue, nil
+
+}
+
+// print help instruction
+func help() {
+	log.Println("!help.")
+	AddSpacing(1)
+	fmt.Println("The following commands are available to you: ")
+	color.New(color.FgHiYellow).Print("   !members")
+	fmt.Print(": Lists the current members in the group.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !invite <name>")
+	fmt.Print(": Invites someone to the group.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !kick <name>")
+	fmt.Print(": Removes someone from the group, admins only.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !ban <name>, !unban <name>")
+	fmt.Print(": Keeps someone out of the group or lets them back, admins only.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !mute <name> [minutes], !unmute <name>")
+	fmt.Print(": Keeps someone from talking in the group, admins only.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !promote <name>, !demote <name>, !transfer <name>")
+	fmt.Print(": Manages the admins and the owner of the group, owner only.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   a line ending with \\")
+	fmt.Print(": Goes on on the next line, the others see you typing meanwhile.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !status online|away|busy [text]")
+	fmt.Print(": Sets what the others see of you, busy means do not disturb.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !reply <id> <text>, !thread <id>")
+	fmt.Print(": Answers a message under a quote of it, or shows all the answers of its thread.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !attach <path> [text], !get <id> [path]")
+	fmt.Print(": Shares a file with the room, or saves a file shared in it.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !search <words> [from:<name>] [in:<group>] [days:<n>]")
+	fmt.Print(": Finds the latest messages with all the words in your groups and conversations, !search alone shows older ones.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !verify [fingerprint]")
+	fmt.Print(": Shows the fingerprints of the keys of the devices in the conversation, or marks a key of the peer as verified.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !clear")
+	fmt.Print(": Sends your messages in clear to a peer who has no key, never to one who had keys.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   @name, !mentions")
+	fmt.Print(": Calls a member of the group wherever they are, or lists the messages calling you.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !react <id> <emoji>")
+	fmt.Print(": Reacts to a message, or takes your reaction back.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !edit <id> <text>, !delete <id>")
+	fmt.Print(": Changes or removes one of your messages, admins can remove any message of the group.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !watch <group>, !unwatch <group>")
+	fmt.Print(": Shows the messages of another of your groups in this chat, or stops.")
+
+	AddSpacing(1)
+	color.New(color.FgHiYellow).Print("   !exit")
+	fmt.Println(": Leaves the chat server.")
+	AddSpacing(1)
+}
+
+// SplitCommand splits a chat line into its first word and the rest of the line.
+// It returns the command and its argument.
+func SplitCommand(line string) (string, string) {
+
+	line = strings.TrimSpace(line)
+	i := strings.IndexAny(line, " \t")
+	if i < 0 {
+		return line, ""
+	}
+	return line[:i], strings.TrimSpace(line[i+1:])
+}
+
+// InviteToRoom invites someone to the group of the chat.
+// It doesn't return anything.
+func InviteToRoom(c chat.ChatServiceClient, room Room, invitee string) {
+
+	if room.Group == "" {
+		color.New(color.FgRed).Println("Only groups take invitations.")
+		return
+	} else if invitee == "" {
+		color.New(color.FgRed).Println("Usage: !invite <name>")
+		return
+	}
+
+	_, err := c.SendInvitation(context.Background(), &chat.Invitation{Invitee: invitee, Group: room.Group})
+	if err != nil {
+		color.New(color.FgRed).Println("Could not invite " + invitee + ": " + status.Convert(err).Message())
+		return
+	}
+	color.New(color.FgGreen).Println("Invited " + invitee + " to " + room.Group + ".")
+}
+
+// Moderate runs a moderation command of the chat on a member of the group.
+// It doesn't return anything.
+func Moderate(c chat.ChatServiceClient, room Room, cmd string, arg string) {
+
+	if room.Group == "" {
+		color.New(color.FgRed).Println("Only groups can be moderated.")
+		return
+	}
+
+	member, rest := SplitCommand(arg)
+	if member == "" {
+		usage := "Usage: " + cmd + " <name>"
+		if cmd == "!mute" {
+			usage += " [minutes]"
+		}
+		color.New(color.FgRed).Println(usage)
+		return
+	}
+
+	req := &chat.ModerationRequest{Group: room.Group, Member: member}
+	var err error
+	var done string
+	switch cmd {
+	case "!kick":
+		_, err = c.KickMember(context.Background(), req)
+		done = member + " was kicked."
+	case "!ban", "!unban":
+		req.Lift = cmd == "!unban"
+		_, err = c.BanMember(context.Background(), req)
+		done = member + " was " + cmd[1:] + "ned."
+	case "!mute", "!unmute":
+		if rest != "" {
+			minutes, perr := strconv.Atoi(rest)
+			if perr != nil || minutes <= 0 {
+				color.New(color.FgRed).Println("Usage: !mute <name> [minutes]")
+				return
+			}
+			req.Duration = int64(minutes) * 60
+		}
+		req.Lift = cmd == "!unmute"
+		_, err = c.MuteMember(context.Background(), req)
+		done = member + " was " + cmd[1:] + "d."
+	case "!promote", "!demote":
+		req.Lift = cmd == "!demote"
+		_, err = c.PromoteMember(context.Background(), req)
+		done = member + " was " + cmd[1:] + "d."
+	case "!transfer":
+		_, err = c.TransferOwnership(context.Background(), req)
+		done = member + " now owns " + room.Group + "."
+	}
+
+	if err != nil {
+		color.New(color.FgRed).Println("Could not " + cmd[1:] + " " + member + ": " + status.Convert(err).Message())
+		return
+	}
+	color.New(color.FgGreen).Println(done)
+}
+
+// WatchInvitations prints the invitations of the user as the server pushes them, reopening the
+// stream if it fails.
+// It doesn't return anything.
+func WatchInvitations(c chat.ChatServiceClient, session *Session, u string) {
+
+	for delay := minRetryDelay; ; delay *= 2 {
+		stream, err := c.WatchInvitations(context.Background(), &chat.Empty{})
+		if err == nil {
+			for {
+				inv, rerr := stream.Recv()
+				if rerr != nil {
+					err = rerr
+					break
+				}
+				delay = minRetryDelay
+				PrintInvitation(u, inv)
+			}
+		}
+
+		if Revoked(err) {
+			SessionEnded()
+		} else if status.Code(err) == codes.Unauthenticated {
+			session.Relogin(c)
+		}
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+		time.Sleep(delay)
+	}
+}
+
+// Roster holds the presence of the users as the server pushes it.
+type Roster struct {
+	lock      sync.RWMutex
+	presences map[string]*chat.Presence
+	ready     chan struct{} // closed once the first list came in
+	once      sync.Once
+}
+
+// NewRoster returns an empty roster.
+func NewRoster() *Roster {
+	return &Roster{presences: make(map[string]*chat.Presence), ready: make(chan struct{})}
+}
+
+// update applies a list pushed by the server, a full list replaces the roster.
+// It doesn't return anything.
+func (r *Roster) update(l *chat.PresenceList, full bool) {
+
+	r.lock.Lock()
+	if full {
+		r.presences = make(map[string]*chat.Presence)
+	}
+	for _, p := range l.Presences {
+		r.presences[p.Name] = p
+	}
+	r.lock.Unlock()
+
+	r.once.Do(func() { close(r.ready) })
+}
+
+// Wait waits at most d for the first list from the server.
+// It returns false if it didn't come.
+func (r *Roster) Wait(d time.Duration) bool {
+	select {
+	case <-r.ready:
+		return true
+	case <-time.After(d):
+		return false
+	}
+}
+
+// Get returns the presence of name, offline if the server didn't say otherwise.
+func (r *Roster) Get(name string) *chat.Presence {
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if p, ok := r.presences[name]; ok {
+		return p
+	}
+	return &chat.Presence{Name: name}
+}
+
+// Online returns the number of users who are not offline.
+func (r *Roster) Online() int {
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	n := 0
+	for _, p := range r.presences {
+		if p.State != chat.PresenceState_OFFLINE {
+			n++
+		}
+	}
+	return n
+}
+
+// PresenceLabel describes a presence in a few words, nothing for someone online without status.
+func PresenceLabel(p *chat.Presence) string {
+
+	label := ""
+	if p.State != chat.PresenceState_ONLINE {
+		label = strings.ToLower(p.State.String())
+	}
+	if p.Status != "" {
+		if label != "" {
+			label += ": "
+		}
+		label += p.Status
+	}
+	return label
+}
+
+// WatchPresence keeps the roster of the session up to date, reopening the stream if it fails.
+// It doesn't return anything.
+func WatchPresence(c chat.ChatServiceClient, session *Session) {
+
+	for delay := minRetryDelay; ; delay *= 2 {
+		stream, err := c.WatchPresence(context.Background(), &chat.Empty{})
+		if err == nil {
+			for full := true; ; full = false {
+				l, rerr := stream.Recv()
+				if rerr != nil {
+					err = rerr
+					break
+				}
+				delay = minRetryDelay
+				session.roster.update(l, full)
+			}
+		}
+
+		if Revoked(err) {
+			SessionEnded()
+		} else if status.Code(err) == codes.Unauthenticated {
+			session.Relogin(c)
+		}
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+		time.Sleep(delay)
+	}
+}
+
+// SetPresence sets the state and the status text of the user from a "!status" argument.
+// It doesn't return anything.
+func SetPresence(c chat.ChatServiceClient, arg string) {
+
+	word, text := SplitCommand(arg)
+	state, ok := chat.PresenceState_value[strings.ToUpper(word)]
+	if !ok || state == int32(chat.PresenceState_OFFLINE) {
+		color.New(color.FgRed).Println("Usage: !status online|away|busy [text]")
+		return
+	}
+
+	_, err := c.SetPresence(context.Background(), &chat.Presence{State: chat.PresenceState(state), Status: text})
+	if err != nil {
+		color.New(color.FgRed).Println("Could not set your status: " + status.Convert(err).Message())
+		return
+	}
+	color.New(color.FgGreen).Println("You are now " + strings.ToLower(word) + ".")
+}
+
+// PrintInvitation displays a notification for an invitation sent to or answered by someone else.
+// It doesn't return anything.
+func PrintInvitation(u string, inv *chat.Invitation) {
+
+	to := "a direct conversation"
+	if inv.Group != "" {
+		to = "group " + inv.Group
+	}
+
+	switch {
+	case inv.Invitee == u && inv.State == chat.InvitationState_PENDING:
+		color.New(color.FgHiMagenta).Println("* " + inv.Inviter + " invited you to " + to + ", answer it from the inbox menu.")
+	case inv.Inviter == u && inv.State != chat.InvitationState_PENDING:
+		color.New(color.FgHiMagenta).Println("* your invitation of " + inv.Invitee + " to " + to + " is " + strings.ToLower(inv.State.String()) + ".")
+	}
+}
+
+func Chat(conn *grpc.ClientConn, c chat.ChatServiceClient, session *Session, listener *Listener, r *bufio.Reader, u string, room Room) bool {
+
+	stream, sendingQueue, receivingQueue, err := StartChat(c, session, listener, r, u, room)
+	if err != nil {
+		color.New(color.FgRed).Println("Could not open the chat: " + err.Error())
+		return true
+	}
+	AddSpacing(1)
+	fmt.Println("good chat with " + room.Name() + ".")
+	Frame()
+
+	var search *chat.SearchRequest // the search !search goes on with
+	// direct messages are encrypted for the peer on their way out
+	send := func(msg *chat.Message) {
+		if err := keyring.Seal(c, room.Peer, msg); err == errNoKey {
+			color.New(color.FgRed).Println("Not sent, " + room.Peer + " has no key: type !clear to send your messages to them in clear.")
+			return
+		} else if err != nil {
+			color.New(color.FgRed).Println("Not sent, could not encrypt it: " + status.Convert(err).Message())
+			return
+		}
+		stream.Send(msg)
+	}
+	for {
+		select {
+		case toSend := <-sendingQueue.MessageChanel:
+			switch cmd, arg := SplitCommand(toSend.Body); cmd {
+			case "!members":
+				log.Println("!members:")
+				if room.Group != "" {
+					CurrentMembers(c, session.roster, room.Group)
+				}
+			case "!leave":
+				log.Println("!leave.")
+				if room.Group != "" {
+					c.LeaveChatRoom(context.Background(), &chat.ChatGroup{Client: u, Name: room.Group})
+				}
+				sendingQueue.StopListeningMessage()
+				listener.chattingState = false
+				stream.Close()
+				return true
+			case "!exit":
+				log.Println("!exit.")
+				stream.Send(LeaveEvent(u, room))
+				ExitClient(c, session, u, room.Group)
+				stream.Close()
+				conn.Close()
+				return false
+			case "!help":
+				help()
+			case "!invite":
+				log.Println("!invite.")
+				InviteToRoom(c, room, arg)
+			case "!kick", "!ban", "!unban", "!mute", "!unmute", "!promote", "!demote", "!transfer":
+				log.Println(cmd + ".")
+				Moderate(c, room, cmd, arg)
+			case "!status":
+				log.Println("!status.")
+				SetPresence(c, arg)
+			case "!reply":
+				log.Println("!reply.")
+				if msg := Reply(c, u, room, arg); msg != nil {
+					send(msg)
+				}
+			case "!thread":
+				log.Println("!thread.")
+				ShowThread(c, u, room, arg)
+			case "!attach":
+				log.Println("!attach.")
+				if msg := Attach(c, u, room, arg); msg != nil {
+					send(msg)
+				}
+			case "!get":
+				log.Println("!get.")
+				Get(c, u, room, arg)
+			case "!search":
+				log.Println("!search.")
+				search = Search(c, u, arg, search)
+			case "!mentions":
+				log.Println("!mentions.")
+				ShowMentions(c, u)
+			case "!react":
+				log.Println("!react.")
+				React(c, u, room, arg)
+			case "!edit", "!delete":
+				log.Println(cmd + ".")
+				ChangeMessage(c, u, room, cmd, arg)
+			case "!watch", "!unwatch":
+				log.Println(cmd + ".")
+				if arg == "" {
+					color.New(color.FgRed).Println("Usage: " + cmd + " <group>")
+				} else if cmd == "!watch" {
+					stream.Subscribe(Room{Group: arg})
+				} else {
+					stream.Unsubscribe(Room{Group: arg})
+				}
+
+			case "!verify":
+				log.Println("!verify.")
+				Verify(c, room, arg)
+
+			case "!clear":
+				log.Println("!clear.")
+				if keyring == nil || room.Peer == "" {
+					color.New(color.FgRed).Println("Only the direct messages of an encrypted conversation can be sent in clear.")
+				} else {
+					keyring.AllowClear(room.Peer)
+					color.New(color.FgHiBlack).Println("Your messages to " + room.Peer + " go in clear while they have no key.")
+				}
+
+			default:
+				log.Println("Sending the message.")
+				send(&toSend)
+			}
+		case received := <-receivingQueue.MessageChanel:
+			log.Println("Receiving the message.")
+			if m := received.GetMention(); m != nil {
+				// the message itself shows in its own room
+				if !room.Owns(&received) {
+					color.New(color.FgHiMagenta).Println(received.Sender + " mentioned you in " + received.Receiver + " (" + Short(m.Id) + "): " + m.Body)
+				}
+				continue
+			}
+			if received.Event != nil && !room.Owns(&received) {
+				continue
+			}
+			switch ev := received.Event.(type) {
+			case *chat.Message_Typing:
+				if ev.Typing.Started {
+					color.New(color.FgHiBlack).Println(received.Sender + " is typing…")
+				}
+			case *chat.Message_Read:
+				if seq := stream.receipts.Update(received.Sender, ev.Read.Seq); seq > 0 {
+					color.New(color.FgHiBlack).Println("  ✓ seen by " + strings.Join(stream.receipts.SeenBy(seq), ", "))
+				}
+			case *chat.Message_Ack:
+				stream.receipts.Sent(ev.Ack.Seq)
+			case *chat.Message_Error:
+				color.New(color.FgRed).Println("Not sent: " + ev.Error.Message)
+			case *chat.Message_Join:
+				color.New(color.FgHiBlack).Println(received.Sender + " joined the chat")
+			case *chat.Message_Edit:
+				color.New(color.FgHiBlack).Print(received.Sender + " edited " + Short(ev.Edit.Id) + ": ")
+				fmt.Print(ev.Edit.Body)
+			case *chat.Message_Reaction:
+				r := ev.Reaction
+				if r.Removed {
+					color.New(color.FgHiBlack).Printf("%s took back %s on %s (%d)\n", received.Sender, r.Emoji, Short(r.Id), r.Count)
+				} else {
+					color.New(color.FgHiBlack).Printf("%s reacted %s to %s (%d)\n", received.Sender, r.Emoji, Short(r.Id), r.Count)
+				}
+			case *chat.Message_Delete:
+				color.New(color.FgHiBlack).Println(received.Sender + " deleted " + Short(ev.Delete.Id))
+			case *chat.Message_Leave:
+				if ev.Leave.Removed {
+					color.New(color.FgHiBlack).Println(received.Sender + " was removed from the chat")
+				} else {
+					color.New(color.FgHiBlack).Println(received.Sender + " left the chat")
+				}
+			default:
+
+				PrintMessage(c, &received, u)
+				if received.Seq > 0 && room.Owns(&received) {
+					stream.Send(ReadReceipt(u, room, received.Seq))
+				}
+
+			}
+		}
+	}
+
+}
+
+// DialCredentials returns the transport of the connection to the server: TLS if a CA bundle
+// or a certificate fingerprint to pin is given, with the client certificate if there is one,
+// and plain text otherwise.
+// It returns the dial option and an error.
+func DialCredentials(caFile string, pin string, certFile string, keyFile string) (grpc.DialOption, error) {
+
+	if caFile == "" && pin == "" && certFile == "" {
+		return grpc.WithInsecure(), nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificate found in " + caFile)
+		}
+	}
+
+	if pin != "" {
+		want, err := hex.DecodeString(strings.ReplaceAll(pin, ":", ""))
+		if err != nil || len(want) != sha256.Size {
+			return nil, errors.New("the pin must be the SHA-256 fingerprint of the server certificate in hex")
+		}
+		// a pinned certificate is trusted on its own, the CA bundle is checked too if given
+		cfg.InsecureSkipVerify = caFile == ""
+		cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) > 0 {
+				got := sha256.Sum256(rawCerts[0])
+				if subtle.ConstantTimeCompare(got[:], want) == 1 {
+					return nil
+				}
+			}
+			return errors.New("the server certificate doesn't match the pinned fingerprint")
+		}
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(cfg)), nil
+}
+
+func main() {
+
+	caFile := flag.String("ca", "", "CA bundle to verify the server certificate with, connects with TLS if set")
+	pin := flag.String("pin", "", "SHA-256 fingerprint of the server certificate to accept, connects with TLS if set")
+	certFile := flag.String("cert", "", "client certificate, for servers requiring mutual TLS")
+	keyFile := flag.String("key", "", "private key of the client certificate")
+	keyDir := flag.String("keys", DefaultKeyDir(), "directory of the keys encrypting the direct messages, they are sent in clear if empty")
+	flag.Parse()
+
+	transport, err := DialCredentials(*caFile, *pin, *certFile, *keyFile)
+	if err != nil {
+		log.Fatalf("Could not set up TLS: %v", err)
+	}
+
+	r := bufio.NewReader(os.Stdin)
 
 	var uName string // Client username
-	var gName string // Client's chat group
+	var room Room    // Client's chat group or conversation
 
 	a := SetServer(r)
+	session := &Session{roster: NewRoster()}
 
 	// Set up a connection to the server.
-	conn, err := grpc.Dial(a, grpc.WithInsecure())
+	// keepalives let the server notice a dead connection and take the user offline
+	conn, err := grpc.Dial(a, transport,
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{Time: time.Minute, Timeout: 20 * time.Second, PermitWithoutStream: true}),
+		grpc.WithUnaryInterceptor(session.UnaryInterceptor),
+		grpc.WithStreamInterceptor(session.StreamInterceptor))
 
 	if err != nil {
 		log.Fatalf("Could not connect: %v", err)
@@ -261,27 +2217,30 @@ func main() {
 
 	// Create the client
 	c := chat.NewChatServiceClient(conn)
-	ctx := context.Background()
-	stream, serr := c.RouteChat(ctx)
-	if serr != nil {
-		log.Fatal(serr)
+	uName = SetName(c, session, r, *certFile != "")
+	if *keyDir != "" {
+		if keyring, err = OpenKeyring(*keyDir, uName); err != nil {
+			log.Fatalf("Could not open the keys: %v", err)
+		}
+		if err := keyring.Publish(c); err != nil {
+			color.New(color.FgRed).Println("Could not publish your key, the others can't encrypt their messages to you: " + status.Convert(err).Message())
+		}
 	}
+	go WatchInvitations(c, session, uName)
+	go session.KeepFresh(c)
 
-	uName = SetName(c, r)
 	showMenu := true // Control whether the user sees the menu or exits.
 	m := NewListener()
-	go m.ControlExit(c, uName, gName)
+	go m.ControlExit(c, session, uName, room.Group)
 
 	for showMenu {
-		gName, err = TopMenu(c, r, uName)
+		room, err = TopMenu(c, session, r, uName)
 
 		if err != nil {
 			fmt.Print(err)
 			os.Exit(1)
 		}
 
-		log.Print("Showing context")
-		log.Print(ctx)
-		showMenu = Chat(conn, stream, c, m, r, uName, gName)
+		showMenu = Chat(conn, c, session, m, r, uName, room)
 	}
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/baadjis/grpchat/chat"
+	"github.com/baadjis/grpchat/e2e"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// keyServer gives the keys of keys, the other calls are not used by the keyring.
+type keyServer struct {
+	chat.ChatServiceClient
+	keys map[string][][]byte
+}
+
+func (s *keyServer) GetKeys(ctx context.Context, in *chat.PublicKey, opts ...grpc.CallOption) (*chat.KeyList, error) {
+	if len(s.keys[in.Name]) == 0 {
+		return nil, status.Error(codes.NotFound, in.Name+" has no key")
+	}
+	return &chat.KeyList{Name: in.Name, Keys: s.keys[in.Name]}, nil
+}
+
+func TestSealDowngrade(t *testing.T) {
+
+	k, err := OpenKeyring(t.TempDir(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, _ := e2e.GenerateKey()
+	carol, _ := e2e.GenerateKey()
+	srv := &keyServer{keys: map[string][][]byte{"bob": {bob.Public()}, "carol": {carol.Public()}}}
+	k.Trust("carol", e2e.Fingerprint(carol.Public()))
+	k.AllowClear("carol")
+
+	steps := []struct {
+		name   string
+		do     func()
+		peer   string
+		err    string // what Seal refuses for, the server giving no key or taking keys back
+		sealed bool
+	}{
+		{"bob has a key", func() {}, "bob", "", true},
+		{"bob lost it", func() { delete(srv.keys, "bob") }, "bob", "downgrade", false},
+		{"bob lost it, agreed", func() { k.AllowClear("bob") }, "bob", "downgrade", false},
+		{"verified carol lost it", func() { delete(srv.keys, "carol") }, "carol", "downgrade", false},
+		{"dave never had one", func() {}, "dave", "no key", false},
+		{"dave never had one, agreed", func() { k.AllowClear("dave") }, "dave", "", false},
+	}
+	for _, st := range steps {
+		st.do()
+		msg := &chat.Message{Sender: "alice", Receiver: st.peer, Body: "hi\n"}
+		got := ""
+		if err := k.Seal(srv, st.peer, msg); err == errNoKey {
+			got = "no key"
+		} else if err != nil {
+			got = "downgrade"
+		}
+		if got != st.err || (msg.Sealed != nil) != st.sealed || (msg.Body == "") != st.sealed {
+			t.Errorf("%s: refused for %q, sealed %v with body %q, want %q and sealed %v", st.name, got, msg.Sealed != nil, msg.Body, st.err, st.sealed)
+		}
+	}
+}
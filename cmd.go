@@ -9,9 +9,12 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/baadjis/grpchat/chat"
 	"github.com/fatih/color"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Stores the main color for all the entry dialogs.
@@ -43,17 +46,17 @@ func StartMessage() {
 	AddSpacing(1)
 	fmt.Println("Welcome to grpchat!")
 	Frame()
-	fmt.Println("In order to begin chatting, you must first chose a server and username. It cannot be one")
-	fmt.Println("that is already in user on the server. Remember, your username only lasts for as long as")
-	fmt.Println("you are logged into the server!")
+	fmt.Println("In order to begin chatting, you must first chose a server and log in. The first time,")
+	fmt.Println("sign up with a username nobody took yet and a password, the name stays yours for good.")
 	AddSpacing(1)
 }
 
 // WelcomeMessage displays a colored string welcoming the user to the server.
 // It doesn't return anything.
-func WelcomeMessage(c chat.ChatServiceClient, u string) {
+func WelcomeMessage(c chat.ChatServiceClient, session *Session, u string) {
 
 	AddSpacing(1)
+	name := u
 	u = "Welcome " + u + "!"
 	for _, l := range u {
 		color.New(RandColor()).Print(string(l))
@@ -61,7 +64,20 @@ func WelcomeMessage(c chat.ChatServiceClient, u string) {
 	n, _ := c.GetChatClientList(context.Background(), &chat.Empty{})
 	g, _ := c.GetChatGroupList(context.Background(), &chat.Empty{})
 
-	fmt.Print(" There are currently " + strconv.Itoa(len(n.Clients)) + " member(s) logged in and " + strconv.Itoa(len(g.Groups)) + " group(s).")
+	devices := 0
+	for _, i := range n.Infos {
+		if i.Name == name {
+			devices = int(i.Devices)
+		}
+	}
+	session.roster.Wait(2 * time.Second)
+	fmt.Print(" There are currently " + strconv.Itoa(session.roster.Online()) + " member(s) online and " + strconv.Itoa(len(g.Groups)) + " group(s).")
+	if devices > 1 {
+		fmt.Print(" You are logged in on " + strconv.Itoa(devices) + " devices.")
+	}
+	if unread, err := c.GetUnreadCount(context.Background(), &chat.Empty{}); err == nil && unread.Count > 0 {
+		color.New(color.FgHiYellow).Print(" You have " + strconv.Itoa(int(unread.Count)) + " unread message(s).")
+	}
 	AddSpacing(1)
 }
 
@@ -74,7 +90,8 @@ func TopMenuText() {
 	fmt.Println("1) Create a Group")
 	fmt.Println("2) View Group Options")
 	fmt.Println("3) view inbox options")
-	fmt.Println("4) exit chat ")
+	fmt.Println("4) Account options")
+	fmt.Println("5) exit chat ")
 	AddSpacing(1)
 	color.New(promptColor).Print("Main> ")
 }
@@ -94,20 +111,40 @@ func GroupMenuText() {
 	AddSpacing(1)
 	color.New(promptColor).Print("Groups> ")
 }
+
 // inbox menu text
 func InboxMenuText() {
 
 	fmt.Println("Inbox Menu")
 	AddSpacing(1)
-	fmt.Println("1) view inbox invitation")
-	fmt.Println("2) send invitation to someone")
-	fmt.Println("3) accept or reject invitation from someone")
-	fmt.Println("4) exit inbox")
+	fmt.Println("1) List conversations")
+	fmt.Println("2) Send a direct message to someone")
+	fmt.Println("3) Open a conversation")
+	fmt.Println("4) List invitations")
+	fmt.Println("5) Invite someone")
+	fmt.Println("6) Accept or decline an invitation")
+	fmt.Println("7) Go back")
 
 	AddSpacing(1)
 	color.New(promptColor).Print("Inbox> ")
 }
 
+// AccountMenuText displays the option text for the account menu.
+// It doesn't return anything.
+func AccountMenuText() {
+
+	fmt.Println("Account Menu")
+	AddSpacing(1)
+	fmt.Println("1) Change your password")
+	fmt.Println("2) Disable an account (server admins)")
+	fmt.Println("3) Enable an account (server admins)")
+	fmt.Println("4) Log out of all your sessions")
+	fmt.Println("5) Go back")
+
+	AddSpacing(1)
+	color.New(promptColor).Print("Account> ")
+}
+
 // ViewGroupMemMenuText displays option text to view a group.
 // It doesn't return anything.
 func ViewGroupMemMenuText() {
@@ -140,10 +177,34 @@ func SetServer(r *bufio.Reader) string {
 	return address
 }
 
-// SetName sets the username for the user.
+// SetName logs the user in and sets the username for the user, trying the client certificate
+// first if there is one.
 // It returns a string containing the username of the client.
-func SetName(c chat.ChatServiceClient, r *bufio.Reader) string {
+func SetName(c chat.ChatServiceClient, session *Session, r *bufio.Reader, certLogin bool) string {
+	if certLogin {
+		err := session.Login(c, "", "")
+		if err == nil {
+			uName := session.Name()
+			if _, err = c.Register(context.Background(), &chat.ChatClient{Sender: uName}); err == nil {
+				color.New(color.FgHiGreen).Println("Logged in as " + uName + " with your certificate.")
+				go WatchPresence(c, session)
+				WelcomeMessage(c, session, uName)
+				return uName
+			}
+		}
+		AddSpacing(1)
+		color.New(color.FgHiRed).Println("Could not log in with your certificate: " + status.Convert(err).Message())
+	}
+
 	for {
+		fmt.Printf("Log in or sign up? (l/s): ")
+		a, _ := r.ReadString('\n')
+		a = strings.TrimSpace(a)
+		if a != "l" && a != "s" {
+			color.New(color.FgHiRed).Println("Please enter l to log in or s to sign up.")
+			continue
+		}
+
 		fmt.Printf("Enter your username: ")
 		n, err := r.ReadString('\n')
 		if err != nil {
@@ -154,13 +215,37 @@ func SetName(c chat.ChatServiceClient, r *bufio.Reader) string {
 				AddSpacing(1)
 				color.New(color.FgHiRed).Println("Your username must be at least 3 characters long.")
 			} else {
+				fmt.Printf("Enter your password: ")
+				p, _ := r.ReadString('\n')
+				p = strings.TrimSpace(p)
+
+				var lerr error
+				if a == "s" {
+					fmt.Printf("Enter it again: ")
+					again, _ := r.ReadString('\n')
+					if strings.TrimSpace(again) != p {
+						AddSpacing(1)
+						color.New(color.FgHiRed).Println("The passwords don't match.")
+						continue
+					}
+					lerr = session.SignUp(c, uName, p)
+				} else {
+					lerr = session.Login(c, uName, p)
+				}
+				if lerr != nil {
+					AddSpacing(1)
+					color.New(color.FgHiRed).Println("Login failed: " + status.Convert(lerr).Message())
+					continue
+				}
+
 				_, err = c.Register(context.Background(), &chat.ChatClient{Sender: uName})
 
 				if err != nil {
 					AddSpacing(1)
-					color.New(color.FgHiRed).Println("That username already exists. Please choose a new one! ")
+					color.New(color.FgHiRed).Println("Could not register: " + status.Convert(err).Message())
 				} else {
-					WelcomeMessage(c, uName)
+					go WatchPresence(c, session)
+					WelcomeMessage(c, session, uName)
 					return uName
 				}
 			}
@@ -182,11 +267,12 @@ func CreateChatGroup(c chat.ChatServiceClient, r *bufio.Reader, uName string) (s
 		if err != nil {
 			return "", err
 		} else if g != "!back" {
-			_, nerr := c.CreateChatGroup(context.Background(), &chat.ChatGroup{Client: uName, Name: g})
+			v, p := ChooseVisibility(r)
+			_, nerr := c.CreateChatGroup(context.Background(), &chat.ChatGroup{Client: uName, Name: g, Visibility: v, Password: p})
 
 			if nerr != nil {
 				AddSpacing(1)
-				color.New(color.FgRed).Println("The group name \"" + g + "\" has already been chosen. Please select a new one.")
+				color.New(color.FgRed).Println("Could not create the group \"" + g + "\": " + status.Convert(nerr).Message())
 			} else {
 				c.JoinChatGroup(context.Background(), &chat.ChatGroup{Client: uName, Name: g})
 				AddSpacing(1)
@@ -199,6 +285,35 @@ func CreateChatGroup(c chat.ChatServiceClient, r *bufio.Reader, uName string) (s
 	}
 }
 
+// ChooseVisibility asks who can see and join a new group, and its password if it needs one.
+// It returns the visibility and the password.
+func ChooseVisibility(r *bufio.Reader) (chat.Visibility, string) {
+
+	for {
+		fmt.Println("Who can see and join the group? 1) everyone (default) 2) those knowing its name 3) those invited 4) those knowing its password")
+		color.New(promptColor).Print("Visibility> ")
+		i, _ := r.ReadString('\n')
+
+		switch strings.TrimSpace(i) {
+		case "", "1":
+			return chat.Visibility_PUBLIC, ""
+		case "2":
+			return chat.Visibility_UNLISTED, ""
+		case "3":
+			return chat.Visibility_INVITE_ONLY, ""
+		case "4":
+			color.New(promptColor).Print("Group password> ")
+			p, _ := r.ReadString('\n')
+			if p = strings.TrimSpace(p); p != "" {
+				return chat.Visibility_PASSWORD, p
+			}
+			color.New(color.FgRed).Println("The password can't be empty.")
+		default:
+			color.New(color.FgRed).Println("Please enter a valid selection between 1 and 4.")
+		}
+	}
+}
+
 // handles the join group menu option.
 
 func JoinChatGroup(c chat.ChatServiceClient, r *bufio.Reader, u string) string {
@@ -214,8 +329,18 @@ func JoinChatGroup(c chat.ChatServiceClient, r *bufio.Reader, u string) string {
 		}
 
 		_, err := c.JoinChatGroup(context.Background(), &chat.ChatGroup{Client: u, Name: g})
+		if status.Code(err) == codes.FailedPrecondition {
+			// password protected group
+			color.New(promptColor).Print("Group password> ")
+			p, _ := r.ReadString('\n')
+			_, err = c.JoinChatGroup(context.Background(), &chat.ChatGroup{Client: u, Name: g, Password: strings.TrimSpace(p)})
+		}
 
-		if err != nil {
+		if status.Code(err) == codes.PermissionDenied {
+			AddSpacing(1)
+			color.New(color.FgRed).Println(status.Convert(err).Message())
+			AddSpacing(1)
+		} else if err != nil {
 			AddSpacing(1)
 			color.New(color.FgRed).Println("The group name \"" + g + "\" doesn't exist. Please check again.")
 			AddSpacing(1)
@@ -225,28 +350,13 @@ func JoinChatGroup(c chat.ChatServiceClient, r *bufio.Reader, u string) string {
 		}
 	}
 }
-// get chat groups or  invitattions for inbox chat list
-func GetChatGroupsOrInvitation(c chat.ChatServiceClient)([]string,[]string){
-	t, _ := c.GetChatGroupList(context.Background(), &chat.Empty{})	
-	l :=t.Groups
-	groups :=make([]string, 0)
-	invitations :=make([]string, 0)
-	for _,g :=range l{
-		if strings.Contains(g,"+"){
-			invitations = append(invitations,strings.Split(g,"+")[0])
-		}else{
-			groups = append(groups,g)
-		}
 
-	}
-   return groups,invitations
-}
 // ListGroups handles listing all of the groups stored on the server.
 // It doesn't return anything.
 func ListChatGroups(c chat.ChatServiceClient, r *bufio.Reader) {
 
-	l, _ := GetChatGroupsOrInvitation(c)
-	
+	t, _ := c.GetChatGroupList(context.Background(), &chat.Empty{})
+	l := t.Groups
 
 	if len(l) == 0 {
 		AddSpacing(1)
@@ -319,114 +429,248 @@ func JoinedGroup(c chat.ChatServiceClient, uName string, gName string) bool {
 	return false
 }
 
-// invite someone for inboxchat
+// ListConversations lists the direct conversations of the user with their last message.
+// It returns the conversations.
+func ListConversations(c chat.ChatServiceClient) []*chat.Conversation {
+
+	l, err := c.ListConversations(context.Background(), &chat.Empty{})
+	if err != nil || len(l.Conversations) == 0 {
+		AddSpacing(1)
+		color.New(color.FgYellow).Println("You have no conversations yet!")
+		return nil
+	}
 
-func InboxInvitation(c chat.ChatServiceClient, r *bufio.Reader, uName string) (string, error) {
+	AddSpacing(1)
+	fmt.Println("Conversations:")
+	for i, conv := range l.Conversations {
+		fmt.Print("  " + strconv.Itoa(i+1) + ") " + conv.Peer)
+		if m := conv.LastMessage; m != nil {
+			body := m.Body
+			if m.Sealed != nil && !m.Deleted {
+				body, _ = keyring.Open(c, m)
+			}
+			color.New(color.FgHiBlack).Print("  " + m.Sender + ": " + strings.TrimSpace(body))
+		}
+		fmt.Println()
+	}
+	return l.Conversations
+}
+
+// SendDirectMessage asks for someone and a first message to send them.
+// It returns the conversation with them, or a room with no conversation to go back, and an error.
+func SendDirectMessage(c chat.ChatServiceClient, r *bufio.Reader, uName string) (Room, error) {
 
 	for {
 		AddSpacing(1)
-		fmt.Println("Enter the name of person you want to invite for inbox chat.")
-		color.New(promptColor).Print("Invite> ")
+		fmt.Println("Enter the name of the person you want to write to or type !back to go back to the inbox menu.")
+		color.New(promptColor).Print("To> ")
 		other, err := r.ReadString('\n')
 		other = strings.TrimSpace(other)
-		g := uName + "+" + other
 		if err != nil {
-			return "", err
-		} else if other != "!back" && IsRegistered(c, other) {
+			return Room{}, err
+		} else if other == "!back" {
+			return Room{}, nil
+		} else if !IsRegistered(c, other) {
+			color.New(color.FgRed).Println(other + " is not logged in.")
+			continue
+		}
 
-			_, nerr := c.CreateChatGroup(context.Background(), &chat.ChatGroup{Client: uName, Name: g})
+		color.New(promptColor).Print("Message> ")
+		body, err := r.ReadString('\n')
+		if err != nil {
+			return Room{}, err
+		}
 
-			if nerr != nil {
-				AddSpacing(1)
-				color.New(color.FgRed).Println("invitation already sent")
-			} else {
-				c.JoinChatGroup(context.Background(), &chat.ChatGroup{Client: uName, Name: g})
-				AddSpacing(1)
-				color.New(color.FgGreen).Println("sent inbox invitation to: " + other)
-				return g, nil
+		msg := &chat.Message{Sender: uName, Receiver: other, Body: body}
+		err = keyring.Seal(c, other, msg)
+		if err == errNoKey {
+			color.New(promptColor).Print(other + " has no key, send your messages to them in clear? [y/N] ")
+			answer, err := r.ReadString('\n')
+			if err != nil {
+				return Room{}, err
+			} else if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+				color.New(color.FgHiBlack).Println("Not sent.")
+				continue
 			}
-		} else {
-			return g, nil
+			keyring.AllowClear(other)
+		} else if err != nil {
+			color.New(color.FgRed).Println("Could not encrypt the message: " + status.Convert(err).Message())
+			continue
+		}
+		msg, err = c.SendDirectMessage(context.Background(), msg)
+		if err != nil {
+			color.New(color.FgRed).Println("Could not send the message: " + status.Convert(err).Message())
+			continue
 		}
+		color.New(color.FgGreen).Println("Sent to " + other)
+		return Room{Peer: other, Conversation: msg.Conversation}, nil
 	}
 }
 
+// OpenConversation asks which of the conversations of the user to open.
+// It returns the conversation, or a room with no conversation to go back.
+func OpenConversation(c chat.ChatServiceClient, r *bufio.Reader) Room {
 
+	l := ListConversations(c)
+	if len(l) == 0 {
+		return Room{}
+	}
 
-// list chat invitation for current user
-func ListInvitations(c chat.ChatServiceClient, uName string) {
-	fmt.Println("invitations:")
+	for {
+		fmt.Println("Enter the name of the person as it appears in the list or enter !back to go back to the inbox menu.")
+		color.New(promptColor).Print("Open> ")
+		other, _ := r.ReadString('\n')
+		other = strings.TrimSpace(other)
+		if other == "!back" {
+			return Room{}
+		}
+
+		for _, conv := range l {
+			if conv.Peer == other {
+				return Room{Peer: other, Conversation: conv.Id}
+			}
+		}
+		color.New(color.FgRed).Println("You have no conversation with " + other + ".")
+	}
+}
 
-	_,list := GetChatGroupsOrInvitation(c)
-	if len(list) > 0 {
-		for i, inv := range list {
+// PendingInvitations returns the invitations the user received and has not answered yet.
+func PendingInvitations(c chat.ChatServiceClient, uName string) []*chat.Invitation {
 
-			Frame()
-			println(strconv.Itoa(i+1) + " )invitation from :" + inv)
+	l, err := c.ListInvitations(context.Background(), &chat.Empty{})
+	if err != nil {
+		return nil
+	}
 
+	var pending []*chat.Invitation
+	for _, inv := range l.Invitations {
+		if inv.Invitee == uName && inv.State == chat.InvitationState_PENDING {
+			pending = append(pending, inv)
 		}
-	} else {
-		println("you have no invitation")
 	}
+	return pending
 }
-func CheckInvitation(c chat.ChatServiceClient, u string, other string) bool {
-	_,list := GetChatGroupsOrInvitation(c)
 
-	for _, inv := range list {
-		if inv == other {
+// InvitationText describes what an invitation is for.
+func InvitationText(inv *chat.Invitation) string {
 
-			return true
+	if inv.Group == "" {
+		return "a direct conversation"
+	}
+	return "group " + inv.Group
+}
+
+// list chat invitation for current user
+func ListInvitations(c chat.ChatServiceClient, uName string) {
+
+	l, err := c.ListInvitations(context.Background(), &chat.Empty{})
+	if err != nil || len(l.Invitations) == 0 {
+		AddSpacing(1)
+		color.New(color.FgYellow).Println("You have no invitations.")
+		return
+	}
+
+	AddSpacing(1)
+	fmt.Println("Invitations:")
+	for _, inv := range l.Invitations {
+		state := strings.ToLower(inv.State.String())
+		if inv.Invitee == uName {
+			fmt.Println("  from " + inv.Inviter + " to " + InvitationText(inv) + " (" + state + ")")
+		} else {
+			fmt.Println("  to " + inv.Invitee + " for " + InvitationText(inv) + " (" + state + ")")
 		}
 	}
+}
 
-	fmt.Println("you have no invitation from:" + other + "!!")
+// InviteSomeone asks for someone to invite to a group or to a direct conversation.
+// It doesn't return anything.
+func InviteSomeone(c chat.ChatServiceClient, r *bufio.Reader) {
 
-	return false
+	AddSpacing(1)
+	fmt.Println("Enter the name of the person you want to invite or type !back to go back to the inbox menu.")
+	color.New(promptColor).Print("Invite> ")
+	other, _ := r.ReadString('\n')
+	other = strings.TrimSpace(other)
+	if other == "!back" {
+		return
+	}
+
+	fmt.Println("Enter the group to invite them to, or leave empty for a direct conversation.")
+	color.New(promptColor).Print("Group> ")
+	g, _ := r.ReadString('\n')
+	g = strings.TrimSpace(g)
+
+	_, err := c.SendInvitation(context.Background(), &chat.Invitation{Invitee: other, Group: g})
+	if err != nil {
+		AddSpacing(1)
+		color.New(color.FgRed).Println("Could not invite " + other + ": " + status.Convert(err).Message())
+		return
+	}
+	AddSpacing(1)
+	color.New(color.FgGreen).Println("Sent invitation to: " + other)
 }
 
-// accept invitation from someone
-func AcceptOrRejectInvitation(c chat.ChatServiceClient, r *bufio.Reader, u string) string{
-	_,list := GetChatGroupsOrInvitation(c)
-
-	if len(list) > 0 {
-		
-			fmt.Println("type the name of someone to accept or reject invitation")
-			other, _ := r.ReadString('\n')
-			other = strings.TrimSpace(other)
-			g := other +"+"+ u
-			if CheckInvitation(c, u, other) {
-				println(">Accept " + other + " y(yes) or n(no): ")
-				i, _ := r.ReadString('\n')
-				i = strings.TrimSpace(i)
-				switch answer := i; answer {
-				case "y": //accept
-					_,err := c.JoinChatGroup(context.Background(), &chat.ChatGroup{Client: u, Name: g})
-					if err ==nil {
-						color.New(color.FgGreen).Println("Joined " + other)
-						
-						return g
-					}
-					
+// RespondInvitation lets the user accept or decline one of their pending invitations.
+// It returns the group or conversation to open if one was accepted, and an error.
+func RespondInvitation(c chat.ChatServiceClient, r *bufio.Reader, u string) (Room, error) {
 
-				case "n":
-					c.LeaveChatRoom(context.Background(), &chat.ChatGroup{Client: u, Name: g})
-					return "!back"
-				default:
-					fmt.Println("please answer y(yes) or n(no)")
-				}
+	pending := PendingInvitations(c, u)
+	if len(pending) == 0 {
+		AddSpacing(1)
+		color.New(color.FgYellow).Println("You have no pending invitations.")
+		return Room{}, nil
+	}
+
+	AddSpacing(1)
+	for i, inv := range pending {
+		fmt.Println("  " + strconv.Itoa(i+1) + ") from " + inv.Inviter + " to " + InvitationText(inv))
+	}
+	fmt.Println("Enter the number of the invitation or !back to go back to the inbox menu.")
+	color.New(promptColor).Print("Invitation> ")
+	i, err := r.ReadString('\n')
+	if err != nil {
+		return Room{}, err
+	}
+	i = strings.TrimSpace(i)
+	if i == "!back" {
+		return Room{}, nil
+	}
 
+	n, err := strconv.Atoi(i)
+	if err != nil || n < 1 || n > len(pending) {
+		color.New(color.FgRed).Println("Please enter a number between 1 and " + strconv.Itoa(len(pending)) + ".")
+		return Room{}, nil
+	}
+	inv := pending[n-1]
+
+	for {
+		fmt.Print(">Accept " + inv.Inviter + " y(yes) or n(no): ")
+		a, _ := r.ReadString('\n')
+
+		switch answer := strings.TrimSpace(a); answer {
+		case "y", "n":
+			res, err := c.RespondInvitation(context.Background(), &chat.InvitationResponse{Id: inv.Id, Accept: answer == "y"})
+			if err != nil {
+				color.New(color.FgRed).Println("Could not answer the invitation: " + status.Convert(err).Message())
+				return Room{}, nil
+			} else if answer == "n" {
+				return Room{}, nil
 			}
-		
-	} else {
-		fmt.Println("you have no invitation")
+
+			color.New(color.FgGreen).Println("Joined " + InvitationText(res))
+			if res.Group != "" {
+				return Room{Group: res.Group}, nil
+			}
+			return Room{Peer: res.Inviter, Conversation: res.Conversation}, nil
+		default:
+			fmt.Println("please answer y(yes) or n(no)")
+		}
 	}
- return "!back"
 }
 
-//
 // TopMenu handles displaying the menu to the client.
-// It returns the group name for the user and an error.
-func TopMenu(c chat.ChatServiceClient, r *bufio.Reader, u string) (string, error) {
+// It returns the group or conversation for the user and an error.
+func TopMenu(c chat.ChatServiceClient, session *Session, r *bufio.Reader, u string) (Room, error) {
 	//func TopMenu(c pb.ChatClient, u string) (string, error) {
 	log.Println("In TopMenu")
 
@@ -443,28 +687,35 @@ func TopMenu(c chat.ChatServiceClient, r *bufio.Reader, u string) (string, error
 			g, err := CreateChatGroup(c, r, u)
 
 			if err != nil {
-				return g, err
+				return Room{}, err
 			} else if g != "!back" {
-				return g, nil
+				return Room{Group: g}, nil
 			}
 		case "2": // View Group Menu
 			g, err := DisplayGroupMenu(c, r, u)
 
 			if err != nil {
-				return g, err
+				return Room{}, err
 			} else if g != "!back" {
-				return g, nil
+				return Room{Group: g}, nil
 			}
 		case "3": // inbox menu
-		 return DisplayInboxMenu(c,r,u)
-		    
+			room, err := DisplayInboxMenu(c, r, u)
+
+			if err != nil || room.Group != "" || room.Conversation != "" {
+				return room, err
+			}
+
+		case "4": // account menu
+			DisplayAccountMenu(c, session, r, u)
+
+		case "5": // exit client
+			c.UnRegister(context.Background(), &chat.ChatClient{Sender: u})
+			session.Logout(c, false)
+			os.Exit(0)
 
-		case "4": // exit client
-		    c.UnRegister(context.Background(), &chat.ChatClient{Sender: u})
-		    os.Exit(0)
-		
 		default: // Error
-			color.New(color.FgRed).Println("Please enter a valid selection between 1 and 3.")
+			color.New(color.FgRed).Println("Please enter a valid selection between 1 and 5.")
 		}
 	}
 }
@@ -503,8 +754,11 @@ func DisplayGroupMenu(c chat.ChatServiceClient, r *bufio.Reader, u string) (stri
 		}
 	}
 }
-func DisplayInboxMenu(c chat.ChatServiceClient, r *bufio.Reader, u string) (string, error){
-	log.Println("In TopMenu")
+
+// displays the menu for the direct conversations.
+// It returns the conversation to open, or a room with no conversation to go back, and an error.
+func DisplayInboxMenu(c chat.ChatServiceClient, r *bufio.Reader, u string) (Room, error) {
+
 	for {
 		Frame()
 		InboxMenuText()
@@ -512,17 +766,105 @@ func DisplayInboxMenu(c chat.ChatServiceClient, r *bufio.Reader, u string) (stri
 		i = strings.TrimSpace(i)
 
 		switch input := i; input {
-
-		case "1": // list invitations
+		case "1": // List conversations
+			ListConversations(c)
+		case "2": // Write to someone
+			room, err := SendDirectMessage(c, r, u)
+			if err != nil || room.Conversation != "" {
+				return room, err
+			}
+		case "3": // Open a conversation
+			if room := OpenConversation(c, r); room.Conversation != "" {
+				return room, nil
+			}
+		case "4": // List invitations
 			ListInvitations(c, u)
-		case "2": // send  invitation to someone
-			g,_:=InboxInvitation(c, r, u)
-			return g,nil
-		case "3":
-			g:=AcceptOrRejectInvitation(c, r, u)
-			return g,nil
+		case "5": // Invite someone
+			InviteSomeone(c, r)
+		case "6": // Answer an invitation
+			room, err := RespondInvitation(c, r, u)
+			if err != nil || room.Group != "" || room.Conversation != "" {
+				return room, err
+			}
+		case "7": // Go back
+			return Room{}, nil
 		default: // Error
-			color.New(color.FgRed).Println("Please enter a valid selection between 1 and 3.")
+			color.New(color.FgRed).Println("Please enter a valid selection between 1 and 7.")
 		}
 	}
-}
\ No newline at end of file
+}
+
+// displays the menu for the account of the user.
+// It doesn't return anything.
+func DisplayAccountMenu(c chat.ChatServiceClient, session *Session, r *bufio.Reader, u string) {
+
+	for {
+		Frame()
+		AccountMenuText()
+		i, _ := r.ReadString('\n')
+		i = strings.TrimSpace(i)
+
+		switch input := i; input {
+		case "1": // Change password
+			ChangePassword(c, session, r)
+		case "2": // Disable an account
+			SetAccountDisabled(c, r, true)
+		case "3": // Enable an account
+			SetAccountDisabled(c, r, false)
+		case "4": // Log out everywhere, this one included
+			c.UnRegister(context.Background(), &chat.ChatClient{Sender: u})
+			if err := session.Logout(c, true); err != nil {
+				color.New(color.FgRed).Println("Could not log out: " + status.Convert(err).Message())
+				continue
+			}
+			color.New(color.FgGreen).Println("Logged out of all your sessions.")
+			os.Exit(0)
+		case "5": // Go back
+			return
+		default: // Error
+			color.New(color.FgRed).Println("Please enter a valid selection between 1 and 5.")
+		}
+	}
+}
+
+// ChangePassword asks for the current password of the user and a new one.
+// It doesn't return anything.
+func ChangePassword(c chat.ChatServiceClient, session *Session, r *bufio.Reader) {
+
+	fmt.Print("Enter your current password: ")
+	old, _ := r.ReadString('\n')
+	fmt.Print("Enter the new password: ")
+	p, _ := r.ReadString('\n')
+	fmt.Print("Enter it again: ")
+	again, _ := r.ReadString('\n')
+
+	p = strings.TrimSpace(p)
+	if strings.TrimSpace(again) != p {
+		color.New(color.FgRed).Println("The passwords don't match.")
+		return
+	}
+
+	if err := session.ChangePassword(c, strings.TrimSpace(old), p); err != nil {
+		color.New(color.FgRed).Println("Could not change your password: " + status.Convert(err).Message())
+		return
+	}
+	color.New(color.FgGreen).Println("Your password was changed.")
+}
+
+// SetAccountDisabled asks for the name of an account to disable or enable.
+// It doesn't return anything.
+func SetAccountDisabled(c chat.ChatServiceClient, r *bufio.Reader, disabled bool) {
+
+	fmt.Print("Enter the name of the account: ")
+	n, _ := r.ReadString('\n')
+	n = strings.TrimSpace(n)
+
+	_, err := c.DisableAccount(context.Background(), &chat.AccountState{Name: n, Disabled: disabled})
+	if err != nil {
+		color.New(color.FgRed).Println("Could not update " + n + ": " + status.Convert(err).Message())
+	} else if disabled {
+		color.New(color.FgGreen).Println("The account of " + n + " was disabled.")
+	} else {
+		color.New(color.FgGreen).Println("The account of " + n + " was enabled.")
+	}
+}
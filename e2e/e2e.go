@@ -0,0 +1,173 @@
+// Package e2e encrypts the direct messages between two users so that only they can read them.
+// Each user has an X25519 key pair, the two keys of a conversation agree on a shared secret
+// from which HKDF-SHA256 derives the XChaCha20-Poly1305 key of its messages.
+package e2e
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// KeySize is the number of bytes of a public or a private key.
+const KeySize = curve25519.PointSize
+
+// info tells the keys derived here from any other use of the shared secret.
+const info = "grpchat e2e v1"
+
+// The errors of the package.
+var (
+	ErrKey  = errors.New("not a valid key")
+	ErrOpen = errors.New("could not decrypt the message")
+)
+
+// Key is the key pair of a user.
+type Key struct {
+	private []byte
+	public  []byte
+}
+
+// newKey returns the key pair of the private key.
+// It returns the key and an error.
+func newKey(private []byte) (*Key, error) {
+
+	if len(private) != curve25519.ScalarSize {
+		return nil, ErrKey
+	}
+	public, err := curve25519.X25519(private, curve25519.Basepoint)
+	if err != nil {
+		return nil, ErrKey
+	}
+	return &Key{private: private, public: public}, nil
+}
+
+// GenerateKey returns a new random key pair.
+// It returns the key and an error.
+func GenerateKey() (*Key, error) {
+
+	private := make([]byte, curve25519.ScalarSize)
+	if _, err := io.ReadFull(rand.Reader, private); err != nil {
+		return nil, err
+	}
+	return newKey(private)
+}
+
+// LoadKey reads the private key kept in hex in the file at path, or generates one and writes it
+// there, readable by the user only, if there is no such file.
+// It returns the key and an error.
+func LoadKey(path string) (*Key, error) {
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		k, err := GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.WriteString(hex.EncodeToString(k.private) + "\n"); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return k, f.Close()
+	} else if err != nil {
+		return nil, err
+	}
+
+	private, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		return nil, ErrKey
+	}
+	return newKey(private)
+}
+
+// Public returns the public key to hand out to the peers.
+func (k *Key) Public() []byte {
+	return k.public
+}
+
+// Fingerprint returns the start of the sha256 of the public key pub in groups of 4 hex digits,
+// short enough for two users to compare out loud.
+func Fingerprint(pub []byte) string {
+
+	sum := sha256.Sum256(pub)
+	h := hex.EncodeToString(sum[:16])
+	var groups []string
+	for i := 0; i < len(h); i += 4 {
+		groups = append(groups, h[i:i+4])
+	}
+	return strings.Join(groups, " ")
+}
+
+// aead returns the cipher of the messages between k and the public key peer, the same on
+// both sides.
+// It returns the cipher and an error.
+func (k *Key) aead(peer []byte) (cipher.AEAD, error) {
+
+	if len(peer) != KeySize {
+		return nil, ErrKey
+	}
+	secret, err := curve25519.X25519(k.private, peer)
+	if err != nil {
+		return nil, ErrKey
+	}
+
+	// both keys salt the secret, in the same order on both sides
+	salt := append(append([]byte{}, k.public...), peer...)
+	if bytes.Compare(peer, k.public) < 0 {
+		salt = append(append([]byte{}, peer...), k.public...)
+	}
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), key); err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.NewX(key)
+}
+
+// Seal encrypts data for the owner of the public key peer. ad is authenticated along, the
+// peer needs it to open the result.
+// It returns the random nonce followed by the ciphertext, and an error.
+func (k *Key) Seal(peer []byte, data []byte, ad []byte) ([]byte, error) {
+
+	a, err := k.aead(peer)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, a.NonceSize(), a.NonceSize()+len(data)+a.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return a.Seal(nonce, nonce, data, ad), nil
+}
+
+// Open decrypts sealed, which the owner of the public key peer sealed for k with ad, or k for
+// the peer.
+// It returns the data and an error, ErrOpen if sealed was not sealed so or was changed since.
+func (k *Key) Open(peer []byte, sealed []byte, ad []byte) ([]byte, error) {
+
+	a, err := k.aead(peer)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < a.NonceSize()+a.Overhead() {
+		return nil, ErrOpen
+	}
+	data, err := a.Open(nil, sealed[:a.NonceSize()], sealed[a.NonceSize():], ad)
+	if err != nil {
+		return nil, ErrOpen
+	}
+	return data, nil
+}
@@ -0,0 +1,109 @@
+package e2e
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+
+	alice, _ := GenerateKey()
+	bob, _ := GenerateKey()
+	eve, _ := GenerateKey()
+	ad := []byte("alice\x00bob")
+	sealed, err := alice.Seal(bob.Public(), []byte("hello bob"), ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := append([]byte{}, sealed...)
+	changed[len(changed)-1] ^= 1
+
+	tests := []struct {
+		name   string
+		key    *Key
+		peer   []byte
+		sealed []byte
+		ad     []byte
+		err    error
+	}{
+		{"receiver", bob, alice.Public(), sealed, ad, nil},
+		{"sender", alice, bob.Public(), sealed, ad, nil},
+		{"someone else", eve, alice.Public(), sealed, ad, ErrOpen},
+		{"wrong sender", bob, eve.Public(), sealed, ad, ErrOpen},
+		{"other data", bob, alice.Public(), sealed, []byte("eve\x00bob"), ErrOpen},
+		{"changed", bob, alice.Public(), changed, ad, ErrOpen},
+		{"truncated", bob, alice.Public(), sealed[:20], ad, ErrOpen},
+		{"empty", bob, alice.Public(), nil, ad, ErrOpen},
+		{"short key", bob, alice.Public()[:16], sealed, ad, ErrKey},
+	}
+	for _, tt := range tests {
+		data, err := tt.key.Open(tt.peer, tt.sealed, tt.ad)
+		if err != tt.err {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+		} else if err == nil && string(data) != "hello bob" {
+			t.Errorf("%s: opened %q", tt.name, data)
+		}
+	}
+
+	// a key seals for itself, for the other devices of its owner
+	self, err := alice.Seal(alice.Public(), []byte("note"), ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := alice.Open(alice.Public(), self, ad); string(data) != "note" || err != nil {
+		t.Errorf("sealed for itself: %q %v", data, err)
+	}
+	if again, _ := alice.Seal(bob.Public(), []byte("hello bob"), ad); bytes.Equal(again, sealed) {
+		t.Error("two seals of the same message are the same")
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "alice.key")
+	k, err := LoadKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("key file: %v %v", fi, err)
+	}
+	again, err := LoadKey(path)
+	if err != nil || !bytes.Equal(again.Public(), k.Public()) {
+		t.Errorf("loading the key again gave another one: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not hex", "not a key\n"},
+		{"short", "0102030405\n"},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		p := filepath.Join(dir, tt.name)
+		ioutil.WriteFile(p, []byte(tt.data), 0600)
+		if _, err := LoadKey(p); err != ErrKey {
+			t.Errorf("%s: got %v, want %v", tt.name, err, ErrKey)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+
+	alice, _ := GenerateKey()
+	bob, _ := GenerateKey()
+	fp := Fingerprint(alice.Public())
+	if groups := strings.Fields(fp); len(groups) != 8 || len(groups[0]) != 4 {
+		t.Errorf("fingerprint %q is not 8 groups of 4 digits", fp)
+	}
+	if Fingerprint(alice.Public()) != fp || Fingerprint(bob.Public()) == fp {
+		t.Error("fingerprints don't tell the keys apart")
+	}
+}
@@ -0,0 +1,227 @@
+// Package mailbox queues the messages sent to a client until one of its streams delivers them.
+package mailbox
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/baadjis/grpchat/chat"
+	"github.com/golang/protobuf/jsonpb"
+)
+
+// ErrTooSlow is returned by Drain once a mailbox overflowed under the Disconnect policy.
+var ErrTooSlow = errors.New("client too slow, messages dropped")
+
+// Policy tells what a full mailbox does with a new message.
+type Policy int
+
+// The overflow policies.
+const (
+	DropOldest  Policy = iota // drop the oldest queued message
+	DropNewest                // drop the new message
+	Disconnect                // drop every queued message and disconnect the client
+	SpillToDisk               // queue the new message in a file
+)
+
+var policyNames = []string{"drop-oldest", "drop-newest", "disconnect", "spill-to-disk"}
+
+func (p Policy) String() string {
+	if p < 0 || int(p) >= len(policyNames) {
+		return fmt.Sprintf("Policy(%d)", int(p))
+	}
+	return policyNames[p]
+}
+
+// ParsePolicy returns the policy named n.
+// It returns the policy and an error.
+func ParsePolicy(n string) (Policy, error) {
+	for i, pn := range policyNames {
+		if pn == n {
+			return Policy(i), nil
+		}
+	}
+	return DropOldest, errors.New("unknown overflow policy " + n)
+}
+
+// Mailbox is a bounded queue of messages. Putting a message never blocks, when
+// the mailbox is full the policy of the message decides what is dropped.
+type Mailbox struct {
+	lock      sync.Mutex
+	size      int
+	messages  []chat.Message
+	parked    []chat.Message // messages no stream wanted yet, outside of size and the policy
+	spillPath string
+	spilled   int  // number of messages in the spill file
+	kicked    bool // overflowed under the Disconnect policy since the last Drain
+	dropped   int64
+	Notify    chan struct{}
+}
+
+// New returns an empty mailbox holding up to size messages in memory. Messages
+// spilled to disk go to the file at spillPath.
+func New(size int, spillPath string) *Mailbox {
+	return &Mailbox{size: size, spillPath: spillPath, Notify: make(chan struct{}, 1)}
+}
+
+// Put adds msg to the mailbox applying p if it is full, and wakes up the stream waiting on it.
+// It returns the number of messages dropped.
+func (m *Mailbox) Put(msg chat.Message, p Policy) int {
+
+	m.lock.Lock()
+	n := m.put(msg, p)
+	m.dropped += int64(n)
+	m.lock.Unlock()
+
+	select {
+	case m.Notify <- struct{}{}:
+	default:
+	}
+	return n
+}
+
+func (m *Mailbox) put(msg chat.Message, p Policy) int {
+
+	// once spilling, everything goes to the file to keep the order
+	if m.spilled > 0 {
+		return m.spill(msg)
+	}
+	if len(m.messages) < m.size {
+		m.messages = append(m.messages, msg)
+		return 0
+	}
+
+	switch p {
+	case DropNewest:
+		return 1
+	case Disconnect:
+		n := len(m.messages) + 1
+		m.messages = nil
+		m.kicked = true
+		return n
+	case SpillToDisk:
+		return m.spill(msg)
+	default:
+		// a mailbox of no size has no older message to make room
+		if len(m.messages) == 0 {
+			return 1
+		}
+		m.messages = append(m.messages[1:], msg)
+		return 1
+	}
+}
+
+// spill appends msg to the spill file.
+// It returns 1 if the message could not be written and was dropped.
+func (m *Mailbox) spill(msg chat.Message) int {
+
+	// the protobuf JSON mapping keeps the event of the message
+	js, err := (&jsonpb.Marshaler{}).MarshalToString(&msg)
+	if err == nil {
+		var f *os.File
+		f, err = os.OpenFile(m.spillPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err == nil {
+			_, err = f.WriteString(js + "\n")
+			f.Close()
+		}
+	}
+	if err != nil {
+		return 1
+	}
+
+	m.spilled++
+	return 0
+}
+
+// Park sets aside messages no stream wanted yet, after the ones already parked. They don't take
+// the room of the new messages: up to size of them are kept, the oldest go first.
+func (m *Mailbox) Park(l []chat.Message) {
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.parked = append(m.parked, l...)
+	if n := len(m.parked) - m.size; n > 0 {
+		m.parked = append([]chat.Message{}, m.parked[n:]...)
+	}
+}
+
+// Requeue puts back at the front of the mailbox messages that could not be delivered.
+func (m *Mailbox) Requeue(l []chat.Message) {
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.messages = append(append([]chat.Message{}, l...), m.messages...)
+}
+
+// Drain empties the mailbox, reading back the messages spilled to disk.
+// It returns the parked messages then the ones it held, oldest first, and ErrTooSlow if the
+// mailbox overflowed under the Disconnect policy.
+func (m *Mailbox) Drain() ([]chat.Message, error) {
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.kicked {
+		m.kicked = false
+		return nil, ErrTooSlow
+	}
+
+	l := append(m.parked, m.messages...)
+	m.parked, m.messages = nil, nil
+	if m.spilled == 0 {
+		return l, nil
+	}
+
+	f, err := os.Open(m.spillPath)
+	if err != nil {
+		return l, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		var msg chat.Message
+		if err := jsonpb.Unmarshal(bytes.NewReader(sc.Bytes()), &msg); err == nil {
+			l = append(l, msg)
+		}
+	}
+	m.spilled = 0
+	os.Remove(m.spillPath)
+	return l, sc.Err()
+}
+
+// Len returns the number of messages waiting in the mailbox.
+func (m *Mailbox) Len() int {
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return len(m.parked) + len(m.messages) + m.spilled
+}
+
+// Dropped returns the number of messages the mailbox dropped so far.
+func (m *Mailbox) Dropped() int64 {
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.dropped
+}
+
+// Close drops everything left in the mailbox and removes its spill file.
+func (m *Mailbox) Close() error {
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.parked, m.messages = nil, nil
+	if m.spilled == 0 {
+		return nil
+	}
+	m.spilled = 0
+	return os.Remove(m.spillPath)
+}
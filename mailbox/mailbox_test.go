@@ -0,0 +1,133 @@
+package mailbox
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/baadjis/grpchat/chat"
+)
+
+// msg returns a message of seq.
+func msg(seq int64) chat.Message {
+	return chat.Message{Seq: seq, Body: "message"}
+}
+
+// seqs returns the sequence numbers of l.
+func seqs(l []chat.Message) []int64 {
+	s := []int64{}
+	for _, m := range l {
+		s = append(s, m.Seq)
+	}
+	return s
+}
+
+func equal(a []int64, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPolicies(t *testing.T) {
+
+	tests := []struct {
+		policy  Policy
+		size    int
+		put     int
+		want    []int64
+		dropped int64
+		err     error
+	}{
+		{DropOldest, 3, 2, []int64{1, 2}, 0, nil},
+		{DropOldest, 3, 5, []int64{3, 4, 5}, 2, nil},
+		{DropOldest, 1, 3, []int64{3}, 2, nil},
+		{DropNewest, 3, 5, []int64{1, 2, 3}, 2, nil},
+		{Disconnect, 3, 3, []int64{1, 2, 3}, 0, nil},
+		{Disconnect, 3, 4, []int64{}, 4, ErrTooSlow},
+		{SpillToDisk, 3, 6, []int64{1, 2, 3, 4, 5, 6}, 0, nil},
+	}
+	for _, tt := range tests {
+		m := New(tt.size, filepath.Join(t.TempDir(), "spill"))
+		for i := 1; i <= tt.put; i++ {
+			m.Put(msg(int64(i)), tt.policy)
+		}
+		if n := m.Len(); tt.err == nil && n != len(tt.want) {
+			t.Errorf("%v of %d after %d: Len = %d", tt.policy, tt.size, tt.put, n)
+		}
+		l, err := m.Drain()
+		if err != tt.err || !equal(seqs(l), tt.want) || m.Dropped() != tt.dropped {
+			t.Errorf("%v of %d after %d: got %v %v dropped %d, want %v %v dropped %d", tt.policy, tt.size, tt.put, seqs(l), err, m.Dropped(), tt.want, tt.err, tt.dropped)
+		}
+		if l, err := m.Drain(); len(l) != 0 || err != nil {
+			t.Errorf("%v of %d: second Drain = %v %v", tt.policy, tt.size, seqs(l), err)
+		}
+	}
+}
+
+func TestPark(t *testing.T) {
+
+	m := New(3, filepath.Join(t.TempDir(), "spill"))
+	m.Put(msg(1), DropNewest)
+	m.Put(msg(2), DropNewest)
+	l, _ := m.Drain()
+	m.Park(l)
+
+	// the parked messages don't take the room of the new ones
+	for i := int64(3); i <= 5; i++ {
+		if n := m.Put(msg(i), DropNewest); n != 0 {
+			t.Errorf("Put %d dropped %d with %d parked", i, n, len(l))
+		}
+	}
+	if n := m.Len(); n != 5 {
+		t.Errorf("Len = %d, want 5", n)
+	}
+	if l, _ := m.Drain(); !equal(seqs(l), []int64{1, 2, 3, 4, 5}) {
+		t.Errorf("Drain = %v, want the parked messages first", seqs(l))
+	}
+
+	// up to size of them are kept, the oldest go first
+	m.Park([]chat.Message{msg(1), msg(2)})
+	m.Park([]chat.Message{msg(3), msg(4)})
+	if l, _ := m.Drain(); !equal(seqs(l), []int64{2, 3, 4}) {
+		t.Errorf("Drain = %v, want the latest parked messages", seqs(l))
+	}
+	if m.Dropped() != 0 {
+		t.Errorf("Dropped = %d, parked messages count as dropped", m.Dropped())
+	}
+
+	m.Park([]chat.Message{msg(1)})
+	m.Close()
+	if n := m.Len(); n != 0 {
+		t.Errorf("Len after Close = %d", n)
+	}
+}
+
+func TestRequeue(t *testing.T) {
+
+	m := New(3, filepath.Join(t.TempDir(), "spill"))
+	m.Put(msg(1), DropOldest)
+	m.Put(msg(2), DropOldest)
+	l, _ := m.Drain()
+	m.Put(msg(3), DropOldest)
+	m.Requeue(l[1:])
+	if l, _ := m.Drain(); !equal(seqs(l), []int64{2, 3}) {
+		t.Errorf("Drain = %v, want the requeued message first", seqs(l))
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+
+	for _, p := range []Policy{DropOldest, DropNewest, Disconnect, SpillToDisk} {
+		if got, err := ParsePolicy(p.String()); got != p || err != nil {
+			t.Errorf("ParsePolicy(%q) = %v %v", p.String(), got, err)
+		}
+	}
+	if _, err := ParsePolicy("drop-all"); err == nil {
+		t.Error("ParsePolicy of an unknown policy succeeded")
+	}
+}
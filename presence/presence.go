@@ -0,0 +1,174 @@
+// Package presence keeps track of the users connected to the server and of the state they set
+// for themselves.
+package presence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/baadjis/grpchat/chat"
+)
+
+// MaxStatus is the most characters of a status text.
+const MaxStatus = 100
+
+// The errors returned by Set.
+var (
+	ErrState  = errors.New("the state can only be online, away or busy")
+	ErrStatus = fmt.Errorf("a status has at most %d characters", MaxStatus)
+)
+
+// user is the presence of a user. The state it chose is shown while it has a stream open,
+// it is offline otherwise.
+type user struct {
+	streams int
+	state   chat.PresenceState
+	status  string
+	since   int64
+}
+
+// Tracker holds the presence of every user seen since the server started.
+type Tracker struct {
+	lock     sync.Mutex
+	users    map[string]*user
+	watchers map[int]chan chat.Presence
+	next     int
+}
+
+// New returns an empty tracker.
+func New() *Tracker {
+	return &Tracker{
+		users:    make(map[string]*user),
+		watchers: make(map[int]chan chat.Presence),
+	}
+}
+
+// presence returns what the others see of u.
+func (u *user) presence(name string) chat.Presence {
+	state := u.state
+	if u.streams == 0 {
+		state = chat.PresenceState_OFFLINE
+	}
+	return chat.Presence{Name: name, State: state, Status: u.status, Since: u.since}
+}
+
+// user returns the presence of name, adding it if it is new. The tracker lock must be held.
+func (t *Tracker) user(name string) *user {
+	u, ok := t.users[name]
+	if !ok {
+		u = &user{state: chat.PresenceState_ONLINE, since: time.Now().UnixNano()}
+		t.users[name] = u
+	}
+	return u
+}
+
+// publish pushes the presence of name to the watchers. A watcher too slow to take it is closed,
+// so it starts over from a fresh list instead of missing a change.
+// The tracker lock must be held.
+func (t *Tracker) publish(name string, u *user) {
+	p := u.presence(name)
+	for id, w := range t.watchers {
+		select {
+		case w <- p:
+		default:
+			close(w)
+			delete(t.watchers, id)
+		}
+	}
+}
+
+// Connect counts a stream opened by name, which is online until all of its streams are closed.
+// It returns the func to call once the stream is closed.
+func (t *Tracker) Connect(name string) func() {
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	u := t.user(name)
+	u.streams++
+	if u.streams == 1 {
+		u.since = time.Now().UnixNano()
+		t.publish(name, u)
+	}
+
+	return func() {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+
+		u.streams--
+		if u.streams == 0 {
+			u.since = time.Now().UnixNano()
+			t.publish(name, u)
+		}
+	}
+}
+
+// Set changes the state and the status text of name.
+// It returns an error.
+func (t *Tracker) Set(name string, state chat.PresenceState, status string) error {
+
+	switch state {
+	case chat.PresenceState_ONLINE, chat.PresenceState_AWAY, chat.PresenceState_BUSY:
+	default:
+		return ErrState
+	}
+	if len([]rune(status)) > MaxStatus {
+		return ErrStatus
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	u := t.user(name)
+	u.state = state
+	u.status = status
+	u.since = time.Now().UnixNano()
+	t.publish(name, u)
+	return nil
+}
+
+// Get returns the presence of name, offline if it was never seen.
+func (t *Tracker) Get(name string) chat.Presence {
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	u, ok := t.users[name]
+	if !ok {
+		return chat.Presence{Name: name}
+	}
+	return u.presence(name)
+}
+
+// list returns the presence of everyone sorted by name. The tracker lock must be held.
+func (t *Tracker) list() []chat.Presence {
+	l := make([]chat.Presence, 0, len(t.users))
+	for name, u := range t.users {
+		l = append(l, u.presence(name))
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].Name < l[j].Name })
+	return l
+}
+
+// Watch returns the presence of everyone, a channel getting the changes made after it and a
+// func to call once done watching. The channel is closed if it falls behind.
+func (t *Tracker) Watch() ([]chat.Presence, <-chan chat.Presence, func()) {
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	id := t.next
+	t.next++
+	w := make(chan chat.Presence, 64)
+	t.watchers[id] = w
+
+	return t.list(), w, func() {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+
+		delete(t.watchers, id)
+	}
+}
@@ -0,0 +1,118 @@
+package presence
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/baadjis/grpchat/chat"
+)
+
+func TestSet(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		state  chat.PresenceState
+		status string
+		err    error
+	}{
+		{"online", chat.PresenceState_ONLINE, "", nil},
+		{"away", chat.PresenceState_AWAY, "lunch", nil},
+		{"busy", chat.PresenceState_BUSY, "in a meeting", nil},
+		{"offline", chat.PresenceState_OFFLINE, "", ErrState},
+		{"unknown state", chat.PresenceState(42), "", ErrState},
+		{"longest status", chat.PresenceState_AWAY, strings.Repeat("é", MaxStatus), nil},
+		{"long status", chat.PresenceState_AWAY, strings.Repeat("a", MaxStatus+1), ErrStatus},
+	}
+	for _, tt := range tests {
+		tr := New()
+		done := tr.Connect("alice")
+		if err := tr.Set("alice", tt.state, tt.status); err != tt.err {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		p := tr.Get("alice")
+		if tt.err == nil && (p.State != tt.state || p.Status != tt.status) {
+			t.Errorf("%s: got %v %q", tt.name, p.State, p.Status)
+		}
+		done()
+	}
+}
+
+func TestConnect(t *testing.T) {
+
+	tr := New()
+	if p := tr.Get("alice"); p.State != chat.PresenceState_OFFLINE {
+		t.Errorf("never seen: %v", p.State)
+	}
+	tr.Set("alice", chat.PresenceState_BUSY, "coding")
+
+	first := tr.Connect("alice")
+	second := tr.Connect("alice")
+	steps := []struct {
+		name  string
+		do    func()
+		state chat.PresenceState
+	}{
+		{"two streams", func() {}, chat.PresenceState_BUSY},
+		{"one left", first, chat.PresenceState_BUSY},
+		{"none left", second, chat.PresenceState_OFFLINE},
+	}
+	for _, st := range steps {
+		st.do()
+		if p := tr.Get("alice"); p.State != st.state || p.Status != "coding" {
+			t.Errorf("%s: got %v %q, want %v", st.name, p.State, p.Status, st.state)
+		}
+	}
+	done := tr.Connect("alice")
+	defer done()
+	if p := tr.Get("alice"); p.State != chat.PresenceState_BUSY {
+		t.Errorf("back: got %v, want the state alice chose", p.State)
+	}
+}
+
+func TestWatch(t *testing.T) {
+
+	tr := New()
+	done := tr.Connect("bob")
+	defer done()
+	tr.Connect("alice")
+
+	l, w, stop := tr.Watch()
+	if len(l) != 2 || l[0].Name != "alice" || l[1].Name != "bob" {
+		t.Fatalf("Watch listed %v", l)
+	}
+	tr.Set("bob", chat.PresenceState_AWAY, "")
+	second := tr.Connect("bob")
+	second()
+	tr.Connect("carol")
+
+	// a second stream of bob changes nothing the others see
+	want := []struct {
+		name  string
+		state chat.PresenceState
+	}{
+		{"bob", chat.PresenceState_AWAY},
+		{"carol", chat.PresenceState_ONLINE},
+	}
+	for _, wt := range want {
+		p := <-w
+		if p.Name != wt.name || p.State != wt.state {
+			t.Errorf("got %s %v, want %s %v", p.Name, p.State, wt.name, wt.state)
+		}
+	}
+	stop()
+
+	// a watcher that falls behind is closed
+	_, slow, stop := tr.Watch()
+	defer stop()
+	for i := 0; i <= cap(slow); i++ {
+		tr.Set("bob", chat.PresenceState_AWAY, strings.Repeat("z", i%MaxStatus))
+	}
+	n := 0
+	for range slow {
+		n++
+	}
+	if n != cap(slow) {
+		t.Errorf("the slow watcher got %d changes, want %d then closed", n, cap(slow))
+	}
+}
@@ -1,52 +1,270 @@
 package main
 
 import (
+	"bytes"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/baadjis/grpchat/accounts"
+	"github.com/baadjis/grpchat/blobs"
 	"github.com/baadjis/grpchat/chat"
+	"github.com/baadjis/grpchat/e2e"
+	"github.com/baadjis/grpchat/mailbox"
+	"github.com/baadjis/grpchat/presence"
+	"github.com/baadjis/grpchat/store"
+	"github.com/baadjis/grpchat/tokens"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 )
 
 // The port the server is listening on.
 const (
-	port        = ":16180"
-	tokenHeader = "x-chat-token"
+	port         = ":16180"
+	tokenHeader  = "x-chat-token"
+	dmPrefix     = "dm:"    // prefix of the history of direct conversations, not allowed in group names
+	serverName   = "server" // sender of the notices of the server, not allowed as a username
+	quoteSize    = 60       // most characters of the message a reply quotes
+	maxEmoji     = 8        // most characters of a reaction
+	maxReactions = 20       // most different reactions to a message
+	maxMentions  = 100      // most mentions of a client kept for ListMentions
+	maxName      = 32       // most characters of a username
+	maxChunk     = 1 << 20  // most bytes of an uploaded chunk
+	chunkSize    = 64 << 10 // bytes of a downloaded chunk
+	keptSent     = 100      // most ids of the messages a device sent kept to leave out of its replays
 )
 
 // the server
 type server struct {
-	Host, Password string
-	lock           sync.RWMutex
-	chatclients    map[string]*Client
-	chatgroups     map[string]*Group
-	clienttoken    map[string]string
+	Host          string
+	accounts      *accounts.Store
+	admins        map[string]bool // server admins, they can disable accounts
+	lock          sync.RWMutex
+	chatclients   map[string]*Client
+	chatgroups    map[string]*Group
+	retired       map[string]*Group // groups whose last member left, kept with their settings for who comes back
+	conversations map[string]*Conversation
+	invitations   map[string]*chat.Invitation
+	watchers      map[string][]chan chat.Invitation // invitation streams of each client
+	inviteTTL     time.Duration
+	tokens        *tokens.Registry
+	presence      *presence.Tracker // a client is online while it has a stream open
+	history       store.MessageStore
+	blobs         *blobs.Store
+	uploads       map[string]map[string]bool   // clients who uploaded each attachment, only they can share it
+	keys          map[string]map[string][]byte // public key each device of each client encrypts its direct messages with
+	policy        mailbox.Policy               // what a full client mailbox does unless its group overrides it
+	mailboxSize   int
+	spillDir      string
+	certIdentity  bool // clients log in as the common name of their certificate, without password
 }
 
 type Group struct {
-	name      string
-	ch        chan chat.Message
-	clients   []string
-	WaitGroup *sync.WaitGroup
+	name       string
+	seq        int64           // sequence number of the last message sent to the group
+	policy     *mailbox.Policy // overrides the server overflow policy if set
+	dropped    int64           // messages of the group dropped by full mailboxes
+	visibility chat.Visibility
+	salt       []byte
+	password   []byte // salted hash of the password of a PASSWORD group
+	owner      string
+	admins     map[string]bool
+	banned     map[string]bool
+	muted      map[string]time.Time // end of the mute of a member, zero until unmuted
+	read       map[string]int64     // sequence number each member read up to
+	ch         chan chat.Message
+	clients    []string
+	WaitGroup  *sync.WaitGroup
 }
 
 type Client struct {
 	name      string
 	groups    []string
-	ch        chan chat.Message
+	mailbox   *mailbox.Mailbox // holds the messages while no device is logged in
+	devices   map[string]*Device
+	mentions  []mention // latest messages naming the client, oldest first
 	WaitGroup *sync.WaitGroup
 }
 
+// mention is a message of a group naming a client.
+type mention struct {
+	group string
+	id    string
+}
+
+// Device is a login of a client, every device gets its own copy of the messages.
+type Device struct {
+	id      string
+	mailbox *mailbox.Mailbox
+	streams int      // number of RouteChat streams currently open
+	sent    []string // ids of the latest messages sent from the device, oldest first
+}
+
+// Sent records that the message id was sent from the device, nothing is done for no device.
+// The server lock must be held.
+func (d *Device) Sent(id string) {
+	if d == nil {
+		return
+	}
+	d.sent = append(d.sent, id)
+	if len(d.sent) > keptSent {
+		d.sent = d.sent[len(d.sent)-keptSent:]
+	}
+}
+
+// Put puts msg in the mailbox of every device of the client, or in its own while it has none.
+// It returns the most messages a mailbox dropped.
+func (c *Client) Put(msg chat.Message, p mailbox.Policy) int {
+	return c.PutFrom(msg, p, nil)
+}
+
+// PutFrom is Put leaving out the mailbox of the device from, the one msg was sent from.
+// It returns the most messages a mailbox dropped.
+func (c *Client) PutFrom(msg chat.Message, p mailbox.Policy, from *Device) int {
+	if len(c.devices) == 0 {
+		return c.mailbox.Put(msg, p)
+	}
+
+	n := 0
+	for _, d := range c.devices {
+		if d == from {
+			continue
+		}
+		if k := d.mailbox.Put(msg, p); k > n {
+			n = k
+		}
+	}
+	return n
+}
+
+// Online returns the number of devices of the client with a stream open.
+func (c *Client) Online() int {
+	n := 0
+	for _, d := range c.devices {
+		if d.streams > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// RemoveDevice drops a device of the client, the messages it didn't get go back to the client
+// if it was the last one.
+func (c *Client) RemoveDevice(id string) {
+	d, ok := c.devices[id]
+	if !ok {
+		return
+	}
+
+	delete(c.devices, id)
+	if len(c.devices) == 0 {
+		moveMessages(d.mailbox, c.mailbox)
+	}
+	d.mailbox.Close()
+	log.Print("removed device " + id + " of " + c.name)
+}
+
+// moveMessages hands the messages waiting in from over to to.
+func moveMessages(from *mailbox.Mailbox, to *mailbox.Mailbox) {
+	l, err := from.Drain()
+	if err == mailbox.ErrTooSlow {
+		// the overflow only mattered to the stream it had to end
+		l, err = from.Drain()
+	}
+	if err != nil {
+		log.Printf("could not read the spilled messages: %v", err)
+	}
+	to.Requeue(l)
+}
+
+// hashPassword returns the salted hash of a group password.
+func hashPassword(salt []byte, password string) []byte {
+	h := sha256.Sum256(append(append([]byte{}, salt...), password...))
+	return h[:]
+}
+
+// CheckPassword checks password against the one of the group.
+func (g *Group) CheckPassword(password string) bool {
+	return subtle.ConstantTimeCompare(hashPassword(g.salt, password), g.password) == 1
+}
+
+// Role returns the role of a member in the group.
+func (g *Group) Role(clientName string) chat.Role {
+	switch {
+	case g.owner == clientName:
+		return chat.Role_OWNER
+	case g.admins[clientName]:
+		return chat.Role_ADMIN
+	}
+	return chat.Role_MEMBER
+}
+
+// IsMuted checks if a member of the group is muted at time now.
+func (g *Group) IsMuted(clientName string, now time.Time) bool {
+	until, ok := g.muted[clientName]
+	if ok && !until.IsZero() && now.After(until) {
+		delete(g.muted, clientName)
+		return false
+	}
+	return ok
+}
+
+// Conversation is a direct conversation between two clients, only they can see it.
+type Conversation struct {
+	id      string
+	members [2]string
+	seq     int64 // sequence number of the last message of the conversation
+	last    *chat.Message
+	read    map[string]int64 // sequence number each participant read up to
+}
+
+// key returns the name the conversation history is stored under.
+func (c *Conversation) key() string {
+	return dmPrefix + c.id
+}
+
+// HasMember checks if clientName takes part in the conversation.
+func (c *Conversation) HasMember(clientName string) bool {
+	return c.members[0] == clientName || c.members[1] == clientName
+}
+
+// Peer returns the participant of the conversation other than clientName.
+func (c *Conversation) Peer(clientName string) string {
+	if c.members[0] == clientName {
+		return c.members[1]
+	}
+	return c.members[0]
+}
+
+// conversationID returns the id of the conversation between a and b, the same whoever starts it.
+func conversationID(a string, b string) string {
+	m := []string{a, b}
+	sort.Strings(m)
+	h := sha256.Sum256([]byte(m[0] + "\x00" + m[1]))
+	return fmt.Sprintf("%x", h[:8])
+}
+
 // AddClient adds a new client n to the server.
 
 func (s *server) AddChatClient(n string) {
@@ -56,7 +274,8 @@ func (s *server) AddChatClient(n string) {
 
 	c := &Client{
 		name:      n,
-		ch:        make(chan chat.Message, 100),
+		mailbox:   mailbox.New(s.mailboxSize, filepath.Join(s.spillDir, fmt.Sprintf("%x.spill", n))),
+		devices:   make(map[string]*Device),
 		WaitGroup: &sync.WaitGroup{},
 	}
 
@@ -64,18 +283,77 @@ func (s *server) AddChatClient(n string) {
 	s.chatclients[n] = c
 }
 
-//  add a new group to the server.
+// ClientDevice returns the device id of a client, adding it if it is new. The first device
+// takes the messages that waited for the client.
+// The server lock must be held.
+func (s *server) ClientDevice(cl *Client, id string) *Device {
 
-func (s *server) AddChatGroup(n string) {
+	if d, ok := cl.devices[id]; ok {
+		return d
+	}
+
+	d := &Device{
+		id:      id,
+		mailbox: mailbox.New(s.mailboxSize, filepath.Join(s.spillDir, fmt.Sprintf("%x-%s.spill", cl.name, id))),
+	}
+	if len(cl.devices) == 0 {
+		moveMessages(cl.mailbox, d.mailbox)
+	}
+	cl.devices[id] = d
+
+	log.Print("added device " + id + " of " + cl.name)
+	return d
+}
+
+// DropDevices removes devices of a client, every device if ids is nil.
+func (s *server) DropDevices(name string, ids []string) {
 
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
+	cl, ok := s.chatclients[name]
+	if !ok {
+		return
+	}
+	if ids == nil {
+		for id := range cl.devices {
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range ids {
+		cl.RemoveDevice(id)
+	}
+}
+
+//  add a new group owned by owner to the server, a nil policy means the server overflow policy.
+// The server lock must be held.
+
+func (s *server) AddChatGroup(n string, owner string, policy *mailbox.Policy, visibility chat.Visibility, password string) {
+
 	g := &Group{
-		name:      n,
-		ch:        make(chan chat.Message, 100),
-		WaitGroup: &sync.WaitGroup{},
+		name:       n,
+		policy:     policy,
+		visibility: visibility,
+		owner:      owner,
+		admins:     make(map[string]bool),
+		banned:     make(map[string]bool),
+		muted:      make(map[string]time.Time),
+		read:       make(map[string]int64),
+		ch:         make(chan chat.Message, 100),
+		WaitGroup:  &sync.WaitGroup{},
+	}
+	if visibility == chat.Visibility_PASSWORD {
+		g.salt = make([]byte, 16)
+		rand.Read(g.salt)
+		g.password = hashPassword(g.salt, password)
+	}
+
+	// a group created again under an old name carries on its history
+	seq, err := s.history.LastSeq(n)
+	if err != nil {
+		log.Printf("could not read history of %s: %v", n, err)
 	}
+	g.seq = seq
 
 	log.Print("Added a chat group " + g.name)
 	s.chatgroups[n] = g
@@ -112,7 +390,45 @@ func (s *server) NotAvailableGroupName(groupName string) bool {
 	return false
 }
 
-//cheks if a given client joined a given group
+// GroupExists checks if there is a group with the name, even one whose members all left.
+func (s *server) GroupExists(groupName string) bool {
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	_, live := s.chatgroups[groupName]
+	_, retired := s.retired[groupName]
+	return live || retired
+}
+
+// RestoreChatGroup brings back a group lost on a restart from its stored history, its settings
+// are lost with it: it comes back retired, public and owned by the client bringing it back.
+// It returns an error if there is no history of the group.
+func (s *server) RestoreChatGroup(groupName string, clientName string) error {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, live := s.chatgroups[groupName]
+	_, retired := s.retired[groupName]
+	if live || retired {
+		return nil
+	}
+	if seq, err := s.history.LastSeq(groupName); err != nil {
+		return status.Error(codes.Internal, "could not read the history of "+groupName+": "+err.Error())
+	} else if seq == 0 || strings.HasPrefix(groupName, dmPrefix) {
+		return errors.New("a group with that name doesn't exist")
+	}
+	if _, ok := s.chatclients[clientName]; !ok {
+		return errors.New("Client(" + clientName + ") is not registered")
+	}
+	s.AddChatGroup(groupName, clientName, nil, chat.Visibility_PUBLIC, "")
+	s.retired[groupName] = s.chatgroups[groupName]
+	delete(s.chatgroups, groupName)
+	log.Print(clientName + " restored group " + groupName + " from its history")
+	return nil
+}
+
+// cheks if a given client joined a given group
 func (s *server) ClientJoinedGroup(clientName string, groupName string) bool {
 
 	for _, c := range s.chatgroups[groupName].clients {
@@ -136,9 +452,14 @@ func (s *server) RemoveClientFromGroup(clientName string, groupName string) erro
 				c = c[:len(c)-1]
 				s.chatclients[clientName].groups = c
 
-				// remove the group if there is one client
+				// retire the group if there is one client, its owner, bans and password stay
+				// for whoever brings it back
 				if len(s.chatgroups[g].clients) == 1 {
+					s.chatgroups[g].clients = nil
+					s.chatgroups[g].RemoveMember(clientName)
+					s.retired[g] = s.chatgroups[g]
 					delete(s.chatgroups, g)
+					log.Print("retired group " + g)
 				} else {
 
 					//remove  client name  from the group client
@@ -153,6 +474,7 @@ func (s *server) RemoveClientFromGroup(clientName string, groupName string) erro
 						}
 
 					}
+					s.chatgroups[g].RemoveMember(clientName)
 
 				}
 
@@ -164,6 +486,27 @@ func (s *server) RemoveClientFromGroup(clientName string, groupName string) erro
 	return errors.New("can not found use with this name in this group ")
 }
 
+// RemoveMember drops the role of a client leaving the group. An owner leaving hands the
+// group over to an admin, or to another member if there is none.
+func (g *Group) RemoveMember(clientName string) {
+	delete(g.admins, clientName)
+	delete(g.muted, clientName)
+	delete(g.read, clientName)
+	if g.owner != clientName || len(g.clients) == 0 {
+		return
+	}
+
+	g.owner = g.clients[0]
+	for _, c := range g.clients {
+		if g.admins[c] {
+			g.owner = c
+			break
+		}
+	}
+	delete(g.admins, g.owner)
+	log.Print(g.owner + " now owns " + g.name)
+}
+
 // remove client from any chat group
 
 func (s *server) RemoveClient(clientName string) error {
@@ -187,56 +530,146 @@ func (s *server) RemoveClient(clientName string) error {
 	return errors.New("Client (" + clientName + ") is not registred")
 }
 
-// add a client to a group.
+// checks if a client has a pending invitation to a group.
+// The server lock must be held.
+func (s *server) InvitedToGroup(clientName string, groupName string) bool {
+
+	now := time.Now().UnixNano()
+	for _, inv := range s.invitations {
+		if inv.Invitee == clientName && inv.Group == groupName &&
+			inv.State == chat.InvitationState_PENDING && now <= inv.Expires {
+			return true
+		}
+	}
+	return false
+}
+
+// checks if a client can see a group in the list of groups.
+// The server lock must be held.
+func (s *server) CanSeeGroup(clientName string, g *Group) bool {
+
+	switch g.visibility {
+	case chat.Visibility_UNLISTED:
+		return s.ClientJoinedGroup(clientName, g.name)
+	case chat.Visibility_INVITE_ONLY:
+		return s.ClientJoinedGroup(clientName, g.name) || s.InvitedToGroup(clientName, g.name)
+	}
+	return true
+}
+
+// checks if the visibility of a group lets a client join it, an invitation or owning it always
+// does unless the client is banned.
+// It returns a status error if not. The server lock must be held.
+func (s *server) CanJoinGroup(clientName string, g *Group, password string) error {
 
-func (s *server) AddClientToChatGroup(clientName string, groupName string) error {
+	groupName := g.name
+	if g.banned[clientName] {
+		return status.Error(codes.PermissionDenied, "you are banned from "+groupName)
+	}
+	// the owner of a retired group can always bring it back
+	if s.InvitedToGroup(clientName, groupName) || g.owner == clientName {
+		return nil
+	}
+
+	switch g.visibility {
+	case chat.Visibility_INVITE_ONLY:
+		// not telling the group exists to those who can't see it
+		return status.Error(codes.NotFound, "a group with that name doesn't exist")
+	case chat.Visibility_PASSWORD:
+		if password == "" {
+			return status.Error(codes.FailedPrecondition, "the group "+groupName+" needs a password")
+		} else if !g.CheckPassword(password) {
+			return status.Error(codes.PermissionDenied, "wrong password for "+groupName)
+		}
+	}
+	return nil
+}
 
-	//lock.Lock()
-	//defer lock.Unlock()
+// add a client to a group if its visibility lets them in, the checks and the join are one step.
+// Joining a retired group brings it back as it was.
+
+func (s *server) AddClientToChatGroup(clientName string, groupName string, password string) error {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
 
-	s.chatgroups[groupName].WaitGroup.Add(1)
-	defer s.chatgroups[groupName].WaitGroup.Done()
 	// group did not exist
-	if !s.NotAvailableGroupName(groupName) {
+	g, ok := s.chatgroups[groupName]
+	if !ok {
+		g, ok = s.retired[groupName]
+	}
+	if !ok {
 		return errors.New("Group (" + groupName + ") did not exist")
 	}
+	g.WaitGroup.Add(1)
+	defer g.WaitGroup.Done()
 
 	// client is not registered yet
 
-	if !s.RegisteredClient(clientName) {
+	if _, ok := s.chatclients[clientName]; !ok {
 		return errors.New("Client(" + clientName + ") is not registered")
 	}
 	// client already joined group
-	if s.ClientJoinedGroup(clientName, groupName) {
+	if indexOf(g.clients, clientName) >= 0 {
 		return errors.New("Client(" + clientName + ") already joined group(" + groupName + ")")
 	}
+	if err := s.CanJoinGroup(clientName, g, password); err != nil {
+		return err
+	}
+	if s.retired[groupName] == g {
+		delete(s.retired, groupName)
+		s.chatgroups[groupName] = g
+		log.Print(clientName + " brought back group " + groupName)
+	}
+	s.AddGroupMember(clientName, groupName)
+	return nil
+}
+
+// add a client to the members of a group.
+// The server lock must be held.
+
+func (s *server) AddGroupMember(clientName string, groupName string) {
+
 	s.chatgroups[groupName].clients = append(s.chatgroups[groupName].clients, clientName)
 	s.chatclients[clientName].groups = append(s.chatclients[clientName].groups, groupName)
 
 	log.Println("Added " + clientName + " to group" + groupName)
-	return nil
 }
 
-// get all of the currently connected clients to the server.
+// get all of the clients of the server, with how many devices each has logged in and online
+// and their presence.
 func (s *server) GetChatClientList(ctx context.Context, in *chat.Empty) (*chat.ChatClientList, error) {
 
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
 	var cl []string
-	for key := range s.chatclients {
+	var infos []*chat.ClientInfo
+	for key, c := range s.chatclients {
 		cl = append(cl, key)
+		p := s.presence.Get(key)
+		infos = append(infos, &chat.ClientInfo{Name: key, Devices: int32(len(c.devices)), Online: int32(c.Online()), Presence: &p})
 	}
 
 	log.Print("this is the list of current clients ")
 	log.Print(cl)
 
-	return &chat.ChatClientList{Clients: cl}, nil
+	return &chat.ChatClientList{Clients: cl, Infos: infos}, nil
 }
 
-// It returns a list of  all chatgroups.
+// It returns a list of  all chatgroups the client can see.
 func (s *server) GetChatGroupList(ctx context.Context, in *chat.Empty) (*chat.ChatGroupList, error) {
 
+	name, _ := clientName(ctx)
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
 	var grp []string
-	for groupName := range s.chatgroups {
-		grp = append(grp, groupName)
+	for groupName, g := range s.chatgroups {
+		if s.CanSeeGroup(name, g) {
+			grp = append(grp, groupName)
+		}
 	}
 
 	log.Print("this the list of current chat groups ")
@@ -250,11 +683,15 @@ func (s *server) GetChatGroupClientList(ctx context.Context, in *chat.ChatGroup)
 
 	grpname := in.Name
 
-	if !s.NotAvailableGroupName(grpname) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	g, ok := s.chatgroups[grpname]
+	if !ok || !s.CanSeeGroup(in.Client, g) {
 		return &chat.ChatClientList{}, errors.New("that group doesn't exist")
 	}
 
-	list := s.chatgroups[grpname].clients
+	list := append([]string{}, g.clients...)
 
 	log.Print("this is group " + grpname + " members list: ")
 	log.Print(list)
@@ -262,33 +699,76 @@ func (s *server) GetChatGroupClientList(ctx context.Context, in *chat.ChatGroup)
 	return &chat.ChatClientList{Clients: list}, nil
 }
 
-// Register will add the user to the server's collection of users (and by extension restrict the username).
+// Register will add the user to the server's collection of users, and the device of the call
+// to the devices of the user. The devices whose session expired are dropped.
 // It returns an empty object and an error.
 func (s *server) Register(ctx context.Context, in *chat.ChatClient) (*chat.Empty, error) {
 
 	name := in.Sender
-	if s.RegisteredClient(name) {
-		return nil, errors.New("that client already registered")
+	device, _ := clientDevice(ctx)
+	if !s.RegisteredClient(name) {
+		s.AddChatClient(name)
 	}
+	live := s.tokens.Devices(name)
 
-	s.AddChatClient(name)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	cl := s.chatclients[name]
+	for id, d := range cl.devices {
+		if !live[id] && d.streams == 0 {
+			cl.RemoveDevice(id)
+		}
+	}
+	s.ClientDevice(cl, device)
 	return &chat.Empty{}, nil
 }
 
-// removes a user from the server
+// GetUnreadCount returns the number of messages waiting in the mailbox of the device of the client.
+func (s *server) GetUnreadCount(ctx context.Context, in *chat.Empty) (*chat.UnreadCount, error) {
 
-func (s *server) UnRegister(ctx context.Context, in *chat.ChatClient) (*chat.Empty, error) {
+	name, _ := clientName(ctx)
+	device, _ := clientDevice(ctx)
 
-	cl := in.Sender
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	cl, ok := s.chatclients[name]
+	if !ok {
+		return &chat.UnreadCount{}, nil
+	}
+	return &chat.UnreadCount{Count: int32(s.ClientDevice(cl, device).mailbox.Len())}, nil
+}
 
-	log.Print("Unregistering client " + cl)
+// GetOverflowStats returns the overflow policy of a group and how many of its messages full mailboxes dropped.
+func (s *server) GetOverflowStats(ctx context.Context, in *chat.ChatGroup) (*chat.OverflowStats, error) {
 
-	err := s.RemoveClient(cl)
+	s.lock.RLock()
+	defer s.lock.RUnlock()
 
-	if err != nil {
-		return nil, err
+	g, ok := s.chatgroups[in.Name]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "group:"+in.Name+" doesn't exist")
+	}
+
+	policy := s.policy
+	if g.policy != nil {
+		policy = *g.policy
 	}
-	log.Print("Unregistered client " + cl)
+	return &chat.OverflowStats{Policy: policy.String(), Dropped: g.dropped}, nil
+}
+
+// removes the device of the call from the user, the user keeps its groups and gets the
+// messages sent while it has no device on its next login.
+
+func (s *server) UnRegister(ctx context.Context, in *chat.ChatClient) (*chat.Empty, error) {
+
+	cl := in.Sender
+	device, _ := clientDevice(ctx)
+
+	log.Print("Unregistering device " + device + " of client " + cl)
+
+	s.DropDevices(cl, []string{device})
 
 	return &chat.Empty{}, nil
 }
@@ -300,14 +780,41 @@ func (s *server) CreateChatGroup(ctx context.Context, in *chat.ChatGroup) (*chat
 	clName := in.Client
 	grpName := in.Name
 
-	log.Printf(clName + " is attempting creating " + grpName)
+	log.Print(clName + " is attempting creating " + grpName)
 
-	if !s.NotAvailableGroupName(grpName) {
-		s.AddChatGroup(grpName)
-		return &chat.Empty{}, nil
+	if strings.HasPrefix(grpName, dmPrefix) {
+		return nil, status.Error(codes.InvalidArgument, "group names can't start with "+dmPrefix)
+	}
+
+	var policy *mailbox.Policy
+	if in.OverflowPolicy != "" {
+		p, err := mailbox.ParsePolicy(in.OverflowPolicy)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		policy = &p
+	}
+
+	if in.Visibility == chat.Visibility_PASSWORD && in.Password == "" {
+		return nil, status.Error(codes.InvalidArgument, "a password protected group needs a password")
 	}
 
-	return &chat.Empty{}, errors.New("the group name is not available")
+	// checking the name, creating the group and letting the creator in are one step, two
+	// clients creating the same group can't both get it
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, live := s.chatgroups[grpName]
+	_, retired := s.retired[grpName]
+	if live || retired {
+		return &chat.Empty{}, errors.New("the group name is not available")
+	}
+	s.AddChatGroup(grpName, clName, policy, in.Visibility, in.Password)
+	// the creator is let in whatever the visibility
+	if _, ok := s.chatclients[clName]; ok {
+		s.AddGroupMember(clName, grpName)
+	}
+	return &chat.Empty{}, nil
 }
 
 // let a user to an existing group.
@@ -317,11 +824,25 @@ func (s *server) JoinChatGroup(ctx context.Context, in *chat.ChatGroup) (*chat.E
 	clName := in.Client
 	grpName := in.Name
 
-	log.Printf(clName + " is trying to joing group: " + grpName)
-
-	if s.NotAvailableGroupName(grpName) {
-		s.AddClientToChatGroup(clName, grpName)
+	log.Print(clName + " is trying to joing group: " + grpName)
 
+	if !s.GroupExists(grpName) {
+		if err := s.RestoreChatGroup(grpName, clName); err != nil {
+			return &chat.Empty{}, err
+		}
+	}
+	if s.GroupExists(grpName) {
+		s.lock.RLock()
+		_, live := s.chatgroups[grpName]
+		joined := live && s.ClientJoinedGroup(clName, grpName)
+		s.lock.RUnlock()
+
+		if joined {
+			return &chat.Empty{}, nil
+		}
+		if err := s.AddClientToChatGroup(clName, grpName, in.Password); err != nil {
+			return nil, err
+		}
 		return &chat.Empty{}, nil
 	}
 
@@ -340,145 +861,1912 @@ func (s *server) LeaveChatRoom(ctx context.Context, in *chat.ChatGroup) (*chat.E
 
 	} else if !s.RegisteredClient(clName) {
 		return &chat.Empty{}, errors.New("the client name " + clName + " is not registered")
+	} else if s.CheckMember(clName, grpName) != nil {
+		// already kicked or banned
+		return &chat.Empty{}, nil
 	} else {
-		msg := chat.Message{Sender: clName, Receiver: grpName, Body: clName + " left chat!\n"}
+		s.lock.Lock()
+		defer s.lock.Unlock()
 
-		s.BroadcastMessage(grpName, msg)
+		// every member hears of it, the chats of the client in the group end
+		s.PutLeave(s.chatgroups[grpName].clients, grpName, clName, false)
 		s.RemoveClientFromGroup(clName, grpName)
 		return &chat.Empty{}, nil
 	}
 }
 
-// Broadcast takes any messages that need to be sent and sorts them by group. It then
-// adds  messages to message channel of each member of a group.
-
-func (s *server) BroadcastMessage(grpName string, msg chat.Message) {
-
-	s.lock.Lock()
-	defer s.lock.Unlock()
-
-	for grp := range s.chatgroups {
-		log.Printf(grpName + ":")
-		if grp == grpName {
-			log.Printf(msg.Sender + " sent " + msg.Receiver + " a message: " + msg.Body)
-			for _, c := range s.chatgroups[grp].clients {
-
-				if c == msg.Sender && msg.Body == msg.Sender+" left chat!\n" {
-
-					s.chatclients[c].ch <- msg
-
-				} else if c != msg.Sender {
+// GetHistory pages through the stored messages of a group the client joined or of one of its conversations,
+// with how far each member read.
+// It returns the messages oldest first and an error.
+func (s *server) GetHistory(ctx context.Context, in *chat.HistoryRequest) (*chat.MessageList, error) {
 
-					log.Printf(msg.Sender + "sending message to " + c + "...")
-					s.chatclients[c].ch <- msg
-				}
-			}
+	var key string
+	ml := &chat.MessageList{Read: make(map[string]int64)}
+	if in.Conversation != "" {
+		name, _ := clientName(ctx)
+		conv, err := s.GetConversation(in.Conversation, name)
+		if err != nil {
+			return nil, err
 		}
-	}
-}
-
-// ListenToClient listens on the incoming stream for any messages. It adds those messages to the channel.
-// It doesn't return anything.
-func Listen(stream chat.ChatService_RouteChatServer, messages chan<- chat.Message) {
-
-	for {
-		msg, err := stream.Recv()
-		if err == io.EOF {
+		key = conv.key()
+		s.lock.RLock()
+		for member, seq := range conv.read {
+			ml.Read[member] = seq
 		}
-		if err != nil {
-		} else {
-			log.Printf(msg.Sender + " sent " + msg.Receiver + " a message: " + msg.Body)
-			messages <- *msg
+		s.lock.RUnlock()
+	} else if in.Group != nil {
+		if err := s.CheckMember(in.Group.Client, in.Group.Name); err != nil {
+			return nil, err
+		}
+		key = in.Group.Name
+		s.lock.RLock()
+		if g, ok := s.chatgroups[key]; ok {
+			for member, seq := range g.read {
+				ml.Read[member] = seq
+			}
 		}
+		s.lock.RUnlock()
+	} else {
+		return nil, status.Error(codes.InvalidArgument, "group or conversation is required")
+	}
 
+	l, err := s.history.History(key, in.Before, int(in.Limit))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	for i := range l {
+		ml.Messages = append(ml.Messages, &l[i])
+	}
+	return ml, nil
 }
 
-// RouteChat handles the routing of all messages on the stream.
-// It returns an error.
-func (s *server) RouteChat(stream chat.ChatService_RouteChatServer) error {
+// CheckMember checks that the group exists and the client joined it.
+// It returns a status error if not.
+func (s *server) CheckMember(clName string, grpName string) error {
 
-	msg, err := stream.Recv()
+	s.lock.RLock()
+	_, ok := s.chatgroups[grpName]
+	joined := ok && s.ClientJoinedGroup(clName, grpName)
+	s.lock.RUnlock()
 
-	if err != nil {
-		return err
+	if !ok {
+		return status.Error(codes.NotFound, "group:"+grpName+" doesn't exist")
+	} else if !joined {
+		return status.Error(codes.PermissionDenied, "you are not a member of "+grpName)
 	}
+	return nil
+}
 
-	log.Printf(msg.Sender + " sent " + msg.Receiver + " a message: " + msg.Body)
+// CanPost checks if a client may send messages to a group, it must be a member not muted.
+// It returns a status error if not.
+func (s *server) CanPost(clName string, grpName string) error {
 
-	outbox := make(chan chat.Message, 100)
+	if err := s.CheckMember(clName, grpName); err != nil {
+		return err
+	}
 
-	go Listen(stream, outbox)
+	s.lock.Lock()
+	defer s.lock.Unlock()
 
-	for {
-		select {
-		case outMsg := <-outbox:
-			s.BroadcastMessage(msg.Receiver, outMsg)
-		case inMsg := <-s.chatclients[msg.Sender].ch:
-			log.Println("Sending message to channel: ")
-			log.Println(s.chatclients[msg.Sender])
-			log.Println("Sending message to STREAM: ")
-			log.Println(stream)
-			stream.Send(&inMsg)
-		}
+	if g, ok := s.chatgroups[grpName]; ok && g.IsMuted(clName, time.Now()) {
+		return status.Error(codes.PermissionDenied, "you are muted in "+grpName)
 	}
+	return nil
 }
 
-func (s *server) genToken() string {
-	tkn := make([]byte, 4)
-	rand.Read(tkn)
-	return fmt.Sprintf("%x", tkn)
-}
-func (s *server) Login(ctx context.Context, req *chat.ClientLoginRequest) (*chat.ClientLoginResponse, error) {
-	switch {
-	case req.Password != s.Password:
-		return nil, status.Error(codes.Unauthenticated, "password is incorrect")
-	case req.Name == "":
-		return nil, status.Error(codes.InvalidArgument, "username is required")
-	}
+// Notice puts a message of the server about a group in the mailbox of a client, it is not stored.
+func (s *server) Notice(clName string, grpName string, body string) {
 
-	tkn := s.genToken()
-	s.setName(tkn, req.Name)
+	s.lock.Lock()
+	defer s.lock.Unlock()
 
-	log.Println(tkn + "," + req.Name + "has logged in")
+	if cl, ok := s.chatclients[clName]; ok {
+		msg := notice(grpName, body)
+		msg.Timestamp = time.Now().UnixNano()
+		cl.Put(msg, s.policy)
+	}
+}
 
-	return &chat.ClientLoginResponse{Token: tkn}, nil
+// notice returns a message of the server to a group.
+func notice(grpName string, text string) chat.Message {
+	return chat.Message{Sender: serverName, Receiver: grpName, Event: &chat.Message_Notice{Notice: &chat.Notice{Text: text}}}
 }
 
-//logout from server
-func (s *server) Logout(ctx context.Context, req *chat.ClientLogoutRequest) (*chat.ClientLogoutResponse, error) {
-	name, ok := s.deleteToken(req.Token)
+// PutLeave tells the clients that member left the group grpName, or was removed from it.
+// The server lock must be held.
+func (s *server) PutLeave(to []string, grpName string, member string, removed bool) {
+	msg := chat.Message{Sender: member, Receiver: grpName, Timestamp: time.Now().UnixNano(), Event: &chat.Message_Leave{Leave: &chat.Leave{Removed: removed}}}
+	for _, c := range to {
+		if cl, ok := s.chatclients[c]; ok {
+			cl.Put(msg, s.policy)
+		}
+	}
+}
+
+// Broadcast takes any messages that need to be sent and sorts them by group. It then
+// adds  messages to message channel of each member of a group but the device from, nil for
+// the notices of the server.
+// It returns the message as stored.
+
+func (s *server) BroadcastMessage(grpName string, msg chat.Message, from *Device) chat.Message {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for grp := range s.chatgroups {
+		log.Print(grpName + ":")
+		if grp == grpName {
+			s.chatgroups[grp].seq++
+			msg.Id = s.genID()
+			msg.Timestamp = time.Now().UnixNano()
+			msg.Seq = s.chatgroups[grp].seq
+			msg.Mentions = s.mentions(s.chatgroups[grp], msg.Body, msg.Sender)
+
+			log.Print(msg.Sender + " sent " + msg.Receiver + " a message")
+			if err := s.history.Append(grpName, msg); err != nil {
+				log.Printf("could not store message for %s: %v", grpName, err)
+			}
+			// the sender read its own message
+			if s.ClientJoinedGroup(msg.Sender, grp) {
+				s.chatgroups[grp].read[msg.Sender] = msg.Seq
+			}
+			from.Sent(msg.Id)
+			s.fanOut(s.chatgroups[grp], msg, from)
+			s.notifyMentions(s.chatgroups[grp], msg)
+		}
+	}
+	return msg
+}
+
+// mentions returns the members of g other than the sender named with @name in body, once each.
+// The server lock must be held.
+func (s *server) mentions(g *Group, body string, sender string) []string {
+
+	var names []string
+	for _, word := range strings.Fields(body) {
+		if !strings.HasPrefix(word, "@") {
+			continue
+		}
+		name := strings.TrimRight(word[1:], ".,:;!?)'\"")
+		if name != sender && s.ClientJoinedGroup(name, g.name) && indexOf(names, name) < 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// notifyMentions puts a Mention event in the mailbox of every client msg names, and keeps the
+// message in their mentions.
+// The server lock must be held.
+func (s *server) notifyMentions(g *Group, msg chat.Message) {
+
+	ev := chat.Message{Sender: msg.Sender, Receiver: g.name, Timestamp: msg.Timestamp, Event: &chat.Message_Mention{Mention: &chat.Mention{Id: msg.Id, Seq: msg.Seq, Body: quote(msg.Body)}}}
+	for _, name := range msg.Mentions {
+		cl, ok := s.chatclients[name]
+		if !ok {
+			continue
+		}
+		cl.mentions = append(cl.mentions, mention{group: g.name, id: msg.Id})
+		if len(cl.mentions) > maxMentions {
+			cl.mentions = cl.mentions[len(cl.mentions)-maxMentions:]
+		}
+		cl.Put(ev, s.policy)
+	}
+}
+
+// fanOut puts msg in the mailbox of every device of the members of g but the device from, the
+// one msg was sent from if any, full mailboxes apply the overflow policy of the group.
+// The server lock must be held.
+func (s *server) fanOut(g *Group, msg chat.Message, from *Device) {
+
+	policy := s.policy
+	if g.policy != nil {
+		policy = *g.policy
+	}
+	for _, c := range g.clients {
+
+		log.Print(msg.Sender + " sending message to " + c + "...")
+		if n := s.chatclients[c].PutFrom(msg, policy, from); n > 0 {
+			g.dropped += int64(n)
+			log.Printf("mailbox of %s is full (%v), dropped %d message(s)", c, policy, n)
+		}
+	}
+}
+
+// PutEvent puts an ephemeral event in the mailboxes of the clients, full mailboxes drop it
+// rather than anything else.
+// The server lock must be held.
+func (s *server) PutEvent(to []string, ev chat.Message) {
+	for _, c := range to {
+		if cl, ok := s.chatclients[c]; ok {
+			cl.Put(ev, mailbox.DropNewest)
+		}
+	}
+}
+
+// Relay passes the event of a client on to the others in its group or in conv if it is set,
+// a read receipt also moves the read position of the client. Events are never stored, typing
+// events of muted members and receipts going backwards are dropped.
+func (s *server) Relay(grpName string, conv *Conversation, ev chat.Message) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	out := chat.Message{Sender: ev.Sender, Timestamp: time.Now().UnixNano(), Event: ev.Event}
+	var to []string
+	var read map[string]int64
+	var last int64
+	if conv != nil {
+		out.Receiver, out.Conversation = conv.Peer(ev.Sender), conv.id
+		to, read, last = []string{out.Receiver}, conv.read, conv.seq
+	} else {
+		g, ok := s.chatgroups[grpName]
+		if !ok || !s.ClientJoinedGroup(ev.Sender, grpName) {
+			return
+		}
+		if ev.GetTyping() != nil && g.IsMuted(ev.Sender, time.Now()) {
+			return
+		}
+		out.Receiver = grpName
+		to, read, last = g.clients, g.read, g.seq
+	}
+
+	if r := ev.GetRead(); r != nil {
+		if r.Seq <= read[ev.Sender] || r.Seq > last {
+			return
+		}
+		read[ev.Sender] = r.Seq
+	}
+
+	var others []string
+	for _, c := range to {
+		if c != ev.Sender {
+			others = append(others, c)
+		}
+	}
+	s.PutEvent(others, out)
+}
+
+// GetConversation returns the conversation with the given id if clName takes part in it.
+// It returns the conversation and a status error.
+func (s *server) GetConversation(id string, clName string) (*Conversation, error) {
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	conv, ok := s.conversations[id]
+	if !ok || !conv.HasMember(clName) {
+		// other people's conversations are reported as missing
+		return nil, status.Error(codes.NotFound, "conversation "+id+" doesn't exist")
+	}
+	return conv, nil
+}
+
+// SendDirect stores msg in the conversation and puts it in the mailboxes of the other participant
+// and of the other devices of the sender than from, the one msg was sent from.
+// It returns the message as stored.
+func (s *server) SendDirect(conv *Conversation, msg chat.Message, from *Device) chat.Message {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	peer := conv.Peer(msg.Sender)
+	conv.seq++
+	msg.Id = s.genID()
+	msg.Timestamp = time.Now().UnixNano()
+	msg.Seq = conv.seq
+	msg.Receiver = peer
+	msg.Conversation = conv.id
+	conv.last = &msg
+
+	log.Print(msg.Sender + " sent a direct message to " + peer)
+	if err := s.history.Append(conv.key(), msg); err != nil {
+		log.Printf("could not store message for %s: %v", conv.key(), err)
+	}
+	conv.read[msg.Sender] = msg.Seq
+	from.Sent(msg.Id)
+
+	for _, c := range []string{peer, msg.Sender} {
+		if cl, ok := s.chatclients[c]; ok {
+			if n := cl.PutFrom(msg, s.policy, from); n > 0 {
+				log.Printf("mailbox of %s is full (%v), dropped %d message(s)", c, s.policy, n)
+			}
+		}
+	}
+	return msg
+}
+
+// SendDirectMessage sends a message to a single client, starting a conversation with them if needed.
+// It returns the message as stored and an error.
+func (s *server) SendDirectMessage(ctx context.Context, in *chat.Message) (*chat.Message, error) {
+
+	switch {
+	case in.Receiver == "":
+		return nil, status.Error(codes.InvalidArgument, "receiver is required")
+	case in.Receiver == in.Sender:
+		return nil, status.Error(codes.InvalidArgument, "you can't send a direct message to yourself")
+	case !s.RegisteredClient(in.Receiver):
+		return nil, status.Error(codes.NotFound, "the client name "+in.Receiver+" is not registered")
+	}
+
+	conv := s.OpenConversation(in.Sender, in.Receiver)
+	if err := s.thread(conv.key(), in); err != nil {
+		return nil, err
+	}
+	if err := s.attach(in); err != nil {
+		return nil, err
+	}
+	if err := s.sealed(conv, in); err != nil {
+		return nil, err
+	}
+	device, _ := clientDevice(ctx)
+	s.lock.RLock()
+	var from *Device
+	if cl, ok := s.chatclients[in.Sender]; ok {
+		from = cl.devices[device]
+	}
+	s.lock.RUnlock()
+	msg := s.SendDirect(conv, *in, from)
+	return &msg, nil
+}
+
+// OpenConversation returns the conversation between a and b, starting it if needed.
+func (s *server) OpenConversation(a string, b string) *Conversation {
+
+	id := conversationID(a, b)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	conv, ok := s.conversations[id]
 	if !ok {
-		return nil, status.Error(codes.NotFound, "token not found")
+		conv = &Conversation{id: id, members: [2]string{a, b}, read: make(map[string]int64)}
+		// a conversation started again carries on its history
+		seq, err := s.history.LastSeq(conv.key())
+		if err != nil {
+			log.Printf("could not read history of %s: %v", conv.key(), err)
+		}
+		conv.seq = seq
+		s.conversations[id] = conv
+		log.Print("started conversation between " + a + " and " + b)
+	}
+	return conv
+}
+
+// ListConversations returns the conversations of the client, latest activity first.
+func (s *server) ListConversations(ctx context.Context, in *chat.Empty) (*chat.ConversationList, error) {
+
+	name, _ := clientName(ctx)
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	cl := &chat.ConversationList{}
+	for _, conv := range s.conversations {
+		if !conv.HasMember(name) {
+			continue
+		}
+		c := &chat.Conversation{Id: conv.id, Peer: conv.Peer(name), LastSeq: conv.seq}
+		if conv.last != nil {
+			last := *conv.last
+			c.LastMessage = &last
+		}
+		cl.Conversations = append(cl.Conversations, c)
+	}
+
+	sort.Slice(cl.Conversations, func(i, j int) bool {
+		return cl.Conversations[i].GetLastMessage().GetTimestamp() > cl.Conversations[j].GetLastMessage().GetTimestamp()
+	})
+	return cl, nil
+}
+
+// storedMessage returns the chat message id stored under key. Notices and deleted messages are
+// reported as missing.
+// It returns the message and a status error. The server lock must be held.
+func (s *server) storedMessage(key string, id string) (chat.Message, error) {
+
+	if id == "" {
+		return chat.Message{}, status.Error(codes.InvalidArgument, "the id of the message is required")
+	}
+	msg, err := s.history.Find(key, id)
+	if err == store.ErrNotFound || (err == nil && (msg.Deleted || msg.Event != nil)) {
+		return chat.Message{}, status.Error(codes.NotFound, "message "+id+" doesn't exist")
+	} else if err != nil {
+		return chat.Message{}, status.Error(codes.Internal, err.Error())
+	}
+	return msg, nil
+}
+
+// Announce puts an event about a stored message of a group, or of conv if it is set, in the
+// mailboxes of all of its members. The sender gets it too, for its other devices.
+// The server lock must be held.
+func (s *server) Announce(grpName string, conv *Conversation, ev chat.Message) {
+
+	ev.Timestamp = time.Now().UnixNano()
+	if conv == nil {
+		if g, ok := s.chatgroups[grpName]; ok {
+			ev.Receiver = grpName
+			s.fanOut(g, ev, nil)
+		}
+		return
+	}
+
+	ev.Receiver, ev.Conversation = conv.Peer(ev.Sender), conv.id
+	for _, c := range conv.members {
+		if cl, ok := s.chatclients[c]; ok {
+			cl.Put(ev, s.policy)
+		}
+	}
+}
+
+// update stores the new version of msg and tells the room about it with ev.
+// It returns a status error. The server lock must be held.
+func (s *server) update(key string, conv *Conversation, msg chat.Message, ev chat.Message) error {
+
+	if err := s.history.Update(key, msg); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if conv != nil && conv.last != nil && conv.last.Seq == msg.Seq {
+		conv.last = &msg
+	}
+	s.Announce(msg.Receiver, conv, ev)
+	return nil
+}
+
+// EditMessage changes the body of a message of the client in a group or a conversation, the
+// members get an Edit event.
+// It returns the message as stored and an error.
+func (s *server) EditMessage(ctx context.Context, in *chat.Message) (*chat.Message, error) {
+
+	if strings.TrimSpace(in.Body) == "" {
+		return nil, status.Error(codes.InvalidArgument, "a message can't be empty, delete it instead")
+	}
+	key, conv, err := s.target(in.Sender, in)
+	if err != nil {
+		return nil, err
+	}
+	if conv == nil {
+		if err := s.CanPost(in.Sender, in.Receiver); err != nil {
+			return nil, err
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	msg, err := s.storedMessage(key, in.Id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Sender != in.Sender {
+		return nil, status.Error(codes.PermissionDenied, "only the sender of a message can edit it")
+	} else if msg.Sealed != nil {
+		return nil, status.Error(codes.FailedPrecondition, "an encrypted message can't be edited, delete it instead")
+	}
+
+	msg.Body = in.Body
+	msg.Edited = time.Now().UnixNano()
+	ev := chat.Message{Sender: in.Sender, Event: &chat.Message_Edit{Edit: &chat.Edit{Id: msg.Id, Seq: msg.Seq, Body: msg.Body}}}
+	if err := s.update(key, conv, msg, ev); err != nil {
+		return nil, err
+	}
+	log.Printf("%s edited message %s of %s", in.Sender, msg.Id, key)
+	return &msg, nil
+}
+
+// DeleteMessage removes a message from a group or a conversation, the members get a Delete
+// event. Its sender can delete it, and so can the admins of the group.
+// It returns an empty object and an error.
+func (s *server) DeleteMessage(ctx context.Context, in *chat.Message) (*chat.Empty, error) {
+
+	key, conv, err := s.target(in.Sender, in)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	msg, err := s.storedMessage(key, in.Id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Sender != in.Sender {
+		g, ok := s.chatgroups[in.Receiver]
+		if conv != nil || !ok || g.Role(in.Sender) < chat.Role_ADMIN {
+			return nil, status.Error(codes.PermissionDenied, "only the sender of a message or an admin can delete it")
+		}
+	}
+
+	msg.Body, msg.Sealed = "", nil
+	msg.Deleted = true
+	ev := chat.Message{Sender: in.Sender, Event: &chat.Message_Delete{Delete: &chat.Delete{Id: msg.Id, Seq: msg.Seq}}}
+	if err := s.update(key, conv, msg, ev); err != nil {
+		return nil, err
+	}
+	log.Printf("%s deleted message %s of %s", in.Sender, msg.Id, key)
+	return &chat.Empty{}, nil
+}
+
+// react adds the reaction of the client of in to a message, or removes it, and tells the room.
+// It returns the message as stored and a status error.
+func (s *server) react(in *chat.ReactionRequest, remove bool) (*chat.Message, error) {
+
+	e := strings.TrimSpace(in.Emoji)
+	if e == "" || len([]rune(e)) > maxEmoji || strings.ContainsAny(e, " \t\n") {
+		return nil, status.Errorf(codes.InvalidArgument, "a reaction is a single emoji of at most %d characters", maxEmoji)
+	}
+	key, conv, err := s.target(in.Sender, &chat.Message{Receiver: in.Group, Conversation: in.Conversation})
+	if err != nil {
+		return nil, err
+	}
+	if conv == nil && !remove {
+		if err := s.CanPost(in.Sender, in.Group); err != nil {
+			return nil, err
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	msg, err := s.storedMessage(key, in.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// the reactions are copied, the stored message may be shared with readers
+	var reactions []*chat.Reaction
+	var count int
+	changed := false
+	for _, r := range msg.Reactions {
+		r = &chat.Reaction{Emoji: r.Emoji, Senders: append([]string(nil), r.Senders...)}
+		if r.Emoji == e {
+			i := indexOf(r.Senders, in.Sender)
+			if remove && i >= 0 {
+				r.Senders = append(r.Senders[:i], r.Senders[i+1:]...)
+				changed = true
+			} else if !remove && i < 0 {
+				r.Senders = append(r.Senders, in.Sender)
+				changed = true
+			}
+			count = len(r.Senders)
+			if count == 0 {
+				continue
+			}
+		}
+		reactions = append(reactions, r)
+	}
+	if !remove && count == 0 {
+		if len(reactions) >= maxReactions {
+			return nil, status.Errorf(codes.ResourceExhausted, "a message has at most %d different reactions", maxReactions)
+		}
+		reactions = append(reactions, &chat.Reaction{Emoji: e, Senders: []string{in.Sender}})
+		count = 1
+		changed = true
+	}
+	msg.Reactions = reactions
+	if !changed {
+		return &msg, nil
+	}
+
+	ch := &chat.ReactionChange{Id: msg.Id, Seq: msg.Seq, Emoji: e, Removed: remove, Count: int32(count)}
+	ev := chat.Message{Sender: in.Sender, Event: &chat.Message_Reaction{Reaction: ch}}
+	if err := s.update(key, conv, msg, ev); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// indexOf returns the position of name in l, -1 if it is not there.
+func indexOf(l []string, name string) int {
+	for i, n := range l {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddReaction reacts to a message of a group or a conversation with an emoji, the members get a
+// ReactionChange event.
+// It returns the message as stored and an error.
+func (s *server) AddReaction(ctx context.Context, in *chat.ReactionRequest) (*chat.Message, error) {
+	return s.react(in, false)
+}
+
+// RemoveReaction takes back a reaction of the client to a message.
+// It returns the message as stored and an error.
+func (s *server) RemoveReaction(ctx context.Context, in *chat.ReactionRequest) (*chat.Message, error) {
+	return s.react(in, true)
+}
+
+// ListMentions returns the latest messages naming the client in the groups it is still in,
+// oldest first.
+// It returns the messages and an error.
+func (s *server) ListMentions(ctx context.Context, in *chat.Empty) (*chat.MessageList, error) {
+
+	name, _ := clientName(ctx)
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	cl, ok := s.chatclients[name]
+	if !ok {
+		return nil, status.Error(codes.FailedPrecondition, "the client name "+name+" is not registered")
+	}
+
+	ml := &chat.MessageList{}
+	for _, m := range cl.mentions {
+		if _, ok := s.chatgroups[m.group]; !ok || !s.ClientJoinedGroup(name, m.group) {
+			continue
+		}
+		if msg, err := s.history.Find(m.group, m.id); err == nil && !msg.Deleted {
+			ml.Messages = append(ml.Messages, &msg)
+		}
+	}
+	return ml, nil
+}
+
+// SearchMessages looks for the messages containing all the words of the query in a group of the
+// client, or in all of its groups and conversations.
+// It returns a page of the latest messages found and an error.
+func (s *server) SearchMessages(ctx context.Context, in *chat.SearchRequest) (*chat.SearchResults, error) {
+
+	name, _ := clientName(ctx)
+	if strings.TrimSpace(in.Query) == "" {
+		return nil, status.Error(codes.InvalidArgument, "search for at least one word")
+	}
+
+	var keys []string
+	if in.Group != "" {
+		if err := s.CheckMember(name, in.Group); err != nil {
+			return nil, err
+		}
+		keys = []string{in.Group}
+	} else {
+		s.lock.RLock()
+		for grpName := range s.chatgroups {
+			if s.ClientJoinedGroup(name, grpName) {
+				keys = append(keys, grpName)
+			}
+		}
+		for _, conv := range s.conversations {
+			if conv.HasMember(name) {
+				keys = append(keys, conv.key())
+			}
+		}
+		s.lock.RUnlock()
+	}
+
+	limit := int(in.Limit)
+	if limit <= 0 || limit > store.MaxPage {
+		limit = store.MaxPage
+	}
+	until := in.Until
+	if in.Before > 0 && (until == 0 || in.Before-1 < until) {
+		until = in.Before - 1
+	}
+
+	l, err := s.history.Search(store.Query{Text: in.Query, Groups: keys, Sender: in.Sender, Since: in.Since, Until: until, Limit: limit})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	res := &chat.SearchResults{}
+	for i := range l {
+		res.Messages = append(res.Messages, &l[i])
+	}
+	if len(l) == limit {
+		res.Next = l[len(l)-1].Timestamp
+	}
+	return res, nil
+}
+
+// attach checks the attachment of the new message msg, its sender must have uploaded the file.
+// The size is the server's to set and the name loses its directories.
+// It returns a status error.
+func (s *server) attach(msg *chat.Message) error {
+
+	a := msg.Attachment
+	if a == nil {
+		return nil
+	}
+
+	s.lock.RLock()
+	uploaded := s.uploads[a.Id][msg.Sender]
+	s.lock.RUnlock()
+
+	size, err := s.blobs.Stat(a.Id)
+	if !uploaded || err == blobs.ErrNotFound {
+		return status.Error(codes.NotFound, "upload the attachment before sharing it")
+	} else if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	name := filepath.Base(strings.Replace(a.Name, "\\", "/", -1))
+	if name == "." || name == "/" {
+		name = "attachment"
+	}
+	msg.Attachment = &chat.Attachment{Id: a.Id, Name: name, Size: size}
+	return nil
+}
+
+// UploadAttachment stores a file sent in chunks, the first one with the sha256 of the whole file.
+// The client can then attach it to its messages.
+// It returns an error.
+func (s *server) UploadAttachment(stream chat.ChatService_UploadAttachmentServer) error {
+
+	name, _ := clientName(stream.Context())
+
+	w, err := s.blobs.Create()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer w.Abort()
+
+	var sum string
+	for first := true; ; first = false {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if first {
+			sum = chunk.Sha256
+		}
+		if len(chunk.Data) > maxChunk {
+			return status.Errorf(codes.InvalidArgument, "a chunk has at most %d bytes", maxChunk)
+		}
+		if _, err := w.Write(chunk.Data); err == blobs.ErrTooLarge {
+			return status.Errorf(codes.ResourceExhausted, "an attachment has at most %d bytes", s.blobs.Max())
+		} else if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	if sum == "" {
+		return status.Error(codes.InvalidArgument, "the sha256 of the file is required")
+	}
+	id, size, err := w.Commit(sum)
+	if err == blobs.ErrChecksum {
+		return status.Error(codes.DataLoss, err.Error())
+	} else if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	s.lock.Lock()
+	if s.uploads[id] == nil {
+		s.uploads[id] = make(map[string]bool)
+	}
+	s.uploads[id][name] = true
+	s.lock.Unlock()
+
+	log.Printf("%s uploaded attachment %s (%d bytes)", name, id, size)
+	return stream.SendAndClose(&chat.Attachment{Id: id, Size: size})
+}
+
+// DownloadAttachment sends the file attached to a message of a group or a conversation of the
+// client in chunks, the first one with its sha256 and its size.
+// It returns an error.
+func (s *server) DownloadAttachment(in *chat.AttachmentRequest, stream chat.ChatService_DownloadAttachmentServer) error {
+
+	name, _ := clientName(stream.Context())
+	key, _, err := s.target(name, &chat.Message{Receiver: in.Group, Conversation: in.Conversation})
+	if err != nil {
+		return err
+	}
+
+	msg, err := s.history.Find(key, in.Message)
+	if err == store.ErrNotFound || (err == nil && (msg.Deleted || msg.Attachment == nil)) {
+		return status.Error(codes.NotFound, "message "+in.Message+" has no attachment")
+	} else if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	f, err := s.blobs.Open(msg.Attachment.Id)
+	if err == blobs.ErrNotFound {
+		return status.Error(codes.NotFound, "the attachment of message "+in.Message+" is gone")
+	} else if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer f.Close()
+
+	chunk := &chat.AttachmentChunk{Sha256: msg.Attachment.Id, Size: msg.Attachment.Size}
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			chunk.Data = buf[:n]
+			if err := stream.Send(chunk); err != nil {
+				return err
+			}
+			chunk = &chat.AttachmentChunk{}
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+	// an empty file still gets its first chunk
+	if chunk.Sha256 != "" {
+		return stream.Send(chunk)
+	}
+	return nil
+}
+
+// quote returns the start of the first line of body, as a reply shows it.
+func quote(body string) string {
+
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(body), "\n", 2)[0])
+	if r := []rune(line); len(r) > quoteSize {
+		line = string(r[:quoteSize]) + "…"
+	}
+	return line
+}
+
+// thread links the new message msg to the message stored under key it replies to, if any, and
+// quotes it. The edit marks, the thread, the quote, the reactions and the mentions are the
+// server's to set, whatever the client sent in them is dropped.
+// It returns a status error.
+func (s *server) thread(key string, msg *chat.Message) error {
+
+	msg.Edited, msg.Deleted, msg.Thread, msg.Quote = 0, false, "", nil
+	msg.Reactions, msg.Mentions = nil, nil
+	if msg.ReplyTo == "" {
+		return nil
+	}
+
+	s.lock.RLock()
+	parent, err := s.storedMessage(key, msg.ReplyTo)
+	s.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	msg.Thread = parent.Thread
+	if msg.Thread == "" {
+		msg.Thread = parent.Id
+	}
+	msg.Quote = &chat.Quote{Sender: parent.Sender, Body: quote(parent.Body)}
+	if parent.Sealed != nil {
+		msg.Quote.Body = "(encrypted)"
+	}
+	return nil
+}
+
+// sealed checks the encrypted body of the new message msg of conv, if it has one. The server
+// can't read it, it only makes sure it is a direct message with no body in clear, sent with a
+// key the sender published.
+// It returns a status error.
+func (s *server) sealed(conv *Conversation, msg *chat.Message) error {
+
+	b := msg.Sealed
+	switch {
+	case b == nil:
+		return nil
+	case conv == nil:
+		return status.Error(codes.InvalidArgument, "only direct messages can be encrypted")
+	case msg.Body != "":
+		return status.Error(codes.InvalidArgument, "an encrypted message can't have a body in clear")
+	case len(b.SenderKey) != e2e.KeySize:
+		return status.Errorf(codes.InvalidArgument, "the keys of an encrypted message are %d bytes", e2e.KeySize)
+	case len(b.Copies) == 0:
+		return status.Error(codes.InvalidArgument, "an encrypted message can't be empty")
+	}
+	for _, cp := range b.Copies {
+		if len(cp.Key) != e2e.KeySize {
+			return status.Errorf(codes.InvalidArgument, "the keys of an encrypted message are %d bytes", e2e.KeySize)
+		} else if len(cp.Ciphertext) == 0 {
+			return status.Error(codes.InvalidArgument, "an encrypted message can't be empty")
+		}
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for _, key := range s.keys[msg.Sender] {
+		if bytes.Equal(key, b.SenderKey) {
+			return nil
+		}
+	}
+	return status.Error(codes.FailedPrecondition, "the message is sealed with a key you didn't publish, publish it first")
+}
+
+// PublishKey sets the public key the device of the client encrypts its direct messages with,
+// its peers get it with GetKeys. The keys of the devices the client left are dropped.
+// It returns an empty object and an error.
+func (s *server) PublishKey(ctx context.Context, in *chat.PublicKey) (*chat.Empty, error) {
+
+	if len(in.Key) != e2e.KeySize {
+		return nil, status.Errorf(codes.InvalidArgument, "a public key is %d bytes", e2e.KeySize)
+	}
+	name, _ := clientName(ctx)
+	device, _ := clientDevice(ctx)
+	live := s.tokens.Devices(name)
+
+	s.lock.Lock()
+	keys := s.keys[name]
+	if keys == nil {
+		keys = make(map[string][]byte)
+		s.keys[name] = keys
+	}
+	for d := range keys {
+		if !live[d] {
+			delete(keys, d)
+		}
+	}
+	changed := keys[device] != nil && !bytes.Equal(keys[device], in.Key)
+	keys[device] = append([]byte{}, in.Key...)
+	s.lock.Unlock()
+
+	if changed {
+		log.Print(name + " published a new key")
+	}
+	return &chat.Empty{}, nil
+}
+
+// GetKeys returns the public keys the devices client name is logged in on published.
+// It returns the keys and an error, NotFound if the client has none.
+func (s *server) GetKeys(ctx context.Context, in *chat.PublicKey) (*chat.KeyList, error) {
+
+	live := s.tokens.Devices(in.Name)
+
+	s.lock.RLock()
+	var keys [][]byte
+	for d, key := range s.keys[in.Name] {
+		if live[d] {
+			keys = append(keys, key)
+		}
+	}
+	s.lock.RUnlock()
+
+	if len(keys) == 0 {
+		return nil, status.Error(codes.NotFound, in.Name+" has no key")
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	return &chat.KeyList{Name: in.Name, Keys: keys}, nil
+}
+
+// GetThread returns the latest messages of the thread of a message of a group or a conversation,
+// its first message first.
+// It returns the messages and an error.
+func (s *server) GetThread(ctx context.Context, in *chat.HistoryRequest) (*chat.MessageList, error) {
+
+	var key string
+	if in.Conversation != "" {
+		name, _ := clientName(ctx)
+		conv, err := s.GetConversation(in.Conversation, name)
+		if err != nil {
+			return nil, err
+		}
+		key = conv.key()
+	} else if in.Group != nil {
+		if err := s.CheckMember(in.Group.Client, in.Group.Name); err != nil {
+			return nil, err
+		}
+		key = in.Group.Name
+	} else {
+		return nil, status.Error(codes.InvalidArgument, "group or conversation is required")
+	}
+
+	msg, err := s.history.Find(key, in.Thread)
+	if err == store.ErrNotFound {
+		return nil, status.Error(codes.NotFound, "message "+in.Thread+" doesn't exist")
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	root := msg.Thread
+	if root == "" {
+		root = msg.Id
+	}
+
+	l, err := s.history.Thread(key, root, int(in.Limit))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	ml := &chat.MessageList{}
+	for i := range l {
+		ml.Messages = append(ml.Messages, &l[i])
+	}
+	return ml, nil
+}
+
+// expire marks the invitation as expired if it is still pending past its expiry time.
+// The server lock must be held.
+func (s *server) expire(inv *chat.Invitation, now time.Time) {
+	if inv.State == chat.InvitationState_PENDING && now.UnixNano() > inv.Expires {
+		inv.State = chat.InvitationState_EXPIRED
+		s.notify(inv)
+	}
+}
+
+// pruneInvitations expires the pending invitations past their expiry time, and forgets the
+// invitations that expired longer than the invitation lifetime ago, answered or not.
+// The server lock must be held.
+func (s *server) pruneInvitations(now time.Time) {
+	for id, inv := range s.invitations {
+		s.expire(inv, now)
+		if now.UnixNano() > inv.Expires+int64(s.inviteTTL) {
+			delete(s.invitations, id)
+		}
+	}
+}
+
+// notify pushes inv to the invitation streams of the inviter and the invitee.
+// A stream too slow to take it misses it, it can still list the invitations.
+// The server lock must be held.
+func (s *server) notify(inv *chat.Invitation) {
+	for _, name := range []string{inv.Inviter, inv.Invitee} {
+		for _, w := range s.watchers[name] {
+			select {
+			case w <- *inv:
+			default:
+			}
+		}
+	}
+}
+
+// SendInvitation invites a client to a group the inviter joined, or to a direct conversation if no group is given.
+// It returns the invitation and an error.
+func (s *server) SendInvitation(ctx context.Context, in *chat.Invitation) (*chat.Invitation, error) {
+
+	switch {
+	case in.Invitee == "":
+		return nil, status.Error(codes.InvalidArgument, "invitee is required")
+	case in.Invitee == in.Inviter:
+		return nil, status.Error(codes.InvalidArgument, "you can't invite yourself")
+	case !s.RegisteredClient(in.Invitee):
+		return nil, status.Error(codes.NotFound, "the client name "+in.Invitee+" is not registered")
+	}
+
+	if in.Group != "" {
+		if err := s.CheckMember(in.Inviter, in.Group); err != nil {
+			return nil, err
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if in.Group != "" && s.ClientJoinedGroup(in.Invitee, in.Group) {
+		return nil, status.Error(codes.AlreadyExists, in.Invitee+" already joined "+in.Group)
+	} else if in.Group != "" && s.chatgroups[in.Group].banned[in.Invitee] {
+		return nil, status.Error(codes.FailedPrecondition, in.Invitee+" is banned from "+in.Group)
+	}
+
+	now := time.Now()
+	s.pruneInvitations(now)
+	for _, inv := range s.invitations {
+		if inv.State == chat.InvitationState_PENDING && inv.Inviter == in.Inviter &&
+			inv.Invitee == in.Invitee && inv.Group == in.Group {
+			return nil, status.Error(codes.AlreadyExists, "invitation already sent")
+		}
+	}
+
+	inv := &chat.Invitation{
+		Id:      s.genID(),
+		Inviter: in.Inviter,
+		Invitee: in.Invitee,
+		Group:   in.Group,
+		Created: now.UnixNano(),
+		Expires: now.Add(s.inviteTTL).UnixNano(),
+		State:   chat.InvitationState_PENDING,
+	}
+	s.invitations[inv.Id] = inv
+	s.notify(inv)
+
+	log.Print(inv.Inviter + " invited " + inv.Invitee + " to " + inv.Group)
+	res := *inv
+	return &res, nil
+}
+
+// ListInvitations returns the invitations the client sent or received, latest first.
+func (s *server) ListInvitations(ctx context.Context, in *chat.Empty) (*chat.InvitationList, error) {
+
+	name, _ := clientName(ctx)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.pruneInvitations(time.Now())
+	l := &chat.InvitationList{}
+	for _, inv := range s.invitations {
+		if inv.Inviter != name && inv.Invitee != name {
+			continue
+		}
+		i := *inv
+		l.Invitations = append(l.Invitations, &i)
+	}
+
+	sort.Slice(l.Invitations, func(i, j int) bool {
+		return l.Invitations[i].Created > l.Invitations[j].Created
+	})
+	return l, nil
+}
+
+// RespondInvitation accepts or declines a pending invitation of the client. Accepting joins
+// the group, or starts the direct conversation with the inviter.
+// It returns the invitation and an error.
+func (s *server) RespondInvitation(ctx context.Context, in *chat.InvitationResponse) (*chat.Invitation, error) {
+
+	name, _ := clientName(ctx)
+
+	s.lock.Lock()
+	inv, ok := s.invitations[in.Id]
+	if !ok || inv.Invitee != name {
+		s.lock.Unlock()
+		return nil, status.Error(codes.NotFound, "invitation "+in.Id+" doesn't exist")
+	}
+	s.expire(inv, time.Now())
+	if inv.State != chat.InvitationState_PENDING {
+		state := inv.State.String()
+		s.lock.Unlock()
+		return nil, status.Error(codes.FailedPrecondition, "invitation is "+strings.ToLower(state))
+	}
+	s.lock.Unlock()
+
+	state := chat.InvitationState_DECLINED
+	var conv string
+	if in.Accept {
+		if inv.Group != "" {
+			if !s.GroupExists(inv.Group) {
+				return nil, status.Error(codes.NotFound, "group:"+inv.Group+" doesn't exist anymore")
+			}
+			if err := s.AddClientToChatGroup(name, inv.Group, ""); err != nil {
+				return nil, status.Error(codes.FailedPrecondition, err.Error())
+			}
+		} else {
+			conv = s.OpenConversation(inv.Inviter, name).id
+		}
+		state = chat.InvitationState_ACCEPTED
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	inv.State = state
+	inv.Conversation = conv
+	s.notify(inv)
+
+	log.Print(name + " " + strings.ToLower(state.String()) + " the invitation of " + inv.Inviter)
+	res := *inv
+	return &res, nil
+}
+
+// WatchInvitations streams the invitations of the client as they are sent, answered or expire,
+// starting with the pending ones.
+// It returns an error.
+func (s *server) WatchInvitations(in *chat.Empty, stream chat.ChatService_WatchInvitationsServer) error {
+
+	name, _ := clientName(stream.Context())
+	w := make(chan chat.Invitation, 16)
+
+	s.lock.Lock()
+	var pending []chat.Invitation
+	s.pruneInvitations(time.Now())
+	for _, inv := range s.invitations {
+		if inv.Invitee == name && inv.State == chat.InvitationState_PENDING {
+			pending = append(pending, *inv)
+		}
+	}
+	s.watchers[name] = append(s.watchers[name], w)
+	s.lock.Unlock()
+
+	defer func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		l := s.watchers[name]
+		for i := range l {
+			if l[i] == w {
+				s.watchers[name] = append(l[:i], l[i+1:]...)
+				break
+			}
+		}
+	}()
+
+	for i := range pending {
+		if err := stream.Send(&pending[i]); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case inv := <-w:
+			if err := stream.Send(&inv); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return s.streamEnded(stream.Context())
+		}
+	}
+}
+
+// SetPresence sets the state and the status text the others see of the client.
+// It returns an empty object and an error.
+func (s *server) SetPresence(ctx context.Context, in *chat.Presence) (*chat.Empty, error) {
+
+	name, _ := clientName(ctx)
+	if err := s.presence.Set(name, in.State, in.Status); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	log.Print(name + " is now " + strings.ToLower(in.State.String()))
+	return &chat.Empty{}, nil
+}
+
+// WatchPresence streams the presence of the clients, the first list holds everyone and the
+// next ones the changes. The stream ends if the client falls behind, it watches again.
+// It returns an error.
+func (s *server) WatchPresence(in *chat.Empty, stream chat.ChatService_WatchPresenceServer) error {
+
+	all, w, done := s.presence.Watch()
+	defer done()
+
+	l := &chat.PresenceList{}
+	for i := range all {
+		l.Presences = append(l.Presences, &all[i])
+	}
+	if err := stream.Send(l); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case p, ok := <-w:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "too slow to read presence changes, watch again")
+			}
+			if err := stream.Send(&chat.PresenceList{Presences: []*chat.Presence{&p}}); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return s.streamEnded(stream.Context())
+		}
+	}
+}
+
+// moderate checks that the client of in has at least the role need in the group and ranks
+// above the member it acts on. With joined set the member must be in the group.
+// It returns the group and a status error. The server lock must be held.
+func (s *server) moderate(in *chat.ModerationRequest, need chat.Role, joined bool) (*Group, error) {
+
+	g, ok := s.chatgroups[in.Group]
+	switch {
+	case !ok:
+		return nil, status.Error(codes.NotFound, "group:"+in.Group+" doesn't exist")
+	case !s.ClientJoinedGroup(in.Client, in.Group):
+		return nil, status.Error(codes.PermissionDenied, "you are not a member of "+in.Group)
+	case in.Member == "" || in.Member == in.Client:
+		return nil, status.Error(codes.InvalidArgument, "name another member of "+in.Group)
+	case g.Role(in.Client) < need:
+		return nil, status.Error(codes.PermissionDenied, "that needs the "+strings.ToLower(need.String())+" role in "+in.Group)
+	case s.chatclients[in.Member] == nil:
+		return nil, status.Error(codes.NotFound, "the client name "+in.Member+" is not registered")
+	case joined && !s.ClientJoinedGroup(in.Member, in.Group):
+		return nil, status.Error(codes.NotFound, in.Member+" is not a member of "+in.Group)
+	case g.Role(in.Member) >= g.Role(in.Client):
+		return nil, status.Error(codes.PermissionDenied, "you can't do that to "+in.Member)
+	}
+	return g, nil
+}
+
+// expel removes a member from a group telling the group why, and ends the chat of the member
+// in the group.
+func (s *server) expel(grpName string, member string, why string) {
+
+	s.BroadcastMessage(grpName, notice(grpName, why), nil)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.chatgroups[grpName]; !ok || !s.ClientJoinedGroup(member, grpName) {
+		return
+	}
+	s.RemoveClientFromGroup(member, grpName)
+	s.PutLeave([]string{member}, grpName, member, true)
+	log.Print(why)
+}
+
+// KickMember removes a member from a group, they can join it again.
+// It returns an empty object and an error.
+func (s *server) KickMember(ctx context.Context, in *chat.ModerationRequest) (*chat.Empty, error) {
+
+	s.lock.Lock()
+	_, err := s.moderate(in, chat.Role_ADMIN, true)
+	s.lock.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	s.expel(in.Group, in.Member, in.Member+" was kicked by "+in.Client)
+	return &chat.Empty{}, nil
+}
+
+// BanMember removes a client from a group and keeps them from joining it again, or lets them
+// back in if in.Lift is set.
+// It returns an empty object and an error.
+func (s *server) BanMember(ctx context.Context, in *chat.ModerationRequest) (*chat.Empty, error) {
+
+	s.lock.Lock()
+	g, err := s.moderate(in, chat.Role_ADMIN, false)
+	var joined bool
+	if err == nil {
+		if in.Lift {
+			delete(g.banned, in.Member)
+		} else {
+			g.banned[in.Member] = true
+			joined = s.ClientJoinedGroup(in.Member, in.Group)
+		}
+	}
+	s.lock.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	if in.Lift {
+		log.Print(in.Member + " was unbanned from " + in.Group + " by " + in.Client)
+	} else if joined {
+		s.expel(in.Group, in.Member, in.Member+" was banned by "+in.Client)
+	} else {
+		log.Print(in.Member + " was banned from " + in.Group + " by " + in.Client)
+	}
+	return &chat.Empty{}, nil
+}
+
+// MuteMember keeps a member from sending messages to a group for in.Duration seconds, until
+// unmuted if 0, or unmutes them if in.Lift is set.
+// It returns an empty object and an error.
+func (s *server) MuteMember(ctx context.Context, in *chat.ModerationRequest) (*chat.Empty, error) {
+
+	if in.Duration < 0 {
+		return nil, status.Error(codes.InvalidArgument, "duration can't be negative")
+	}
+	d := time.Duration(in.Duration) * time.Second
+
+	s.lock.Lock()
+	g, err := s.moderate(in, chat.Role_ADMIN, true)
+	if err == nil {
+		switch {
+		case in.Lift:
+			delete(g.muted, in.Member)
+		case d > 0:
+			g.muted[in.Member] = time.Now().Add(d)
+		default:
+			g.muted[in.Member] = time.Time{}
+		}
+	}
+	s.lock.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	notice := "you were muted in " + in.Group + " by " + in.Client
+	if in.Lift {
+		notice = "you were unmuted in " + in.Group + " by " + in.Client
+	} else if d > 0 {
+		notice += " for " + d.String()
+	}
+	s.Notice(in.Member, in.Group, notice)
+	log.Print(in.Member + ": " + notice)
+	return &chat.Empty{}, nil
+}
+
+// PromoteMember makes a member admin of a group, or a plain member again if in.Lift is set.
+// Only the owner can.
+// It returns an empty object and an error.
+func (s *server) PromoteMember(ctx context.Context, in *chat.ModerationRequest) (*chat.Empty, error) {
+
+	s.lock.Lock()
+	g, err := s.moderate(in, chat.Role_OWNER, true)
+	if err == nil {
+		if in.Lift {
+			delete(g.admins, in.Member)
+		} else {
+			g.admins[in.Member] = true
+		}
+	}
+	s.lock.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	body := in.Member + " is now admin of " + in.Group
+	if in.Lift {
+		body = in.Member + " is no longer admin of " + in.Group
+	}
+	s.BroadcastMessage(in.Group, notice(in.Group, body), nil)
+	return &chat.Empty{}, nil
+}
+
+// TransferOwnership hands a group over to another member, the old owner stays admin.
+// It returns an empty object and an error.
+func (s *server) TransferOwnership(ctx context.Context, in *chat.ModerationRequest) (*chat.Empty, error) {
+
+	s.lock.Lock()
+	g, err := s.moderate(in, chat.Role_OWNER, true)
+	if err == nil {
+		g.owner = in.Member
+		delete(g.admins, in.Member)
+		g.admins[in.Client] = true
+	}
+	s.lock.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	s.BroadcastMessage(in.Group, notice(in.Group, in.Member+" now owns "+in.Group), nil)
+	return &chat.Empty{}, nil
+}
+
+// historyKey returns the name the history of msg is stored under.
+func historyKey(msg *chat.Message) string {
+	if msg.Conversation != "" {
+		return dmPrefix + msg.Conversation
+	}
+	return msg.Receiver
+}
+
+// ListenToClient listens on the incoming stream for any messages. It adds those messages to the channel.
+// It sends the error ending the stream on errc and returns.
+func Listen(stream chat.ChatService_RouteChatServer, messages chan<- chat.Message, errc chan<- error) {
+
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			errc <- err
+			return
+		}
+
+		log.Print(msg.Sender + " sent " + msg.Receiver + " a message")
+		select {
+		case messages <- *msg:
+		case <-stream.Context().Done():
+			return
+		}
 	}
-	log.Printf(name + " logged out")
-	return new(chat.ClientLogoutResponse), nil
 }
 
-func (s *server) getName(tkn string) (string, bool) {
+// ReplayMessages sends the stream of the device d of the client every message stored under key from
+// sequence number from onwards, except the ones sent from d itself.
+// It returns the sequence number of the last message replayed and an error.
+func (s *server) ReplayMessages(stream chat.ChatService_RouteChatServer, clName string, d *Device, key string, from int64) (int64, error) {
+
 	s.lock.RLock()
-	name, ok := s.clienttoken[tkn]
+	sent := make(map[string]bool)
+	for _, id := range d.sent {
+		sent[id] = true
+	}
 	s.lock.RUnlock()
-	return name, ok
+
+	last := from - 1
+	for {
+		l, err := s.history.Since(key, last, store.MaxPage)
+		if err != nil {
+			return last, status.Error(codes.Internal, err.Error())
+		}
+		if len(l) == 0 {
+			return last, nil
+		}
+
+		for i := range l {
+			if sent[l[i].Id] {
+				continue
+			}
+			if err := stream.Send(&l[i]); err != nil {
+				return last, err
+			}
+		}
+		last = l[len(l)-1].Seq
+		log.Printf("replayed %d message(s) of %s to %s", len(l), key, clName)
+	}
 }
 
-func (s *server) setName(tkn string, name string) {
-	s.lock.Lock()
-	s.clienttoken[tkn] = name
-	s.lock.Unlock()
+// subscription is a room a RouteChat stream gets the messages of.
+type subscription struct {
+	group string
+	conv  *Conversation
+	last  int64 // sequence number of the last message replayed from the history
+}
+
+// target returns the history key of the group or conversation msg goes to, and the
+// conversation if it is one, after checking that the client takes part in it.
+// It returns the key, the conversation and a status error.
+func (s *server) target(clName string, msg *chat.Message) (string, *Conversation, error) {
+
+	switch {
+	case msg.Conversation != "":
+		conv, err := s.GetConversation(msg.Conversation, clName)
+		if err != nil {
+			return "", nil, err
+		}
+		return conv.key(), conv, nil
+	case msg.Receiver != "":
+		if err := s.CheckMember(clName, msg.Receiver); err != nil {
+			return "", nil, err
+		}
+		return msg.Receiver, nil, nil
+	}
+	return "", nil, status.Error(codes.InvalidArgument, "a receiver group or a conversation is required")
 }
 
-func (s *server) deleteToken(tkn string) (name string, ok bool) {
-	name, ok = s.getName(tkn)
+// RouteChat handles the routing of all messages on the stream.
+// Every message goes to its own receiver group, or direct conversation if it names one. The
+// stream gets the messages of the rooms it subscribed to, a subscription with a resume marker
+// first replays the messages the client missed since. Chat messages are acknowledged or refused
+// on the stream.
+// It returns an error.
+func (s *server) RouteChat(stream chat.ChatService_RouteChatServer) error {
+
+	name, _ := clientName(stream.Context())
+	device, _ := clientDevice(stream.Context())
 
+	s.lock.Lock()
+	cl, ok := s.chatclients[name]
+	var d *Device
 	if ok {
+		d = s.ClientDevice(cl, device)
+		d.streams++
+	}
+	s.lock.Unlock()
+
+	if !ok {
+		return status.Error(codes.FailedPrecondition, "the client name "+name+" is not registered")
+	}
+
+	defer func() {
 		s.lock.Lock()
-		delete(s.chatclients, tkn)
+		d.streams--
 		s.lock.Unlock()
+	}()
+
+	subs := make(map[string]*subscription)
+
+	// reply answers a message of the client with an event of the server about its room
+	reply := func(to *chat.Message, ev chat.Message) error {
+		ev.Sender, ev.Receiver, ev.Conversation = serverName, to.Receiver, to.Conversation
+		return stream.Send(&ev)
+	}
+	refuse := func(to *chat.Message, err error) error {
+		st := status.Convert(err)
+		return reply(to, chat.Message{Event: &chat.Message_Error{Error: &chat.Error{Code: int32(st.Code()), Message: st.Message()}}})
 	}
 
-	return name, ok
+	// deliver sends everything waiting in the mailbox for the rooms of the stream and the
+	// mentions of the client. The messages of the other rooms are parked until the stream joins
+	// them, without taking the room of the new ones, and the ones that could not be sent are
+	// kept for the next stream.
+	deliver := func() error {
+		l, err := d.mailbox.Drain()
+		if err == mailbox.ErrTooSlow {
+			return status.Error(codes.ResourceExhausted, "too slow to read messages, reconnect to resume")
+		} else if err != nil {
+			log.Printf("could not read the spilled messages of %s: %v", name, err)
+		}
+		var keep []chat.Message
+		for i := range l {
+			key := historyKey(&l[i])
+			sub, ok := subs[key]
+			if !ok && l[i].GetMention() == nil {
+				keep = append(keep, l[i])
+				continue
+			}
+			// already replayed from the history, notices are not stored
+			if ok && l[i].Seq > 0 && l[i].Seq <= sub.last {
+				continue
+			}
+			if err := stream.Send(&l[i]); err != nil {
+				d.mailbox.Park(keep)
+				d.mailbox.Requeue(l[i:])
+				return err
+			}
+			// out of the group, by itself or removed
+			if l[i].GetLeave() != nil && l[i].Sender == name {
+				delete(subs, key)
+			}
+		}
+		if len(keep) > 0 {
+			d.mailbox.Park(keep)
+		}
+		return nil
+	}
+
+	outbox := make(chan chat.Message, 100)
+	errc := make(chan error, 1)
+
+	go Listen(stream, outbox, errc)
+
+	// mentions that waited for the client
+	if err := deliver(); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case outMsg := <-outbox:
+			key, conv, err := s.target(name, &outMsg)
+			if err != nil {
+				if err := refuse(&outMsg, err); err != nil {
+					return err
+				}
+				continue
+			}
+
+			switch ev := outMsg.Event.(type) {
+			case nil:
+				var sent chat.Message
+				if conv == nil {
+					err = s.CanPost(name, outMsg.Receiver)
+				}
+				if err == nil {
+					err = s.thread(key, &outMsg)
+				}
+				if err == nil {
+					err = s.attach(&outMsg)
+				}
+				if err == nil {
+					err = s.sealed(conv, &outMsg)
+				}
+				if err == nil && conv != nil {
+					sent = s.SendDirect(conv, outMsg, d)
+				} else if err == nil {
+					sent = s.BroadcastMessage(outMsg.Receiver, outMsg, d)
+				}
+				if err != nil {
+					err = refuse(&outMsg, err)
+				} else {
+					err = reply(&outMsg, chat.Message{Event: &chat.Message_Ack{Ack: &chat.Ack{Id: sent.Id, Seq: sent.Seq, Timestamp: sent.Timestamp}}})
+				}
+			case *chat.Message_Typing, *chat.Message_Read:
+				s.Relay(outMsg.Receiver, conv, outMsg)
+			case *chat.Message_Subscribe:
+				sub := &subscription{group: outMsg.Receiver, conv: conv}
+				if ev.Subscribe.ResumeFrom > 0 {
+					if sub.last, err = s.ReplayMessages(stream, name, d, key, ev.Subscribe.ResumeFrom); err != nil {
+						return err
+					}
+				}
+				if _, ok := subs[key]; !ok {
+					s.Relay(outMsg.Receiver, conv, chat.Message{Sender: name, Event: &chat.Message_Join{Join: &chat.Join{}}})
+				}
+				subs[key] = sub
+				log.Printf("%s subscribed to %s", name, key)
+				// messages received while the client was away
+				err = deliver()
+			case *chat.Message_Unsubscribe, *chat.Message_Leave:
+				if _, ok := subs[key]; ok {
+					delete(subs, key)
+					s.Relay(outMsg.Receiver, conv, chat.Message{Sender: name, Event: &chat.Message_Leave{Leave: &chat.Leave{}}})
+					log.Printf("%s unsubscribed from %s", name, key)
+				}
+			default:
+				err = refuse(&outMsg, status.Error(codes.InvalidArgument, "a client can't send that"))
+			}
+			if err != nil {
+				return err
+			}
+		case <-d.mailbox.Notify:
+			log.Println("Sending messages to STREAM of " + name)
+			if err := deliver(); err != nil {
+				return err
+			}
+		case err := <-errc:
+			if err == io.EOF {
+				return nil
+			}
+			log.Printf("stream of %s closed: %v", name, err)
+			return err
+		case <-stream.Context().Done():
+			err := s.streamEnded(stream.Context())
+			log.Printf("stream of %s ended: %v", name, err)
+			return err
+		}
+	}
+}
+
+// genID returns a new unique message id.
+func (s *server) genID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return fmt.Sprintf("%x", id)
+}
+
+// certName returns the common name of the verified client certificate of the connection.
+func certName(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	name := info.State.VerifiedChains[0][0].Subject.CommonName
+	return name, name != ""
+}
+
+// accountError turns an error of the account store into a status error.
+func accountError(err error) error {
+	switch err {
+	case accounts.ErrWrongPassword:
+		return status.Error(codes.Unauthenticated, err.Error())
+	case accounts.ErrDisabled:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case accounts.ErrExists:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case accounts.ErrNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case accounts.ErrWeakPassword:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	log.Printf("account store: %v", err)
+	return status.Error(codes.Internal, "could not update the accounts")
+}
+
+// validName checks that a new username can be used. Clients name files after their user, so
+// only letters, digits, '-', '_' and '.' not in first place are allowed.
+// It returns a status error if not.
+func validName(name string) error {
+	for i, r := range name {
+		ok := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || (r == '.' && i > 0)
+		if !ok {
+			return status.Error(codes.InvalidArgument, "a username can only have letters, digits, '-', '_' and '.' after the first character")
+		}
+	}
+	switch {
+	case len(name) < 3:
+		return status.Error(codes.InvalidArgument, "a username needs at least 3 characters")
+	case len(name) > maxName:
+		return status.Errorf(codes.InvalidArgument, "a username has at most %d characters", maxName)
+	case name == serverName:
+		return status.Error(codes.InvalidArgument, "that username is reserved")
+	}
+	return nil
+}
+
+// startSession hands out a session token to name. The device of the previous session of the
+// client, if it is one of name, carries on with its messages instead of a new one.
+// It returns the login response and a status error.
+func (s *server) startSession(name string, previous string) (*chat.ClientLoginResponse, error) {
+	device, ok := s.tokens.Resume(previous, name)
+	if !ok {
+		device = s.genID()
+	}
+	tkn, expires, err := s.tokens.Issue(name, device)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "could not start a session: "+err.Error())
+	}
+
+	log.Println(name + " has logged in")
+
+	return &chat.ClientLoginResponse{Token: tkn, Name: name, Expires: expires.UnixNano()}, nil
+}
+
+// Login checks the password of the account, or the client certificate if the server maps
+// them to names, and hands out a session token.
+func (s *server) Login(ctx context.Context, req *chat.ClientLoginRequest) (*chat.ClientLoginResponse, error) {
+	if s.certIdentity {
+		name, ok := certName(ctx)
+		switch {
+		case !ok:
+			return nil, status.Error(codes.Unauthenticated, "a client certificate is required")
+		case req.Name != "" && req.Name != name:
+			return nil, status.Error(codes.PermissionDenied, "your certificate is for "+name)
+		case validName(name) != nil:
+			return nil, validName(name)
+		case s.accounts.Disabled(name):
+			return nil, accountError(accounts.ErrDisabled)
+		}
+		req.Name = name
+	} else if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "username is required")
+	} else if err := s.accounts.Check(req.Name, req.Password); err != nil {
+		return nil, accountError(err)
+	}
+
+	return s.startSession(req.Name, req.Previous)
+}
+
+// SignUp creates an account, the name is kept for good, and logs it in.
+func (s *server) SignUp(ctx context.Context, req *chat.ClientLoginRequest) (*chat.ClientLoginResponse, error) {
+	if s.certIdentity {
+		return nil, status.Error(codes.FailedPrecondition, "this server logs in with client certificates")
+	}
+	if err := validName(req.Name); err != nil {
+		return nil, err
+	}
+	if err := s.accounts.SignUp(req.Name, req.Password); err != nil {
+		return nil, accountError(err)
+	}
+
+	log.Print("signed up " + req.Name)
+	return s.startSession(req.Name, "")
+}
+
+// ChangePassword replaces the password of the client, given the current one.
+// It returns an empty object and an error.
+func (s *server) ChangePassword(ctx context.Context, in *chat.PasswordChange) (*chat.Empty, error) {
+
+	name, _ := clientName(ctx)
+	if err := s.accounts.ChangePassword(name, in.OldPassword, in.NewPassword); err != nil {
+		return nil, accountError(err)
+	}
+	log.Print(name + " changed password")
+	return &chat.Empty{}, nil
+}
+
+// DisableAccount disables or enables an account, only server admins can. A disabled account
+// can't log in and loses its sessions.
+// It returns an empty object and an error.
+func (s *server) DisableAccount(ctx context.Context, in *chat.AccountState) (*chat.Empty, error) {
+
+	name, _ := clientName(ctx)
+	switch {
+	case !s.admins[name]:
+		return nil, status.Error(codes.PermissionDenied, "only server admins can do that")
+	case in.Name == name:
+		return nil, status.Error(codes.InvalidArgument, "you can't disable your own account")
+	}
+
+	if err := s.accounts.SetDisabled(in.Name, in.Disabled); err != nil {
+		return nil, accountError(err)
+	}
+	if in.Disabled {
+		s.tokens.RevokeAll(in.Name)
+		s.DropDevices(in.Name, nil)
+		log.Print(name + " disabled the account of " + in.Name)
+	} else {
+		log.Print(name + " enabled the account of " + in.Name)
+	}
+	return &chat.Empty{}, nil
+}
+
+// RefreshToken hands out a new token for the session of the call, the old one expires shortly.
+func (s *server) RefreshToken(ctx context.Context, in *chat.Empty) (*chat.ClientLoginResponse, error) {
+
+	tkn, _ := s.extractToken(ctx)
+	name, _ := clientName(ctx)
+
+	tkn, expires, err := s.tokens.Refresh(tkn)
+	switch err {
+	case nil:
+	case tokens.ErrInvalid, tokens.ErrExpired, tokens.ErrRevoked:
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	default:
+		return nil, status.Error(codes.Internal, "could not refresh the session: "+err.Error())
+	}
+	return &chat.ClientLoginResponse{Token: tkn, Name: name, Expires: expires.UnixNano()}, nil
+}
+
+// logout from server, ending the session of the token or every session of the client.
+// The streams of the sessions ended are closed.
+func (s *server) Logout(ctx context.Context, req *chat.ClientLogoutRequest) (*chat.ClientLogoutResponse, error) {
+	name, _ := clientName(ctx)
+
+	if req.All {
+		n := s.tokens.RevokeAll(name)
+		s.DropDevices(name, nil)
+		log.Printf("%s logged out of %d session(s)", name, n)
+		return new(chat.ClientLogoutResponse), nil
+	}
+
+	tkn := req.Token
+	if tkn == "" {
+		tkn, _ = s.extractToken(ctx)
+	}
+	// other people's tokens are reported as missing
+	device, ok := s.tokens.Revoke(tkn, name)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "token not found")
+	}
+	s.DropDevices(name, []string{device})
+	log.Print(name + " logged out")
+	return new(chat.ClientLogoutResponse), nil
 }
 
 func (s *server) extractToken(ctx context.Context) (tkn string, ok bool) {
@@ -490,23 +2778,291 @@ func (s *server) extractToken(ctx context.Context) (tkn string, ok bool) {
 	return md[tokenHeader][0], true
 }
 
+// keys under which the authenticated client name and its device are stored in a call context.
+type clientNameKey struct{}
+type clientDeviceKey struct{}
+
+// clientName returns the authenticated client name attached to ctx by the interceptors.
+func clientName(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(clientNameKey{}).(string)
+	return name, ok
+}
+
+// clientDevice returns the device of the authenticated client attached to ctx by the interceptors.
+func clientDevice(ctx context.Context) (string, bool) {
+	device, ok := ctx.Value(clientDeviceKey{}).(string)
+	return device, ok
+}
+
+// errSessionEnded ends the streams of a revoked session, the client gets it unless it left first.
+var errSessionEnded = status.Error(codes.Unauthenticated, tokens.ErrRevoked.Error())
+
+// streamEnded tells why the context of a stream was cancelled: errSessionEnded if its session
+// was revoked, the cancellation or the deadline of the client otherwise.
+func (s *server) streamEnded(ctx context.Context) error {
+	tkn, _ := s.extractToken(ctx)
+	if _, _, err := s.tokens.Resolve(tkn); err == tokens.ErrRevoked {
+		return errSessionEnded
+	}
+	return status.FromContextError(ctx.Err()).Err()
+}
+
+// methods that can be called without a session token.
+var publicMethods = map[string]bool{
+	"/chat.ChatService/Login":  true,
+	"/chat.ChatService/SignUp": true,
+}
+
+// transferMethods are the streams that don't make their client online.
+var transferMethods = map[string]bool{
+	"/chat.ChatService/UploadAttachment":   true,
+	"/chat.ChatService/DownloadAttachment": true,
+}
+
+// authenticate resolves the session token of an incoming call to a client name.
+// It returns a context carrying that name and an error.
+func (s *server) authenticate(ctx context.Context) (context.Context, string, error) {
+	tkn, ok := s.extractToken(ctx)
+	if !ok {
+		return nil, "", status.Error(codes.Unauthenticated, "missing session token")
+	}
+
+	name, device, err := s.tokens.Resolve(tkn)
+	if err != nil {
+		return nil, "", status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	ctx = context.WithValue(ctx, clientDeviceKey{}, device)
+	return context.WithValue(ctx, clientNameKey{}, name), name, nil
+}
+
+// setSender overrides the identity claimed in a request with the authenticated one.
+func setSender(req interface{}, name string) {
+	switch r := req.(type) {
+	case *chat.Message:
+		r.Sender = name
+	case *chat.ChatClient:
+		r.Sender = name
+	case *chat.ChatGroup:
+		r.Client = name
+	case *chat.HistoryRequest:
+		if r.Group != nil {
+			r.Group.Client = name
+		}
+	case *chat.Invitation:
+		r.Inviter = name
+	case *chat.ModerationRequest:
+		r.Client = name
+	case *chat.ReactionRequest:
+		r.Sender = name
+	}
+}
+
+// unaryInterceptor rejects unauthenticated unary calls and pins the caller identity.
+func (s *server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	ctx, name, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	setSender(req, name)
+	return handler(ctx, req)
+}
+
+// authStream wraps a server stream so every received message carries the authenticated sender.
+type authStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	name string
+}
+
+func (a *authStream) Context() context.Context {
+	return a.ctx
+}
+
+func (a *authStream) RecvMsg(m interface{}) error {
+	if err := a.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	setSender(m, a.name)
+	return nil
+}
+
+// streamInterceptor rejects unauthenticated streams and pins the caller identity.
+func (s *server) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if publicMethods[info.FullMethod] {
+		return handler(srv, ss)
+	}
+
+	ctx, name, err := s.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+
+	// revoking the session cancels the stream
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	tkn, _ := s.extractToken(ctx)
+	release, err := s.tokens.Bind(tkn, cancel)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	defer release()
+	if !transferMethods[info.FullMethod] {
+		defer s.presence.Connect(name)()
+	}
+
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx, name: name})
+}
+
+// serverCredentials loads the certificate of the server, and the CA the certificates of the
+// clients must be signed by if clientCA is set.
+// It returns the credentials and an error.
+func serverCredentials(certFile string, keyFile string, clientCA string) (credentials.TransportCredentials, error) {
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+
+	if clientCA != "" {
+		pem, err := os.ReadFile(clientCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificate found in " + clientCA)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
 func main() {
 
+	historyFile := flag.String("history", "", "file keeping the message history, kept in memory if empty")
+	overflow := flag.String("overflow", "drop-oldest", "what a full client mailbox does: drop-oldest, drop-newest, disconnect or spill-to-disk")
+	mailboxSize := flag.Int("mailbox", 500, "number of messages a client mailbox holds in memory")
+	spillDir := flag.String("spill", filepath.Join(os.TempDir(), "grpchat-spill"), "directory of the mailboxes spilled to disk")
+	inviteTTL := flag.Duration("invite-ttl", 24*time.Hour, "time before a pending invitation expires")
+	certFile := flag.String("cert", "", "certificate of the server, serves TLS if set")
+	keyFile := flag.String("key", "", "private key of the server certificate")
+	clientCA := flag.String("client-ca", "", "CA bundle the client certificates must be signed by, requires mutual TLS if set")
+	certIdentity := flag.Bool("cert-identity", false, "log clients in as the common name of their certificate instead of with the password")
+	accountsFile := flag.String("accounts", "accounts.log", "file keeping the user accounts, kept in memory if empty")
+	adminList := flag.String("admins", "", "comma separated names of the server admins")
+	tokenTTL := flag.Duration("token-ttl", 12*time.Hour, "time a session token is valid for, clients refresh it before")
+	blobDir := flag.String("blobs", "blobs", "directory of the attachments")
+	maxAttachment := flag.Int64("max-attachment", 10<<20, "most bytes of an attachment")
+	keepaliveTime := flag.Duration("keepalive", time.Minute, "time without activity after which a client is pinged, it is offline if it doesn't answer")
+	flag.Parse()
+
+	// dead connections end their streams, which is what takes their clients offline
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{Time: *keepaliveTime, Timeout: 20 * time.Second}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: 10 * time.Second, PermitWithoutStream: true}),
+	}
+	switch {
+	case *certFile != "":
+		creds, err := serverCredentials(*certFile, *keyFile, *clientCA)
+		if err != nil {
+			log.Fatalf("Failed to load certificates %v", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	case *clientCA != "":
+		log.Fatal("-client-ca needs -cert and -key")
+	}
+	if *certIdentity && *clientCA == "" {
+		log.Fatal("-cert-identity needs -client-ca")
+	}
+
+	policy, err := mailbox.ParsePolicy(*overflow)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *mailboxSize < 1 {
+		log.Fatal("-mailbox must hold at least 1 message")
+	}
+	if err := os.MkdirAll(*spillDir, 0700); err != nil {
+		log.Fatalf("Failed to create spill directory %v", err)
+	}
+
+	var history store.MessageStore = store.NewMemoryStore()
+	if *historyFile != "" {
+		fs, err := store.OpenFileStore(*historyFile)
+		if err != nil {
+			log.Fatalf("Failed to open history %v", err)
+		}
+		defer fs.Close()
+		history = fs
+	}
+
+	files, err := blobs.Open(*blobDir, *maxAttachment)
+	if err != nil {
+		log.Fatalf("Failed to open attachments %v", err)
+	}
+
+	users, err := accounts.Open(*accountsFile)
+	if err != nil {
+		log.Fatalf("Failed to open accounts %v", err)
+	}
+	defer users.Close()
+
+	admins := make(map[string]bool)
+	for _, a := range strings.Split(*adminList, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			admins[a] = true
+		}
+	}
+
 	lis, err := net.Listen("tcp", port)
 
 	if err != nil {
 		log.Fatalf("Failed to listen %v", err)
 	}
 	log.Println("server listening on port" + port)
-	// Initializes the gRPC server.
-	s := grpc.NewServer()
-	var mlock = &sync.RWMutex{}
-	var clients = make(map[string]*Client)
-	var groups = make(map[string]*Group)
+
+	srv := &server{
+		chatclients: make(map[string]*Client),
+		chatgroups:  make(map[string]*Group),
+		retired:     make(map[string]*Group),
+		tokens:      tokens.New(*tokenTTL),
+		presence:    presence.New(),
+
+		conversations: make(map[string]*Conversation),
+		invitations:   make(map[string]*chat.Invitation),
+		watchers:      make(map[string][]chan chat.Invitation),
+		inviteTTL:     *inviteTTL,
+		history:       history,
+		blobs:         files,
+		uploads:       make(map[string]map[string]bool),
+		keys:          make(map[string]map[string][]byte),
+		policy:        policy,
+		mailboxSize:   *mailboxSize,
+		spillDir:      *spillDir,
+		certIdentity:  *certIdentity,
+		Host:          port,
+		accounts:      users,
+		admins:        admins,
+	}
+
+	// Initializes the gRPC server, every call but Login must carry a session token.
+	opts = append(opts,
+		grpc.UnaryInterceptor(srv.unaryInterceptor),
+		grpc.StreamInterceptor(srv.streamInterceptor),
+	)
+	s := grpc.NewServer(opts...)
 
 	// Register the server with gRPC.
-	chat.RegisterChatServiceServer(s, &server{lock: *mlock, chatclients: clients,
-		chatgroups: groups, Host: port, Password: "goldenratio"})
+	chat.RegisterChatServiceServer(s, srv)
 
 	// Register reflection service on gRPC server.
 	reflection.Register(s)
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/baadjis/grpchat/accounts"
+	"github.com/baadjis/grpchat/chat"
+	"github.com/baadjis/grpchat/e2e"
+	"github.com/baadjis/grpchat/mailbox"
+	"github.com/baadjis/grpchat/presence"
+	"github.com/baadjis/grpchat/store"
+	"github.com/baadjis/grpchat/tokens"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// newTestServer returns a server keeping everything in memory.
+func newTestServer(t *testing.T) *server {
+	users, err := accounts.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &server{
+		chatclients:   make(map[string]*Client),
+		chatgroups:    make(map[string]*Group),
+		retired:       make(map[string]*Group),
+		tokens:        tokens.New(time.Hour),
+		presence:      presence.New(),
+		conversations: make(map[string]*Conversation),
+		invitations:   make(map[string]*chat.Invitation),
+		watchers:      make(map[string][]chan chat.Invitation),
+		inviteTTL:     time.Hour,
+		history:       store.NewMemoryStore(),
+		uploads:       make(map[string]map[string]bool),
+		keys:          make(map[string]map[string][]byte),
+		policy:        mailbox.DropOldest,
+		mailboxSize:   16,
+		spillDir:      t.TempDir(),
+		accounts:      users,
+		admins:        make(map[string]bool),
+	}
+}
+
+// session returns the context of the calls of the session of tkn, as the interceptors make it.
+func session(t *testing.T, s *server, tkn string) context.Context {
+	ctx, _, err := s.authenticate(metadata.NewIncomingContext(context.Background(), metadata.Pairs(tokenHeader, tkn)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+// signUp creates the account name, registers it and returns the context of its session.
+func signUp(t *testing.T, s *server, name string) context.Context {
+	res, err := s.SignUp(context.Background(), &chat.ClientLoginRequest{Name: name, Password: "secret-" + name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := session(t, s, res.Token)
+	if _, err := s.Register(ctx, &chat.ChatClient{Sender: name}); err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+func TestValidName(t *testing.T) {
+
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"alice", true},
+		{"Bob_42", true},
+		{"jean-luc", true},
+		{"j.doe", true},
+		{"a.b.", true},
+		{"al", false},
+		{strings.Repeat("a", maxName), true},
+		{strings.Repeat("a", maxName+1), false},
+		{".alice", false},
+		{"al ice", false},
+		{"alice\n", false},
+		{"al/ice", false},
+		{"ali:ce", false},
+		{"élise", false},
+		{"аlice", false}, // a cyrillic a
+		{"alice​", false},
+		{serverName, false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if err := validName(tt.name); (err == nil) != tt.ok {
+			t.Errorf("validName(%q) = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestSealed(t *testing.T) {
+
+	key := func(b byte) []byte { return bytes.Repeat([]byte{b}, e2e.KeySize) }
+	s := &server{keys: map[string]map[string][]byte{"alice": {"phone": key(1), "laptop": key(2)}}}
+	conv := &Conversation{}
+	copies := []*chat.SealedCopy{{Key: key(3), Ciphertext: []byte("sealed")}}
+
+	tests := []struct {
+		name string
+		conv *Conversation
+		msg  chat.Message
+		code codes.Code
+	}{
+		{"clear", conv, chat.Message{Sender: "alice", Body: "hi\n"}, codes.OK},
+		{"sealed", conv, chat.Message{Sender: "alice", Sealed: &chat.Sealed{SenderKey: key(1), Copies: copies}}, codes.OK},
+		{"other device", conv, chat.Message{Sender: "alice", Sealed: &chat.Sealed{SenderKey: key(2), Copies: copies}}, codes.OK},
+		{"in a group", nil, chat.Message{Sender: "alice", Sealed: &chat.Sealed{SenderKey: key(1), Copies: copies}}, codes.InvalidArgument},
+		{"body in clear", conv, chat.Message{Sender: "alice", Body: "hi\n", Sealed: &chat.Sealed{SenderKey: key(1), Copies: copies}}, codes.InvalidArgument},
+		{"short sender key", conv, chat.Message{Sender: "alice", Sealed: &chat.Sealed{SenderKey: key(1)[:8], Copies: copies}}, codes.InvalidArgument},
+		{"no copy", conv, chat.Message{Sender: "alice", Sealed: &chat.Sealed{SenderKey: key(1)}}, codes.InvalidArgument},
+		{"short copy key", conv, chat.Message{Sender: "alice", Sealed: &chat.Sealed{SenderKey: key(1), Copies: []*chat.SealedCopy{{Key: key(3)[:8], Ciphertext: []byte("sealed")}}}}, codes.InvalidArgument},
+		{"empty copy", conv, chat.Message{Sender: "alice", Sealed: &chat.Sealed{SenderKey: key(1), Copies: []*chat.SealedCopy{{Key: key(3)}}}}, codes.InvalidArgument},
+		{"unpublished key", conv, chat.Message{Sender: "alice", Sealed: &chat.Sealed{SenderKey: key(3), Copies: copies}}, codes.FailedPrecondition},
+		{"key of another", conv, chat.Message{Sender: "bob", Sealed: &chat.Sealed{SenderKey: key(1), Copies: copies}}, codes.FailedPrecondition},
+	}
+	for _, tt := range tests {
+		if err := s.sealed(tt.conv, &tt.msg); status.Code(err) != tt.code {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.code)
+		}
+	}
+}
+
+func TestRetiredGroup(t *testing.T) {
+
+	s := newTestServer(t)
+	alice := signUp(t, s, "alice")
+	eve := signUp(t, s, "eve")
+	signUp(t, s, "bob")
+
+	if _, err := s.CreateChatGroup(alice, &chat.ChatGroup{Client: "alice", Name: "vault", Visibility: chat.Visibility_INVITE_ONLY}); err != nil {
+		t.Fatal(err)
+	}
+	s.BroadcastMessage("vault", chat.Message{Sender: "alice", Receiver: "vault", Body: "the combination\n"}, nil)
+	s.lock.Lock()
+	s.chatgroups["vault"].banned["bob"] = true
+	s.lock.Unlock()
+	if _, err := s.LeaveChatRoom(alice, &chat.ChatGroup{Client: "alice", Name: "vault"}); err != nil {
+		t.Fatal(err)
+	}
+	if !s.GroupExists("vault") {
+		t.Fatal("the group is gone with its last member")
+	}
+
+	steps := []struct {
+		name string
+		do   func() error
+		ok   bool
+	}{
+		{"recreate", func() error {
+			_, err := s.CreateChatGroup(eve, &chat.ChatGroup{Client: "eve", Name: "vault"})
+			return err
+		}, false},
+		{"join uninvited", func() error {
+			_, err := s.JoinChatGroup(eve, &chat.ChatGroup{Client: "eve", Name: "vault"})
+			return err
+		}, false},
+		{"join banned", func() error { return s.AddClientToChatGroup("bob", "vault", "") }, false},
+		{"owner comes back", func() error {
+			_, err := s.JoinChatGroup(alice, &chat.ChatGroup{Client: "alice", Name: "vault"})
+			return err
+		}, true},
+		{"recreate live", func() error {
+			_, err := s.CreateChatGroup(eve, &chat.ChatGroup{Client: "eve", Name: "vault"})
+			return err
+		}, false},
+	}
+	for _, st := range steps {
+		if err := st.do(); (err == nil) != st.ok {
+			t.Errorf("%s: got %v, want ok %v", st.name, err, st.ok)
+		}
+	}
+
+	s.lock.RLock()
+	g, live := s.chatgroups["vault"]
+	_, retired := s.retired["vault"]
+	s.lock.RUnlock()
+	if !live || retired || g.owner != "alice" || g.visibility != chat.Visibility_INVITE_ONLY || !g.banned["bob"] {
+		t.Errorf("the group came back as live %v retired %v, with owner %q, %v and bans %v", live, retired, g.owner, g.visibility, g.banned)
+	}
+	h, err := s.GetHistory(alice, &chat.HistoryRequest{Group: &chat.ChatGroup{Client: "alice", Name: "vault"}})
+	if err != nil || len(h.Messages) != 1 || h.Messages[0].Body != "the combination\n" {
+		t.Errorf("history of the group that came back: %v %v", h.GetMessages(), err)
+	}
+
+	// a restart loses the groups, not their history: the group comes back from it
+	s.lock.Lock()
+	delete(s.chatgroups, "vault")
+	s.lock.Unlock()
+	if _, err := s.JoinChatGroup(eve, &chat.ChatGroup{Client: "eve", Name: "vault"}); err != nil {
+		t.Fatalf("joining a group lost on a restart: %v", err)
+	}
+	h, err = s.GetHistory(eve, &chat.HistoryRequest{Group: &chat.ChatGroup{Client: "eve", Name: "vault"}})
+	if err != nil || len(h.Messages) != 1 {
+		t.Errorf("history of the restored group: %v %v", h.GetMessages(), err)
+	}
+	if msg := s.BroadcastMessage("vault", chat.Message{Sender: "eve", Receiver: "vault", Body: "hi\n"}, nil); msg.Seq != 2 {
+		t.Errorf("the restored group numbered a message %d, want 2", msg.Seq)
+	}
+	if _, err := s.JoinChatGroup(eve, &chat.ChatGroup{Client: "eve", Name: "nowhere"}); err == nil {
+		t.Error("joined a group without history")
+	}
+}
+
+func TestCreateRace(t *testing.T) {
+
+	s := newTestServer(t)
+	names := []string{"alice", "bob", "carol", "dave", "erin", "frank"}
+	ctxs := make([]context.Context, len(names))
+	for i, name := range names {
+		ctxs[i] = signUp(t, s, name)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(names))
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			_, errs[i] = s.CreateChatGroup(ctxs[i], &chat.ChatGroup{Client: name, Name: "race"})
+		}(i, name)
+	}
+	wg.Wait()
+
+	var winner string
+	for i, err := range errs {
+		if err == nil && winner != "" {
+			t.Errorf("both %s and %s created the group", winner, names[i])
+		} else if err == nil {
+			winner = names[i]
+		}
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	g := s.chatgroups["race"]
+	if winner == "" || g.owner != winner || len(g.clients) != 1 || g.clients[0] != winner {
+		t.Errorf("the group of %q has owner %q and members %v", winner, g.owner, g.clients)
+	}
+}
+
+func TestRelogin(t *testing.T) {
+
+	s := newTestServer(t)
+	signUp(t, s, "bob")
+	bob, _ := s.Login(context.Background(), &chat.ClientLoginRequest{Name: "bob", Password: "secret-bob"})
+	first, _ := s.SignUp(context.Background(), &chat.ClientLoginRequest{Name: "ivy", Password: "secret-ivy"})
+	revoked, _ := s.Login(context.Background(), &chat.ClientLoginRequest{Name: "ivy", Password: "secret-ivy"})
+	s.tokens.Revoke(revoked.Token, "ivy")
+	ctx := session(t, s, first.Token)
+	s.Register(ctx, &chat.ChatClient{Sender: "ivy"})
+	device, _ := clientDevice(ctx)
+
+	tests := []struct {
+		name     string
+		previous string
+		same     bool
+	}{
+		{"previous session", first.Token, true},
+		{"no previous session", "", false},
+		{"session of another", bob.Token, false},
+		{"revoked session", revoked.Token, false},
+		{"not a token", "not a token", false},
+	}
+	for _, tt := range tests {
+		res, err := s.Login(context.Background(), &chat.ClientLoginRequest{Name: "ivy", Password: "secret-ivy", Previous: tt.previous})
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		d, _ := clientDevice(session(t, s, res.Token))
+		if (d == device) != tt.same {
+			t.Errorf("%s: logged in on device %s, the previous one is %s", tt.name, d, device)
+		}
+	}
+
+	// the device logging in again keeps its place and its messages
+	s.lock.Lock()
+	s.chatclients["ivy"].devices[device].mailbox.Put(chat.Message{Body: "waiting\n"}, s.policy)
+	s.lock.Unlock()
+	res, _ := s.Login(context.Background(), &chat.ClientLoginRequest{Name: "ivy", Password: "secret-ivy", Previous: first.Token})
+	again := session(t, s, res.Token)
+	s.Register(again, &chat.ChatClient{Sender: "ivy"})
+	if n, _ := s.GetUnreadCount(again, &chat.Empty{}); n.GetCount() != 1 {
+		t.Errorf("the device has %d messages waiting after logging in again, want 1", n.GetCount())
+	}
+	l, _ := s.GetChatClientList(again, &chat.Empty{})
+	for _, info := range l.Infos {
+		if info.Name == "ivy" && info.Devices != 1 {
+			t.Errorf("ivy has %d devices after logging in again, want 1", info.Devices)
+		}
+	}
+}
+
+func TestStreamEnded(t *testing.T) {
+
+	s := newTestServer(t)
+	signUp(t, s, "alice")
+	res, _ := s.Login(context.Background(), &chat.ClientLoginRequest{Name: "alice", Password: "secret-alice"})
+	stream := func() context.Context {
+		ctx, cancel := context.WithCancel(metadata.NewIncomingContext(context.Background(), metadata.Pairs(tokenHeader, res.Token)))
+		cancel()
+		return ctx
+	}
+
+	if err := s.streamEnded(stream()); status.Code(err) != codes.Canceled {
+		t.Errorf("a client leaving: got %v, want %v", err, codes.Canceled)
+	}
+	s.tokens.Revoke(res.Token, "alice")
+	if err := s.streamEnded(stream()); err != errSessionEnded {
+		t.Errorf("a revoked session: got %v, want %v", err, errSessionEnded)
+	}
+}
+
+// replayStream keeps what the server sends on it.
+type replayStream struct {
+	chat.ChatService_RouteChatServer
+	sent []*chat.Message
+}
+
+func (r *replayStream) Send(m *chat.Message) error {
+	r.sent = append(r.sent, m)
+	return nil
+}
+
+func TestOtherDevices(t *testing.T) {
+
+	s := newTestServer(t)
+	phone := signUp(t, s, "alice")
+	res, _ := s.Login(context.Background(), &chat.ClientLoginRequest{Name: "alice", Password: "secret-alice"})
+	laptop := session(t, s, res.Token)
+	s.Register(laptop, &chat.ChatClient{Sender: "alice"})
+	bob := signUp(t, s, "bob")
+	s.CreateChatGroup(phone, &chat.ChatGroup{Client: "alice", Name: "g"})
+	if _, err := s.JoinChatGroup(bob, &chat.ChatGroup{Client: "bob", Name: "g"}); err != nil {
+		t.Fatal(err)
+	}
+
+	device := func(ctx context.Context, name string) *Device {
+		id, _ := clientDevice(ctx)
+		s.lock.RLock()
+		defer s.lock.RUnlock()
+		return s.chatclients[name].devices[id]
+	}
+	from := device(phone, "alice")
+	sent := s.BroadcastMessage("g", chat.Message{Sender: "alice", Receiver: "g", Body: "from the phone\n"}, from)
+	dm, err := s.SendDirectMessage(phone, &chat.Message{Sender: "alice", Receiver: "bob", Body: "hi bob\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the messages reach the other devices of the sender, not the one they come from
+	tests := []struct {
+		name   string
+		device *Device
+		want   int
+	}{
+		{"phone", from, 0},
+		{"laptop", device(laptop, "alice"), 2},
+		{"bob", device(bob, "bob"), 2},
+	}
+	for _, tt := range tests {
+		l, _ := tt.device.mailbox.Drain()
+		n := 0
+		for _, m := range l {
+			if m.Id == sent.Id || m.Id == dm.Id {
+				n++
+			}
+		}
+		if n != tt.want {
+			t.Errorf("%s got %d of the messages, want %d", tt.name, n, tt.want)
+		}
+
+		for _, key := range []string{"g", historyKey(dm)} {
+			r := &replayStream{}
+			if _, err := s.ReplayMessages(r, "alice", tt.device, key, 1); err != nil {
+				t.Fatal(err)
+			}
+			if len(r.sent) != tt.want/2 {
+				t.Errorf("%s got %d message(s) of %s replayed, want %d", tt.name, len(r.sent), key, tt.want/2)
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/baadjis/grpchat/chat"
+	"github.com/golang/protobuf/jsonpb"
+)
+
+// record is a single line of the log file. The message is written with the protobuf JSON
+// mapping, which keeps its event. An update replaces the message with the same sequence number
+// written before it.
+type record struct {
+	Group   string          `json:"group"`
+	Message json.RawMessage `json:"message"`
+	Update  bool            `json:"update,omitempty"`
+}
+
+var (
+	marshaler   = jsonpb.Marshaler{OrigName: true}
+	unmarshaler = jsonpb.Unmarshaler{AllowUnknownFields: true}
+)
+
+// FileStore keeps the history in an append-only log file so it survives restarts.
+// The whole log is loaded in memory when the store is opened.
+type FileStore struct {
+	*MemoryStore
+	lock sync.Mutex
+	f    *os.File
+}
+
+// OpenFileStore opens the log at path, creating it if needed, and loads its history.
+// It returns the store and an error.
+func OpenFileStore(path string) (*FileStore, error) {
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	m := NewMemoryStore()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		var r record
+		var msg chat.Message
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			// a torn last line from a crash is skipped
+			continue
+		}
+		if err := unmarshaler.Unmarshal(bytes.NewReader(r.Message), &msg); err != nil {
+			continue
+		}
+		if r.Update {
+			replace(m.groups[r.Group], msg)
+			continue
+		}
+		m.groups[r.Group] = append(m.groups[r.Group], msg)
+	}
+	if err := sc.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	for group, l := range m.groups {
+		for i := range l {
+			m.index.add(group, &l[i])
+		}
+	}
+
+	return &FileStore{MemoryStore: m, f: f}, nil
+}
+
+// Append writes msg to the log and adds it at the end of the history of group.
+func (s *FileStore) Append(group string, msg chat.Message) error {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.write(record{Group: group}, &msg); err != nil {
+		return err
+	}
+	return s.MemoryStore.Append(group, msg)
+}
+
+// Update writes the new version of msg to the log and replaces it in the history of group.
+func (s *FileStore) Update(group string, msg chat.Message) error {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, err := s.MemoryStore.Find(group, msg.Id); err != nil {
+		return err
+	}
+	if err := s.write(record{Group: group, Update: true}, &msg); err != nil {
+		return err
+	}
+	return s.MemoryStore.Update(group, msg)
+}
+
+// write appends r holding msg to the log. The store lock must be held.
+func (s *FileStore) write(r record, msg *chat.Message) error {
+
+	js, err := marshaler.MarshalToString(msg)
+	if err != nil {
+		return err
+	}
+	r.Message = json.RawMessage(js)
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	if s.f == nil {
+		return errors.New("store is closed")
+	}
+	_, err = s.f.Write(append(b, '\n'))
+	return err
+}
+
+// Close closes the log file.
+func (s *FileStore) Close() error {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/baadjis/grpchat/chat"
+)
+
+// ref is the place of a message in the history.
+type ref struct {
+	group string
+	seq   int64
+}
+
+// index is an inverted index of the words of the chat messages of the history.
+type index struct {
+	postings map[string]map[ref]bool
+	words    map[ref][]string // words of each message, to take them out again
+}
+
+// newIndex returns an empty index.
+func newIndex() *index {
+	return &index{postings: make(map[string]map[ref]bool), words: make(map[ref][]string)}
+}
+
+// words returns the words of text in lower case, once each. Anything but letters and digits
+// separates them.
+func words(text string) []string {
+
+	seen := make(map[string]bool)
+	var l []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !seen[w] {
+			seen[w] = true
+			l = append(l, w)
+		}
+	}
+	return l
+}
+
+// add indexes msg of group in place of what it said before. Deleted messages and events are
+// left out.
+func (x *index) add(group string, msg *chat.Message) {
+
+	r := ref{group: group, seq: msg.Seq}
+	x.remove(r)
+	if msg.Deleted || msg.Event != nil {
+		return
+	}
+
+	l := words(msg.Body)
+	for _, w := range l {
+		p, ok := x.postings[w]
+		if !ok {
+			p = make(map[ref]bool)
+			x.postings[w] = p
+		}
+		p[r] = true
+	}
+	x.words[r] = l
+}
+
+// remove takes the message at r out of the index.
+func (x *index) remove(r ref) {
+	for _, w := range x.words[r] {
+		delete(x.postings[w], r)
+		if len(x.postings[w]) == 0 {
+			delete(x.postings, w)
+		}
+	}
+	delete(x.words, r)
+}
+
+// lookup returns the messages containing all the words.
+func (x *index) lookup(words []string) []ref {
+
+	if len(words) == 0 {
+		return nil
+	}
+	// the rarest word has the fewest messages to check
+	rarest := x.postings[words[0]]
+	for _, w := range words[1:] {
+		if len(x.postings[w]) < len(rarest) {
+			rarest = x.postings[w]
+		}
+	}
+
+	var l []ref
+	for r := range rarest {
+		all := true
+		for _, w := range words {
+			if !x.postings[w][r] {
+				all = false
+				break
+			}
+		}
+		if all {
+			l = append(l, r)
+		}
+	}
+	return l
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/baadjis/grpchat/chat"
+)
+
+// MemoryStore keeps the history in memory, it is lost when the server stops.
+type MemoryStore struct {
+	lock   sync.RWMutex
+	groups map[string][]chat.Message
+	index  *index
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{groups: make(map[string][]chat.Message), index: newIndex()}
+}
+
+// Append adds msg at the end of the history of group.
+func (m *MemoryStore) Append(group string, msg chat.Message) error {
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.groups[group] = append(m.groups[group], msg)
+	m.index.add(group, &msg)
+	return nil
+}
+
+// History returns up to limit messages of group older than position before.
+func (m *MemoryStore) History(group string, before int64, limit int) ([]chat.Message, error) {
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return page(m.groups[group], before, limit), nil
+}
+
+// Since returns up to limit messages of group with a sequence number greater than after.
+func (m *MemoryStore) Since(group string, after int64, limit int) ([]chat.Message, error) {
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return since(m.groups[group], after, limit), nil
+}
+
+// Find returns the message of group with the given id.
+func (m *MemoryStore) Find(group string, id string) (chat.Message, error) {
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	l := m.groups[group]
+	for i := len(l) - 1; i >= 0; i-- {
+		if l[i].Id == id {
+			return l[i], nil
+		}
+	}
+	return chat.Message{}, ErrNotFound
+}
+
+// Thread returns up to limit of the latest messages of the thread root of group.
+func (m *MemoryStore) Thread(group string, root string, limit int) ([]chat.Message, error) {
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return thread(m.groups[group], root, limit), nil
+}
+
+// Update replaces the message of group with the same sequence number as msg.
+func (m *MemoryStore) Update(group string, msg chat.Message) error {
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := replace(m.groups[group], msg); err != nil {
+		return err
+	}
+	m.index.add(group, &msg)
+	return nil
+}
+
+// Search returns up to limit of the latest chat messages matching q.
+func (m *MemoryStore) Search(q Query) ([]chat.Message, error) {
+
+	limit := q.Limit
+	if limit <= 0 || limit > MaxPage {
+		limit = MaxPage
+	}
+	groups := make(map[string]bool)
+	for _, g := range q.Groups {
+		groups[g] = true
+	}
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var l []chat.Message
+	for _, r := range m.index.lookup(words(q.Text)) {
+		if !groups[r.group] {
+			continue
+		}
+		h := m.groups[r.group]
+		i := sort.Search(len(h), func(i int) bool { return h[i].Seq >= r.seq })
+		if i < len(h) && h[i].Seq == r.seq && q.matches(&h[i]) {
+			l = append(l, h[i])
+		}
+	}
+
+	sort.Slice(l, func(i, j int) bool { return l[i].Timestamp > l[j].Timestamp })
+	if len(l) > limit {
+		l = l[:limit]
+	}
+	return l, nil
+}
+
+// LastSeq returns the sequence number of the latest message of group.
+func (m *MemoryStore) LastSeq(group string) (int64, error) {
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	l := m.groups[group]
+	if len(l) == 0 {
+		return 0, nil
+	}
+	return l[len(l)-1].Seq, nil
+}
+
+// Close does nothing for an in-memory store.
+func (m *MemoryStore) Close() error {
+	return nil
+}
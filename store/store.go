@@ -0,0 +1,149 @@
+// Package store keeps the message history of chat groups.
+package store
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/baadjis/grpchat/chat"
+)
+
+// The most messages a single History call returns.
+const (
+	MaxPage = 100
+)
+
+// ErrNotFound is returned for a message that is not in the history.
+var ErrNotFound = errors.New("message not found")
+
+// Query selects the messages Search returns.
+type Query struct {
+	Text   string   // words the messages must all contain
+	Groups []string // histories to look in
+	Sender string   // only the messages of this sender if set
+	Since  int64    // unix time in nanoseconds the messages are sent at or after, 0 for any
+	Until  int64    // unix time in nanoseconds the messages are sent at or before, 0 for any
+	Limit  int
+}
+
+// matches checks if msg is in the time range and from the sender of q.
+func (q *Query) matches(msg *chat.Message) bool {
+	switch {
+	case q.Sender != "" && msg.Sender != q.Sender:
+		return false
+	case q.Since > 0 && msg.Timestamp < q.Since:
+		return false
+	case q.Until > 0 && msg.Timestamp > q.Until:
+		return false
+	}
+	return true
+}
+
+// MessageStore stores the messages of every group in the order they were sent.
+type MessageStore interface {
+	// Append adds msg at the end of the history of group.
+	Append(group string, msg chat.Message) error
+
+	// History returns up to limit messages of group with a sequence number lower
+	// than before, oldest first. A before of 0 means the latest messages.
+	History(group string, before int64, limit int) ([]chat.Message, error)
+
+	// Since returns up to limit messages of group with a sequence number greater
+	// than after, oldest first.
+	Since(group string, after int64, limit int) ([]chat.Message, error)
+
+	// Find returns the message of group with the given id, or ErrNotFound.
+	Find(group string, id string) (chat.Message, error)
+
+	// Thread returns up to limit of the latest messages of the thread started by the message
+	// of group with id root, the root first if it is among them.
+	Thread(group string, root string, limit int) ([]chat.Message, error)
+
+	// Search returns up to limit of the latest chat messages matching q, newest first. The
+	// words of the messages are indexed as they are written.
+	Search(q Query) ([]chat.Message, error)
+
+	// Update replaces the message of group with the same sequence number as msg, after an
+	// edit or a deletion.
+	Update(group string, msg chat.Message) error
+
+	// LastSeq returns the sequence number of the latest message of group, 0 if it has none.
+	LastSeq(group string) (int64, error)
+
+	// Close releases the resources held by the store.
+	Close() error
+}
+
+// page returns up to limit messages of l with a sequence number lower than before.
+// l must be sorted by sequence number.
+func page(l []chat.Message, before int64, limit int) []chat.Message {
+
+	if limit <= 0 || limit > MaxPage {
+		limit = MaxPage
+	}
+
+	end := len(l)
+	if before > 0 {
+		end = sort.Search(len(l), func(i int) bool { return l[i].Seq >= before })
+	}
+
+	start := end - limit
+	if start < 0 {
+		start = 0
+	}
+
+	p := make([]chat.Message, end-start)
+	copy(p, l[start:end])
+	return p
+}
+
+// since returns up to limit messages of l with a sequence number greater than after.
+// l must be sorted by sequence number.
+func since(l []chat.Message, after int64, limit int) []chat.Message {
+
+	if limit <= 0 || limit > MaxPage {
+		limit = MaxPage
+	}
+
+	start := sort.Search(len(l), func(i int) bool { return l[i].Seq > after })
+	end := start + limit
+	if end > len(l) {
+		end = len(l)
+	}
+
+	p := make([]chat.Message, end-start)
+	copy(p, l[start:end])
+	return p
+}
+
+// thread returns up to limit of the latest messages of l that are root or reply in its thread.
+func thread(l []chat.Message, root string, limit int) []chat.Message {
+
+	if limit <= 0 || limit > MaxPage {
+		limit = MaxPage
+	}
+
+	var p []chat.Message
+	for i := len(l) - 1; i >= 0 && len(p) < limit; i-- {
+		if l[i].Id == root || l[i].Thread == root {
+			p = append(p, l[i])
+		}
+	}
+	for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+		p[i], p[j] = p[j], p[i]
+	}
+	return p
+}
+
+// replace puts msg in place of the message of l with the same sequence number.
+// l must be sorted by sequence number.
+// It returns ErrNotFound if there is none.
+func replace(l []chat.Message, msg chat.Message) error {
+
+	i := sort.Search(len(l), func(i int) bool { return l[i].Seq >= msg.Seq })
+	if i == len(l) || l[i].Seq != msg.Seq {
+		return ErrNotFound
+	}
+	l[i] = msg
+	return nil
+}
@@ -0,0 +1,195 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/baadjis/grpchat/chat"
+)
+
+// fill appends n messages to group, numbered from 1, every third one a reply to the first.
+func fill(t *testing.T, s MessageStore, group string, n int) {
+	for i := 1; i <= n; i++ {
+		msg := chat.Message{Id: group + string(rune('a'+i-1)), Seq: int64(i), Timestamp: int64(i), Sender: "alice", Body: "message number " + string(rune('a'+i-1))}
+		if i%3 == 0 {
+			msg.Thread = group + "a"
+			msg.Sender = "bob"
+		}
+		if err := s.Append(group, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// seqs returns the sequence numbers of l.
+func seqs(l []chat.Message) []int64 {
+	s := []int64{}
+	for _, m := range l {
+		s = append(s, m.Seq)
+	}
+	return s
+}
+
+func equal(a []int64, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// stores returns an empty store of each kind.
+func stores(t *testing.T) map[string]MessageStore {
+	f, err := OpenFileStore(filepath.Join(t.TempDir(), "history.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return map[string]MessageStore{"memory": NewMemoryStore(), "file": f}
+}
+
+func TestPages(t *testing.T) {
+
+	for kind, s := range stores(t) {
+		fill(t, s, "g", 10)
+
+		tests := []struct {
+			name string
+			get  func() ([]chat.Message, error)
+			want []int64
+		}{
+			{"latest", func() ([]chat.Message, error) { return s.History("g", 0, 3) }, []int64{8, 9, 10}},
+			{"before", func() ([]chat.Message, error) { return s.History("g", 4, 2) }, []int64{2, 3}},
+			{"before the first", func() ([]chat.Message, error) { return s.History("g", 1, 5) }, []int64{}},
+			{"more than there is", func() ([]chat.Message, error) { return s.History("g", 3, 50) }, []int64{1, 2}},
+			{"no limit", func() ([]chat.Message, error) { return s.History("g", 0, 0) }, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+			{"other group", func() ([]chat.Message, error) { return s.History("h", 0, 3) }, []int64{}},
+			{"since", func() ([]chat.Message, error) { return s.Since("g", 7, 10) }, []int64{8, 9, 10}},
+			{"since limited", func() ([]chat.Message, error) { return s.Since("g", 0, 2) }, []int64{1, 2}},
+			{"since the last", func() ([]chat.Message, error) { return s.Since("g", 10, 2) }, []int64{}},
+			{"thread", func() ([]chat.Message, error) { return s.Thread("g", "ga", 10) }, []int64{1, 3, 6, 9}},
+			{"thread latest", func() ([]chat.Message, error) { return s.Thread("g", "ga", 2) }, []int64{6, 9}},
+			{"no thread", func() ([]chat.Message, error) { return s.Thread("g", "gb", 10) }, []int64{2}},
+		}
+		for _, tt := range tests {
+			l, err := tt.get()
+			if err != nil || !equal(seqs(l), tt.want) {
+				t.Errorf("%s %s: got %v %v, want %v", kind, tt.name, seqs(l), err, tt.want)
+			}
+		}
+		if seq, err := s.LastSeq("g"); seq != 10 || err != nil {
+			t.Errorf("%s: LastSeq = %d %v", kind, seq, err)
+		}
+		if seq, err := s.LastSeq("h"); seq != 0 || err != nil {
+			t.Errorf("%s: LastSeq of an empty group = %d %v", kind, seq, err)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+
+	for kind, s := range stores(t) {
+		fill(t, s, "g", 3)
+
+		msg, err := s.Find("g", "gb")
+		if err != nil || msg.Seq != 2 {
+			t.Fatalf("%s: Find = %v %v", kind, msg, err)
+		}
+		if _, err := s.Find("g", "gz"); err != ErrNotFound {
+			t.Errorf("%s: Find of a missing message = %v", kind, err)
+		}
+		if _, err := s.Find("h", "gb"); err != ErrNotFound {
+			t.Errorf("%s: Find in another group = %v", kind, err)
+		}
+
+		msg.Body = "changed text"
+		if err := s.Update("g", msg); err != nil {
+			t.Errorf("%s: Update = %v", kind, err)
+		}
+		if m, _ := s.Find("g", "gb"); m.Body != "changed text" {
+			t.Errorf("%s: the update was not kept: %q", kind, m.Body)
+		}
+		if err := s.Update("g", chat.Message{Id: "gz", Seq: 42}); err != ErrNotFound {
+			t.Errorf("%s: Update of a missing message = %v", kind, err)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+
+	for kind, s := range stores(t) {
+		fill(t, s, "g", 6)
+		fill(t, s, "h", 2)
+		s.Append("g", chat.Message{Id: "gj", Seq: 7, Timestamp: 7, Sender: "server", Event: &chat.Message_Join{Join: &chat.Join{}}, Body: "message"})
+		msg, _ := s.Find("g", "gb")
+		msg.Deleted, msg.Body = true, ""
+		s.Update("g", msg)
+		msg, _ = s.Find("g", "gc")
+		msg.Body = "Edited Words"
+		s.Update("g", msg)
+
+		tests := []struct {
+			name string
+			q    Query
+			want []int64
+		}{
+			{"word", Query{Text: "message", Groups: []string{"g"}}, []int64{6, 5, 4, 1}},
+			{"every word", Query{Text: "number d", Groups: []string{"g"}}, []int64{4}},
+			{"case and punctuation", Query{Text: "NUMBER, e!", Groups: []string{"g"}}, []int64{5}},
+			{"groups", Query{Text: "message", Groups: []string{"g", "h"}, Limit: 3}, []int64{6, 5, 4}},
+			{"other group only", Query{Text: "message", Groups: []string{"h"}}, []int64{2, 1}},
+			{"no group", Query{Text: "message"}, []int64{}},
+			{"sender", Query{Text: "message", Groups: []string{"g"}, Sender: "bob"}, []int64{6}},
+			{"since", Query{Text: "message", Groups: []string{"g"}, Since: 5}, []int64{6, 5}},
+			{"until", Query{Text: "message", Groups: []string{"g"}, Until: 4}, []int64{4, 1}},
+			{"edited", Query{Text: "edited", Groups: []string{"g"}}, []int64{3}},
+			{"old words of an edit", Query{Text: "number c", Groups: []string{"g"}}, []int64{}},
+			{"deleted", Query{Text: "number b", Groups: []string{"g"}}, []int64{}},
+			{"nothing", Query{Text: "nowhere", Groups: []string{"g"}}, []int64{}},
+		}
+		for _, tt := range tests {
+			l, err := s.Search(tt.q)
+			if err != nil || !equal(seqs(l), tt.want) {
+				t.Errorf("%s %s: got %v %v, want %v", kind, tt.name, seqs(l), err, tt.want)
+			}
+		}
+	}
+}
+
+func TestReopen(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "history.log")
+	s, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fill(t, s, "g", 4)
+	s.Append("g", chat.Message{Id: "ge", Seq: 5, Sender: "server", Event: &chat.Message_Leave{Leave: &chat.Leave{}}})
+	msg, _ := s.Find("g", "gb")
+	msg.Body = "changed text"
+	s.Update("g", msg)
+	s.Close()
+	if err := s.Append("g", chat.Message{Seq: 6}); err == nil {
+		t.Error("Append after Close succeeded")
+	}
+
+	s, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	l, _ := s.History("g", 0, 0)
+	if !equal(seqs(l), []int64{1, 2, 3, 4, 5}) {
+		t.Fatalf("reloaded %v", seqs(l))
+	}
+	if l[1].Body != "changed text" || l[4].GetLeave() == nil {
+		t.Errorf("reloaded %q and %v", l[1].Body, l[4].Event)
+	}
+	if r, _ := s.Search(Query{Text: "changed", Groups: []string{"g"}}); len(r) != 1 {
+		t.Errorf("the reloaded history is not indexed: %v", seqs(r))
+	}
+}
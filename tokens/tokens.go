@@ -0,0 +1,239 @@
+// Package tokens hands out the session tokens of the server and keeps track of their expiry
+// and revocation.
+package tokens
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// RefreshGrace is how long a token stays valid once it was refreshed, for the calls already
+// on their way with it.
+const RefreshGrace = 30 * time.Second
+
+// The errors returned by Resolve.
+var (
+	ErrInvalid = errors.New("invalid session token")
+	ErrExpired = errors.New("session expired")
+	ErrRevoked = errors.New("session revoked")
+)
+
+// session is what a token stands for, the login of a device. The streams opened with it are
+// cancelled when it is revoked, expiry only stops new calls.
+type session struct {
+	name    string
+	device  string // kept when the token is refreshed
+	expires time.Time
+	streams map[int]context.CancelFunc
+}
+
+// Registry holds the sessions by the hash of their token, so the tokens themselves are not
+// kept by the server.
+type Registry struct {
+	lock     sync.Mutex
+	ttl      time.Duration
+	sessions map[string]*session
+	revoked  map[string]time.Time // revoked tokens until they would have expired
+	next     int
+}
+
+// New returns a registry handing out tokens valid for ttl.
+func New(ttl time.Duration) *Registry {
+	return &Registry{
+		ttl:      ttl,
+		sessions: make(map[string]*session),
+		revoked:  make(map[string]time.Time),
+	}
+}
+
+// hash returns the key a token is kept under.
+func hash(tkn string) string {
+	h := sha256.Sum256([]byte(tkn))
+	return hex.EncodeToString(h[:])
+}
+
+// prune forgets the sessions expired with no open stream and the revoked tokens that expired.
+// The registry lock must be held.
+func (r *Registry) prune(now time.Time) {
+	for k, s := range r.sessions {
+		if now.After(s.expires) && len(s.streams) == 0 {
+			delete(r.sessions, k)
+		}
+	}
+	for k, exp := range r.revoked {
+		if now.After(exp) {
+			delete(r.revoked, k)
+		}
+	}
+}
+
+// Issue starts a session for the device of name.
+// It returns the token, when it expires and an error if no random token could be made.
+func (r *Registry) Issue(name string, device string) (string, time.Time, error) {
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", time.Time{}, err
+	}
+	tkn := base64.RawURLEncoding.EncodeToString(b)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	r.prune(now)
+	s := &session{name: name, device: device, expires: now.Add(r.ttl), streams: make(map[int]context.CancelFunc)}
+	r.sessions[hash(tkn)] = s
+	return tkn, s.expires, nil
+}
+
+// Resolve returns the name and the device of the session of tkn, and an error if it can't be used.
+func (r *Registry) Resolve(tkn string) (string, string, error) {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	k := hash(tkn)
+	if _, ok := r.revoked[k]; ok {
+		return "", "", ErrRevoked
+	}
+	s, ok := r.sessions[k]
+	switch {
+	case !ok:
+		return "", "", ErrInvalid
+	case time.Now().After(s.expires):
+		return "", "", ErrExpired
+	}
+	return s.name, s.device, nil
+}
+
+// Devices returns the devices of name with a session that did not expire.
+func (r *Registry) Devices(name string) map[string]bool {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	d := make(map[string]bool)
+	for _, s := range r.sessions {
+		if s.name == name && !now.After(s.expires) {
+			d[s.device] = true
+		}
+	}
+	return d
+}
+
+// endSoon makes the session expire after RefreshGrace at the latest.
+func (s *session) endSoon() {
+	if grace := time.Now().Add(RefreshGrace); grace.Before(s.expires) {
+		s.expires = grace
+	}
+}
+
+// Refresh starts a new session for the device of tkn, tkn expires after RefreshGrace.
+// It returns the new token, when it expires and an error.
+func (r *Registry) Refresh(tkn string) (string, time.Time, error) {
+
+	name, device, err := r.Resolve(tkn)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	r.lock.Lock()
+	if s, ok := r.sessions[hash(tkn)]; ok {
+		s.endSoon()
+	}
+	r.lock.Unlock()
+
+	return r.Issue(name, device)
+}
+
+// Resume hands over the device of the session of tkn, even expired, to a new login of name
+// that replaces it. tkn expires after RefreshGrace.
+// It returns the device and false if name has no such session, or it was revoked.
+func (r *Registry) Resume(tkn string, name string) (string, bool) {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	s, ok := r.sessions[hash(tkn)]
+	if !ok || s.name != name {
+		return "", false
+	}
+	s.endSoon()
+	return s.device, true
+}
+
+// Bind ties a stream opened with tkn to its session, cancel is called if it is revoked.
+// It returns a func to call once the stream is done, and an error if the session is gone.
+func (r *Registry) Bind(tkn string, cancel context.CancelFunc) (func(), error) {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	s, ok := r.sessions[hash(tkn)]
+	if !ok {
+		return nil, ErrRevoked
+	}
+	id := r.next
+	r.next++
+	s.streams[id] = cancel
+
+	return func() {
+		r.lock.Lock()
+		delete(s.streams, id)
+		r.lock.Unlock()
+	}, nil
+}
+
+// revoke ends a session and its streams. The registry lock must be held.
+func (r *Registry) revoke(k string, s *session) {
+	delete(r.sessions, k)
+	r.revoked[k] = s.expires
+	for _, cancel := range s.streams {
+		cancel()
+	}
+}
+
+// Revoke ends the session of tkn if name owns it, and every other session of its device.
+// It returns the device and false if there was no such session.
+func (r *Registry) Revoke(tkn string, name string) (string, bool) {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	s, ok := r.sessions[hash(tkn)]
+	if !ok || s.name != name {
+		return "", false
+	}
+	// the tokens the device refreshed lately go with it
+	for k, other := range r.sessions {
+		if other.name == name && other.device == s.device {
+			r.revoke(k, other)
+		}
+	}
+	return s.device, true
+}
+
+// RevokeAll ends every session of name.
+// It returns the number of sessions ended.
+func (r *Registry) RevokeAll(name string) int {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	n := 0
+	for k, s := range r.sessions {
+		if s.name == name {
+			r.revoke(k, s)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,237 @@
+package tokens
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+
+	r := New(time.Hour)
+	alice, _, err := r.Issue("alice", "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, _, _ := r.Issue("bob", "laptop")
+	dead := New(-time.Second)
+	expired, _, _ := dead.Issue("alice", "phone")
+
+	tests := []struct {
+		name   string
+		r      *Registry
+		tkn    string
+		user   string
+		device string
+		err    error
+	}{
+		{"alice", r, alice, "alice", "phone", nil},
+		{"bob", r, bob, "bob", "laptop", nil},
+		{"unknown", r, "not a token", "", "", ErrInvalid},
+		{"empty", r, "", "", "", ErrInvalid},
+		{"other registry", dead, alice, "", "", ErrInvalid},
+		{"expired", dead, expired, "", "", ErrExpired},
+	}
+	for _, tt := range tests {
+		user, device, err := tt.r.Resolve(tt.tkn)
+		if user != tt.user || device != tt.device || err != tt.err {
+			t.Errorf("%s: got %q %q %v, want %q %q %v", tt.name, user, device, err, tt.user, tt.device, tt.err)
+		}
+	}
+	if alice == bob {
+		t.Error("two sessions got the same token")
+	}
+}
+
+func TestRefresh(t *testing.T) {
+
+	r := New(time.Hour)
+	old, _, _ := r.Issue("alice", "phone")
+	tkn, expires, err := r.Refresh(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tkn == old || time.Until(expires) < 59*time.Minute {
+		t.Errorf("Refresh gave %q expiring at %v", tkn, expires)
+	}
+	if _, device, err := r.Resolve(tkn); device != "phone" || err != nil {
+		t.Errorf("the new token is for %q, %v, want the device of the old one", device, err)
+	}
+	// the old token is still good for the calls on their way
+	if _, _, err := r.Resolve(old); err != nil {
+		t.Errorf("the old token in its grace: %v", err)
+	}
+	r.lock.Lock()
+	left := time.Until(r.sessions[hash(old)].expires)
+	r.lock.Unlock()
+	if left > RefreshGrace {
+		t.Errorf("the old token expires in %v, want at most %v", left, RefreshGrace)
+	}
+
+	if _, _, err := New(-time.Second).Refresh(old); err != ErrInvalid {
+		t.Errorf("Refresh of an unknown token: %v", err)
+	}
+	dead := New(-time.Second)
+	expired, _, _ := dead.Issue("alice", "phone")
+	if _, _, err := dead.Refresh(expired); err != ErrExpired {
+		t.Errorf("Refresh of an expired token: %v", err)
+	}
+}
+
+func TestResume(t *testing.T) {
+
+	r := New(time.Hour)
+	phone, _, _ := r.Issue("alice", "phone")
+	laptop, _, _ := r.Issue("alice", "laptop")
+	r.Revoke(laptop, "alice")
+	dead := New(-time.Second)
+	expired, _, _ := dead.Issue("alice", "tablet")
+
+	tests := []struct {
+		name   string
+		r      *Registry
+		tkn    string
+		user   string
+		device string
+		ok     bool
+	}{
+		{"valid", r, phone, "alice", "phone", true},
+		{"expired", dead, expired, "alice", "tablet", true},
+		{"another user", r, phone, "bob", "", false},
+		{"revoked", r, laptop, "alice", "", false},
+		{"unknown", r, "not a token", "alice", "", false},
+		{"empty", r, "", "alice", "", false},
+	}
+	for _, tt := range tests {
+		device, ok := tt.r.Resume(tt.tkn, tt.user)
+		if device != tt.device || ok != tt.ok {
+			t.Errorf("%s: got %q %v, want %q %v", tt.name, device, ok, tt.device, tt.ok)
+		}
+	}
+
+	// the token it replaces only stays for the calls on their way
+	r.lock.Lock()
+	left := time.Until(r.sessions[hash(phone)].expires)
+	r.lock.Unlock()
+	if left > RefreshGrace {
+		t.Errorf("the resumed token expires in %v, want at most %v", left, RefreshGrace)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+
+	r := New(time.Hour)
+	phone, _, _ := r.Issue("alice", "phone")
+	refreshed, _, _ := r.Refresh(phone)
+	laptop, _, _ := r.Issue("alice", "laptop")
+	bob, _, _ := r.Issue("bob", "phone")
+
+	cancelled := false
+	done, err := r.Bind(refreshed, func() { cancelled = true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	if _, ok := r.Revoke(phone, "bob"); ok {
+		t.Error("bob revoked a session of alice")
+	}
+	if device, ok := r.Revoke(phone, "alice"); device != "phone" || !ok {
+		t.Errorf("Revoke = %q %v", device, ok)
+	}
+	if !cancelled {
+		t.Error("the stream of the device was not cancelled")
+	}
+
+	tests := []struct {
+		name string
+		tkn  string
+		err  error
+	}{
+		{"revoked", phone, ErrRevoked},
+		{"refreshed on the same device", refreshed, ErrRevoked},
+		{"other device", laptop, nil},
+		{"other user", bob, nil},
+	}
+	for _, tt := range tests {
+		if _, _, err := r.Resolve(tt.tkn); err != tt.err {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+		}
+	}
+	if _, err := r.Bind(phone, func() {}); err != ErrRevoked {
+		t.Errorf("Bind on a revoked session: %v", err)
+	}
+	if _, _, err := r.Refresh(phone); err != ErrRevoked {
+		t.Errorf("Refresh of a revoked token: %v", err)
+	}
+
+	if n := r.RevokeAll("alice"); n != 1 {
+		t.Errorf("RevokeAll ended %d sessions, want 1", n)
+	}
+	if _, _, err := r.Resolve(laptop); err != ErrRevoked {
+		t.Errorf("laptop after RevokeAll: %v", err)
+	}
+	if _, _, err := r.Resolve(bob); err != nil {
+		t.Errorf("bob after RevokeAll of alice: %v", err)
+	}
+}
+
+func TestDevices(t *testing.T) {
+
+	r := New(time.Hour)
+	r.Issue("alice", "phone")
+	r.Issue("alice", "laptop")
+	r.Issue("bob", "tablet")
+	dead := New(-time.Second)
+	dead.Issue("alice", "phone")
+
+	tests := []struct {
+		r    *Registry
+		name string
+		want []string
+	}{
+		{r, "alice", []string{"phone", "laptop"}},
+		{r, "bob", []string{"tablet"}},
+		{r, "carol", nil},
+		{dead, "alice", nil},
+	}
+	for _, tt := range tests {
+		got := tt.r.Devices(tt.name)
+		if len(got) != len(tt.want) {
+			t.Errorf("Devices(%s) = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for _, d := range tt.want {
+			if !got[d] {
+				t.Errorf("Devices(%s) = %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+
+	r := New(-time.Second)
+	streaming, _, _ := r.Issue("alice", "phone")
+	_, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done, err := r.Bind(streaming, cancel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idle, _, _ := r.Issue("alice", "laptop")
+	r.Issue("alice", "tablet")
+
+	// an expired session goes once its streams are done
+	if _, _, err := r.Resolve(idle); err != ErrInvalid {
+		t.Errorf("expired idle session: %v", err)
+	}
+	if _, _, err := r.Resolve(streaming); err != ErrExpired {
+		t.Errorf("expired session with a stream: %v", err)
+	}
+	done()
+	r.Issue("alice", "tablet")
+	if _, _, err := r.Resolve(streaming); err != ErrInvalid {
+		t.Errorf("expired session after its stream: %v", err)
+	}
+}